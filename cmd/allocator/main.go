@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"github.com/hetulpatel/Arbitrage/internal/allocator"
+	"github.com/hetulpatel/Arbitrage/internal/arb"
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/kafka"
+	"github.com/hetulpatel/Arbitrage/internal/logging"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	logging.InitFromEnv()
+
+	brokers := kafka.Brokers()
+	inTopic := kafka.TopicFromEnv("OPPORTUNITIES_KAFKA_TOPIC", kafka.DefaultOpportunityTopic)
+	outTopic := kafka.TopicFromEnv("ALLOCATIONS_KAFKA_TOPIC", kafka.DefaultAllocationTopic)
+	group := envString("ALLOCATOR_GROUP", "allocator")
+	window := time.Duration(envInt("ALLOCATOR_WINDOW_SECONDS", 60)) * time.Second
+	interval := time.Duration(envInt("ALLOCATOR_INTERVAL_SECONDS", 15)) * time.Second
+	cfg := allocator.Config{
+		Balances: map[collectors.Venue]float64{
+			collectors.VenueKalshi:     envFloat("ALLOCATOR_KALSHI_BALANCE_USD", 1000),
+			collectors.VenuePolymarket: envFloat("ALLOCATOR_POLYMARKET_BALANCE_USD", 1000),
+		},
+		MaxEventExposureUSD: envFloat("ALLOCATOR_MAX_EVENT_EXPOSURE_USD", 250),
+		MinEdge:             envFloat("ALLOCATOR_MIN_EDGE", 0.01),
+		Fees:                mustFeeSchedule(),
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 45*time.Second)
+	if err := kafka.WaitForBroker(waitCtx, brokers); err != nil {
+		logging.Fatalf("[allocator] wait for broker: %v", err)
+	}
+	cancel()
+
+	ensureCtx, cancelEnsure := context.WithTimeout(ctx, 30*time.Second)
+	for _, topic := range []string{inTopic, outTopic} {
+		if err := kafka.EnsureTopic(ensureCtx, brokers, topic); err != nil {
+			logging.Errorf("[allocator] ensure topic %s warning: %v", topic, err)
+		}
+	}
+	cancelEnsure()
+
+	writer := kafka.NewWriter(brokers, outTopic)
+	defer writer.Close()
+
+	live := allocator.NewWindow(window)
+	go consume(ctx, brokers, inTopic, group, live)
+
+	logging.Infof("[allocator] consuming %s with group %s (window=%s, every %s) publishing to %s", inTopic, group, window, interval, outTopic)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now().UTC()
+			plan := allocator.Allocate(live.Live(now), cfg, now)
+			if len(plan.Allocations) == 0 {
+				logging.Debugf("[allocator] no allocation from %d candidates", plan.Candidates)
+				continue
+			}
+			logPlan(plan)
+			publishPlan(ctx, writer, plan)
+		}
+	}
+}
+
+func consume(ctx context.Context, brokers []string, topic, group string, live *allocator.Window) {
+	reader := kafka.NewReader(brokers, topic, group)
+	defer reader.Close()
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logging.Errorf("[allocator] read error: %v", err)
+			continue
+		}
+		var payload matches.Payload
+		if err := json.Unmarshal(msg.Value, &payload); err != nil {
+			logging.Errorf("[allocator] unmarshal error: %v", err)
+			continue
+		}
+		cand, ok := allocator.CandidateFromPayload(&payload, msg.Time.UTC())
+		if !ok {
+			continue
+		}
+		live.Add(cand)
+	}
+}
+
+func logPlan(plan allocator.Plan) {
+	fmt.Printf("[allocation] candidates=%d allocations=%d cost=%.2f profit=%.4f kalshi=%.2f polymarket=%.2f\n",
+		plan.Candidates, len(plan.Allocations), plan.TotalCostUSD, plan.ProfitUSD,
+		plan.VenueUsageUSD[collectors.VenueKalshi], plan.VenueUsageUSD[collectors.VenuePolymarket])
+	for _, a := range plan.Allocations {
+		fmt.Printf("  pair=%s dir=%s qty=%.2f cost=%.2f profit=%.4f edge=%.4f\n",
+			a.PairID, a.Direction, a.Quantity, a.CostUSD, a.ProfitUSD, a.Edge)
+	}
+}
+
+func publishPlan(ctx context.Context, writer *kafkago.Writer, plan allocator.Plan) {
+	data, err := json.Marshal(plan)
+	if err != nil {
+		logging.Errorf("[allocator] marshal plan error: %v", err)
+		return
+	}
+	msg := kafkago.Message{
+		Key:   []byte(plan.CreatedAt.Format(time.RFC3339Nano)),
+		Value: data,
+		Time:  plan.CreatedAt,
+	}
+	if err := writer.WriteMessages(ctx, msg); err != nil {
+		logging.Errorf("[allocator] publish plan error: %v", err)
+	}
+}
+
+func mustFeeSchedule() *arb.FeeSchedule {
+	sched, err := arb.LoadFeeSchedule(os.Getenv("ARB_FEE_SCHEDULE_PATH"))
+	if err != nil {
+		logging.Fatalf("[allocator] fee schedule: %v", err)
+	}
+	return &sched
+}
+
+func envInt(key string, def int) int {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.Atoi(val); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
+func envFloat(key string, def float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.ParseFloat(val, 64); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
+func envString(key, def string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return def
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -46,11 +47,22 @@ func main() {
 	}
 	defer store.Close()
 
+	fees := mustFeeSchedule()
+
 	logging.Infof("[arb-engine] consuming %s with group %s (%d workers, budget=%.2f)", topic, group, workerCount, budget)
-	runWorkers(ctx, brokers, topic, group, workerCount, budget, store)
+	runWorkers(ctx, brokers, topic, group, workerCount, arb.Config{
+		BudgetUSD:       budget,
+		Budgets:         envFloats("ARB_ENGINE_BUDGETS_USD"),
+		Fees:            fees,
+		RankBy:          matches.ParseRankMetric(envString("ARB_RANK_BY", string(matches.RankByScore))),
+		Scoring:         mustScoreWeights(),
+		Tradability:     mustTradabilityPolicy(),
+		MaxSnapshotAge:  time.Duration(envInt("ARB_MAX_SNAPSHOT_AGE_SECONDS", 60)) * time.Second,
+		MaxSnapshotSkew: time.Duration(envInt("ARB_MAX_SNAPSHOT_SKEW_SECONDS", 10)) * time.Second,
+	}, store)
 }
 
-func runWorkers(ctx context.Context, brokers []string, topic, group string, workerCount int, budget float64, store *sqlstore.Store) {
+func runWorkers(ctx context.Context, brokers []string, topic, group string, workerCount int, cfg arb.Config, store *sqlstore.Store) {
 	if workerCount <= 0 {
 		workerCount = 1
 	}
@@ -59,18 +71,17 @@ func runWorkers(ctx context.Context, brokers []string, topic, group string, work
 		wg.Add(1)
 		go func(id int) {
 			defer wg.Done()
-			consume(ctx, brokers, topic, group, budget, store)
+			consume(ctx, brokers, topic, group, cfg, store)
 		}(i)
 	}
 	<-ctx.Done()
 	wg.Wait()
 }
 
-func consume(ctx context.Context, brokers []string, topic, group string, budget float64, store *sqlstore.Store) {
+func consume(ctx context.Context, brokers []string, topic, group string, cfg arb.Config, store *sqlstore.Store) {
 	reader := kafka.NewReader(brokers, topic, group)
 	defer reader.Close()
 
-	cfg := arb.Config{BudgetUSD: budget}
 	for {
 		msg, err := reader.ReadMessage(ctx)
 		if err != nil {
@@ -102,6 +113,11 @@ func logOpportunity(payload *matches.Payload, result arb.Result) {
 		pairID = "unknown"
 	}
 
+	if result.Stale {
+		logging.Infof("[arb-engine] pair=%s STALE reason=%s", pairID, result.Reason)
+		return
+	}
+
 	if result.Untradable {
 		logging.Errorf("[arb-engine] pair=%s UNTRADABLE reason=%s", pairID, result.Reason)
 		return
@@ -111,8 +127,32 @@ func logOpportunity(payload *matches.Payload, result arb.Result) {
 		logging.Infof("[arb-engine] pair=%s no opportunity found", pairID)
 		return
 	}
-	fmt.Printf("[arb-opportunity] pair=%s dir=%s qty=%.2f cost=%.4f profit=%.4f fees=%.4f\n",
-		pairID, result.Best.Direction, result.Best.Quantity, result.Best.TotalCostUSD, result.Best.ProfitUSD, result.Best.KalshiFeesUSD+result.Best.PolymarketFeesUSD)
+	fmt.Printf("[arb-opportunity] pair=%s dir=%s qty=%.2f cost=%.4f profit=%.4f fees=%.4f apy=%.4f score=%.3f\n",
+		pairID, result.Best.Direction, result.Best.Quantity, result.Best.TotalCostUSD, result.Best.ProfitUSD, result.Best.KalshiFeesUSD+result.Best.PolymarketFeesUSD, result.Best.AnnualizedYield, result.Best.Score)
+}
+
+func mustFeeSchedule() *arb.FeeSchedule {
+	sched, err := arb.LoadFeeSchedule(os.Getenv("ARB_FEE_SCHEDULE_PATH"))
+	if err != nil {
+		logging.Fatalf("[arb-engine] fee schedule: %v", err)
+	}
+	return &sched
+}
+
+func mustScoreWeights() *matches.ScoreWeights {
+	weights, err := matches.ParseScoreWeights(os.Getenv("ARB_SCORE_WEIGHTS"))
+	if err != nil {
+		logging.Fatalf("[arb-engine] score weights: %v", err)
+	}
+	return &weights
+}
+
+func mustTradabilityPolicy() *arb.TradabilityPolicy {
+	policy, err := arb.LoadTradabilityPolicy(os.Getenv("ARB_TRADABILITY_PATH"))
+	if err != nil {
+		logging.Fatalf("[arb-engine] tradability policy: %v", err)
+	}
+	return &policy
 }
 
 func envInt(key string, def int) int {
@@ -139,3 +179,14 @@ func envString(key, def string) string {
 	}
 	return def
 }
+
+// envFloats parses a comma-separated list of floats, skipping bad entries.
+func envFloats(key string) []float64 {
+	var out []float64
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if parsed, err := strconv.ParseFloat(strings.TrimSpace(part), 64); err == nil {
+			out = append(out, parsed)
+		}
+	}
+	return out
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/arb"
+	"github.com/hetulpatel/Arbitrage/internal/cache"
+	"github.com/hetulpatel/Arbitrage/internal/kafka"
+	"github.com/hetulpatel/Arbitrage/internal/logging"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+	sqlstore "github.com/hetulpatel/Arbitrage/internal/storage/sqlite"
+	"github.com/hetulpatel/Arbitrage/internal/workers"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	logging.InitFromEnv()
+
+	brokers := kafka.Brokers()
+	topics := []string{
+		kafka.TopicFromEnv("POLYMARKET_KAFKA_TOPIC", kafka.DefaultPolyTopic),
+		kafka.TopicFromEnv("KALSHI_KAFKA_TOPIC", kafka.DefaultKalshiTopic),
+	}
+	group := envString("BOX_SCANNER_GROUP", "box-scanner")
+	workerCount := envInt("BOX_SCANNER_WORKERS", 1)
+	budget := envFloat("BOX_SCANNER_BUDGET_USD", 100)
+	rescan := time.Duration(envInt("BOX_SCANNER_RESCAN_SECONDS", 300)) * time.Second
+
+	waitCtx, cancel := context.WithTimeout(ctx, 45*time.Second)
+	if err := kafka.WaitForBroker(waitCtx, brokers); err != nil {
+		logging.Fatalf("[box-scanner] wait for broker: %v", err)
+	}
+	cancel()
+
+	store, err := sqlstore.Open(os.Getenv("SQLITE_PATH"))
+	if err != nil {
+		logging.Fatalf("[box-scanner] open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	opportunities := mustOpportunityCache()
+	if opportunities != nil {
+		defer opportunities.Close()
+	}
+
+	cfg := arb.Config{BudgetUSD: budget, Budgets: envFloats("BOX_SCANNER_BUDGETS_USD"), Fees: mustFeeSchedule()}
+	handler := func(ctx context.Context, snap *models.MarketSnapshot) error {
+		result := arb.EvaluateBox(snap, cfg)
+		if result.Best == nil {
+			logging.Debugf("[box-scanner] market=%s venue=%s skipped (%s)", snap.Market.MarketID, snap.Venue, result.Reason)
+			return nil
+		}
+		key := string(snap.Venue) + ":" + snap.Market.MarketID
+		if !shouldEmit(ctx, opportunities, key, result.Best, rescan) {
+			logging.Debugf("[box-scanner] market=%s venue=%s suppressed repeat profit=%.4f", snap.Market.MarketID, snap.Venue, result.Best.ProfitUSD)
+			return nil
+		}
+		payload := matches.NewBoxPayload(*snap)
+		payload.Arbitrage = result.Best
+		fmt.Printf("[box-opportunity] venue=%s market=%s dir=%s qty=%.2f cost=%.4f profit=%.4f\n",
+			snap.Venue, snap.Market.MarketID, result.Best.Direction, result.Best.Quantity, result.Best.TotalCostUSD, result.Best.ProfitUSD)
+		if err := store.InsertArbOpportunity(ctx, &payload, result); err != nil {
+			return fmt.Errorf("sqlite insert: %w", err)
+		}
+		return nil
+	}
+
+	logging.Infof("[box-scanner] consuming %v with group %s (%d workers, budget=%.2f, rescan=%s)", topics, group, workerCount, budget, rescan)
+	var wg sync.WaitGroup
+	for _, topic := range topics {
+		wg.Add(1)
+		go func(topic string) {
+			defer wg.Done()
+			workers.Run(ctx, brokers, topic, group, workerCount, handler)
+		}(topic)
+	}
+	wg.Wait()
+}
+
+// shouldEmit records a box unless the same profit and quantity were already
+// recorded for the market within the rescan window. A standing box is seen on
+// every snapshot, so without this each one would insert another row.
+func shouldEmit(ctx context.Context, opportunities cache.OpportunityCache, key string, best *matches.Opportunity, rescan time.Duration) bool {
+	if opportunities == nil {
+		return true
+	}
+	now := time.Now().UTC()
+	record, ok, err := opportunities.Get(ctx, key)
+	if err != nil {
+		logging.Errorf("[box-scanner] opportunity cache key=%s: %v", key, err)
+		return true
+	}
+	if ok && record != nil && record.ProfitUSD == best.ProfitUSD && record.Quantity == best.Quantity && now.Sub(record.UpdatedAt) < rescan {
+		return false
+	}
+	if err := opportunities.Set(ctx, key, cache.OpportunityRecord{
+		ProfitUSD:       best.ProfitUSD,
+		AnnualizedYield: best.AnnualizedYield,
+		Score:           best.Score,
+		Direction:       string(best.Direction),
+		Quantity:        best.Quantity,
+		UpdatedAt:       now,
+	}); err != nil {
+		logging.Errorf("[box-scanner] opportunity cache key=%s: %v", key, err)
+	}
+	return true
+}
+
+func mustOpportunityCache() cache.OpportunityCache {
+	addr := envString("REDIS_ADDR", "redis:6379")
+	if addr == "" {
+		return nil
+	}
+	ttlHours := envInt("OPPORTUNITY_CACHE_TTL_HOURS", 72)
+	cacheClient, err := cache.NewRedisOpportunityCache(addr, os.Getenv("REDIS_PASSWORD"), envInt("REDIS_DB", 0), time.Duration(ttlHours)*time.Hour, "box_best")
+	if err != nil {
+		logging.Fatalf("[box-scanner] redis opportunity cache: %v", err)
+	}
+	return cacheClient
+}
+
+func mustFeeSchedule() *arb.FeeSchedule {
+	sched, err := arb.LoadFeeSchedule(os.Getenv("ARB_FEE_SCHEDULE_PATH"))
+	if err != nil {
+		logging.Fatalf("[box-scanner] fee schedule: %v", err)
+	}
+	return &sched
+}
+
+func envInt(key string, def int) int {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.Atoi(val); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
+func envFloat(key string, def float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.ParseFloat(val, 64); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
+func envString(key, def string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return def
+}
+
+// envFloats parses a comma-separated list of floats, skipping bad entries.
+func envFloats(key string) []float64 {
+	var out []float64
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if parsed, err := strconv.ParseFloat(strings.TrimSpace(part), 64); err == nil {
+			out = append(out, parsed)
+		}
+	}
+	return out
+}
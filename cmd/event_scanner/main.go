@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/arb"
+	"github.com/hetulpatel/Arbitrage/internal/cache"
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/kafka"
+	"github.com/hetulpatel/Arbitrage/internal/kalshi"
+	"github.com/hetulpatel/Arbitrage/internal/logging"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+	"github.com/hetulpatel/Arbitrage/internal/polymarket"
+	sqlstore "github.com/hetulpatel/Arbitrage/internal/storage/sqlite"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	logging.InitFromEnv()
+
+	brokers := kafka.Brokers()
+	topic := kafka.TopicFromEnv("MATCHES_KAFKA_TOPIC", kafka.DefaultMatchTopic)
+	group := envString("EVENT_SCANNER_GROUP", "event-scanner")
+
+	waitCtx, cancel := context.WithTimeout(ctx, 45*time.Second)
+	if err := kafka.WaitForBroker(waitCtx, brokers); err != nil {
+		logging.Fatalf("[event-scanner] wait for broker: %v", err)
+	}
+	cancel()
+
+	store, err := sqlstore.Open(os.Getenv("SQLITE_PATH"))
+	if err != nil {
+		logging.Fatalf("[event-scanner] open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	opportunityCache := mustOpportunityCache()
+	if opportunityCache != nil {
+		defer opportunityCache.Close()
+	}
+
+	fees := mustFeeSchedule()
+	s := &scanner{
+		pmClient: polymarket.NewClient(polymarket.Config{
+			BaseURL: envString("POLYMARKET_API_URL", ""),
+			BookURL: envString("POLYMARKET_BOOK_URL", ""),
+			Timeout: time.Duration(envInt("POLYMARKET_HTTP_TIMEOUT_SECONDS", 20)) * time.Second,
+		}),
+		kxClient: kalshi.NewClient(kalshi.Config{
+			BaseURL:   envString("KALSHI_API_URL", ""),
+			SeriesURL: envString("KALSHI_SERIES_URL", ""),
+			BookURL:   envString("KALSHI_MARKET_URL", ""),
+			Timeout:   time.Duration(envInt("KALSHI_HTTP_TIMEOUT_SECONDS", 20)) * time.Second,
+		}),
+		cfg: arb.EventConfig{
+			BudgetUSD: envFloat("EVENT_SCANNER_BUDGET_USD", 100),
+			Budgets:   envFloats("EVENT_SCANNER_BUDGETS_USD"),
+			Fees:      fees,
+		},
+		rangeCfg: arb.RangeConfig{
+			BudgetUSD: envFloat("EVENT_SCANNER_BUDGET_USD", 100),
+			Step:      envFloat("EVENT_SCANNER_RANGE_STEP", 0),
+			Fees:      fees,
+		},
+		rescan:        time.Duration(envInt("EVENT_SCANNER_RESCAN_SECONDS", 60)) * time.Second,
+		scanned:       make(map[string]time.Time),
+		store:         store,
+		opportunities: opportunityCache,
+	}
+
+	reader := kafka.NewReader(brokers, topic, group)
+	defer reader.Close()
+
+	logging.Infof("[event-scanner] consuming %s with group %s (budget=%.2f, range_step=%g, rescan=%s)", topic, group, s.cfg.BudgetUSD, s.rangeCfg.Step, s.rescan)
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logging.Errorf("[event-scanner] read error: %v", err)
+			continue
+		}
+		var payload matches.Payload
+		if err := json.Unmarshal(msg.Value, &payload); err != nil {
+			logging.Errorf("[event-scanner] unmarshal error: %v", err)
+			continue
+		}
+		if err := s.scan(ctx, &payload); err != nil {
+			logging.Errorf("[event-scanner] pair=%s: %v", payload.PairID, err)
+		}
+	}
+}
+
+type scanner struct {
+	pmClient      *polymarket.Client
+	kxClient      *kalshi.Client
+	cfg           arb.EventConfig
+	rangeCfg      arb.RangeConfig
+	rescan        time.Duration
+	scanned       map[string]time.Time
+	pruned        time.Time
+	store         *sqlstore.Store
+	opportunities cache.OpportunityCache
+}
+
+// scan prices the two events behind a matched pair as a basket when both
+// are mutually exclusive, and as range buckets when both matched markets are
+// numeric ranges. Matches arrive once per market, so each event pair is
+// refetched at most once per rescan interval.
+func (s *scanner) scan(ctx context.Context, payload *matches.Payload) error {
+	pmSnap := snapshotForVenue(payload, collectors.VenuePolymarket)
+	kxSnap := snapshotForVenue(payload, collectors.VenueKalshi)
+	if pmSnap == nil || kxSnap == nil {
+		return nil
+	}
+	pmRef, kxRef := &pmSnap.Event, &kxSnap.Event
+	basket := pmRef.MutuallyExclusive && kxRef.MutuallyExclusive
+	ranges := arb.IsRangeMarket(&pmSnap.Market) && arb.IsRangeMarket(&kxSnap.Market)
+	if !basket && !ranges {
+		return nil
+	}
+	key := pmRef.EventID + "|" + kxRef.EventID
+	now := time.Now()
+	s.pruneScanned(now)
+	if last, ok := s.scanned[key]; ok && now.Sub(last) < s.rescan {
+		return nil
+	}
+	s.scanned[key] = now
+
+	var (
+		wg           sync.WaitGroup
+		pmEvent      *collectors.Event
+		kxEvent      *collectors.Event
+		pmErr, kxErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		pmEvent, pmErr = s.pmClient.Event(ctx, pmRef.EventID)
+	}()
+	go func() {
+		defer wg.Done()
+		kxEvent, kxErr = s.kxClient.Event(ctx, kxRef.EventID)
+	}()
+	wg.Wait()
+	if pmErr != nil {
+		return fmt.Errorf("refresh polymarket event: %w", pmErr)
+	}
+	if kxErr != nil {
+		return fmt.Errorf("refresh kalshi event: %w", kxErr)
+	}
+
+	if basket {
+		if err := s.record(ctx, "basket", pmEvent, kxEvent, arb.EvaluateEvents(pmEvent, kxEvent, s.cfg)); err != nil {
+			return err
+		}
+	}
+	if ranges {
+		if err := s.record(ctx, "range", pmEvent, kxEvent, arb.EvaluateRanges(pmEvent, kxEvent, s.rangeCfg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record prints and stores the best opportunity of one strategy for the
+// event pair.
+func (s *scanner) record(ctx context.Context, kind string, pmEvent, kxEvent *collectors.Event, result arb.Result) error {
+	if result.Best == nil {
+		logging.Debugf("[event-scanner] pm_event=%s kx_event=%s %s skipped (%s)", pmEvent.EventID, kxEvent.EventID, kind, result.Reason)
+		return nil
+	}
+	eventPayload := matches.NewEventPayload(*pmEvent, *kxEvent)
+	eventPayload.Arbitrage = result.Best
+	if !s.shouldEmit(ctx, kind+":"+eventPayload.PairID, result.Best) {
+		logging.Infof("[event-scanner] pm_event=%s kx_event=%s suppressed duplicate %s profit=%.4f", pmEvent.EventID, kxEvent.EventID, kind, result.Best.ProfitUSD)
+		return nil
+	}
+	fmt.Printf("[%s-opportunity] pm_event=%s kx_event=%s legs=%d qty=%.2f cost=%.4f profit=%.4f\n",
+		kind, pmEvent.EventID, kxEvent.EventID, len(result.Best.Legs), result.Best.Quantity, result.Best.TotalCostUSD, result.Best.ProfitUSD)
+	if err := s.store.InsertArbOpportunity(ctx, &eventPayload, result); err != nil {
+		return fmt.Errorf("sqlite insert: %w", err)
+	}
+	return nil
+}
+
+// pruneScanned forgets event pairs last scanned more than a rescan interval
+// ago, so the map holds only pairs still inside their window. It sweeps at
+// most once per interval.
+func (s *scanner) pruneScanned(now time.Time) {
+	if now.Sub(s.pruned) < s.rescan {
+		return
+	}
+	for key, last := range s.scanned {
+		if now.Sub(last) >= s.rescan {
+			delete(s.scanned, key)
+		}
+	}
+	s.pruned = now
+}
+
+// shouldEmit records an opportunity unless one at least as profitable was
+// already recorded under the same strategy and event pair.
+func (s *scanner) shouldEmit(ctx context.Context, key string, best *matches.Opportunity) bool {
+	if s.opportunities == nil {
+		return true
+	}
+	record, ok, err := s.opportunities.Get(ctx, key)
+	if err != nil {
+		logging.Errorf("[event-scanner] opportunity cache key=%s: %v", key, err)
+		return true
+	}
+	if ok && record != nil && record.ProfitUSD >= best.ProfitUSD {
+		return false
+	}
+	if err := s.opportunities.Set(ctx, key, cache.OpportunityRecord{
+		ProfitUSD:       best.ProfitUSD,
+		AnnualizedYield: best.AnnualizedYield,
+		Score:           best.Score,
+		Direction:       string(best.Direction),
+		Quantity:        best.Quantity,
+		UpdatedAt:       time.Now().UTC(),
+	}); err != nil {
+		logging.Errorf("[event-scanner] opportunity cache key=%s: %v", key, err)
+	}
+	return true
+}
+
+func snapshotForVenue(payload *matches.Payload, venue collectors.Venue) *models.MarketSnapshot {
+	switch {
+	case payload.Source.Venue == venue:
+		return &payload.Source
+	case payload.Target.Venue == venue:
+		return &payload.Target
+	default:
+		return nil
+	}
+}
+
+func mustOpportunityCache() cache.OpportunityCache {
+	addr := envString("REDIS_ADDR", "redis:6379")
+	if addr == "" {
+		return nil
+	}
+	ttlHours := envInt("OPPORTUNITY_CACHE_TTL_HOURS", 72)
+	cacheClient, err := cache.NewRedisOpportunityCache(addr, os.Getenv("REDIS_PASSWORD"), envInt("REDIS_DB", 0), time.Duration(ttlHours)*time.Hour, "event_best")
+	if err != nil {
+		logging.Fatalf("[event-scanner] redis opportunity cache: %v", err)
+	}
+	return cacheClient
+}
+
+func mustFeeSchedule() *arb.FeeSchedule {
+	sched, err := arb.LoadFeeSchedule(os.Getenv("ARB_FEE_SCHEDULE_PATH"))
+	if err != nil {
+		logging.Fatalf("[event-scanner] fee schedule: %v", err)
+	}
+	return &sched
+}
+
+func envInt(key string, def int) int {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.Atoi(val); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
+func envFloat(key string, def float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.ParseFloat(val, 64); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
+func envString(key, def string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return def
+}
+
+// envFloats parses a comma-separated list of floats, skipping bad entries.
+func envFloats(key string) []float64 {
+	var out []float64
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if parsed, err := strconv.ParseFloat(strings.TrimSpace(part), 64); err == nil {
+			out = append(out, parsed)
+		}
+	}
+	return out
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/arb"
+	"github.com/hetulpatel/Arbitrage/internal/cache"
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/kalshi"
+	"github.com/hetulpatel/Arbitrage/internal/logging"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+	"github.com/hetulpatel/Arbitrage/internal/polymarket"
+	sqlstore "github.com/hetulpatel/Arbitrage/internal/storage/sqlite"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	logging.InitFromEnv()
+
+	path := os.Getenv("IMPLICATIONS_PATH")
+	if path == "" {
+		logging.Fatalf("[implication-scanner] IMPLICATIONS_PATH is required")
+	}
+	implications, err := matches.LoadImplications(path)
+	if err != nil {
+		logging.Fatalf("[implication-scanner] %v", err)
+	}
+	relations := make([]relation, len(implications))
+	for i, imp := range implications {
+		relations[i] = relation{Implication: imp}
+	}
+	interval := time.Duration(envInt("IMPLICATION_SCANNER_INTERVAL_SECONDS", 30)) * time.Second
+
+	fees, err := arb.LoadFeeSchedule(os.Getenv("ARB_FEE_SCHEDULE_PATH"))
+	if err != nil {
+		logging.Fatalf("[implication-scanner] fee schedule: %v", err)
+	}
+	cfg := arb.Config{
+		BudgetUSD:       envFloat("IMPLICATION_SCANNER_BUDGET_USD", 100),
+		Fees:            &fees,
+		MaxSnapshotAge:  time.Duration(envInt("ARB_MAX_SNAPSHOT_AGE_SECONDS", 60)) * time.Second,
+		MaxSnapshotSkew: time.Duration(envInt("ARB_MAX_SNAPSHOT_SKEW_SECONDS", 10)) * time.Second,
+	}
+
+	store, err := sqlstore.Open(os.Getenv("SQLITE_PATH"))
+	if err != nil {
+		logging.Fatalf("[implication-scanner] open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	opportunityCache := mustOpportunityCache()
+	if opportunityCache != nil {
+		defer opportunityCache.Close()
+	}
+
+	s := scanner{
+		pmClient: polymarket.NewClient(polymarket.Config{
+			BaseURL: envString("POLYMARKET_API_URL", ""),
+			BookURL: envString("POLYMARKET_BOOK_URL", ""),
+			Timeout: time.Duration(envInt("POLYMARKET_HTTP_TIMEOUT_SECONDS", 20)) * time.Second,
+		}),
+		kxClient: kalshi.NewClient(kalshi.Config{
+			BaseURL:   envString("KALSHI_API_URL", ""),
+			SeriesURL: envString("KALSHI_SERIES_URL", ""),
+			BookURL:   envString("KALSHI_MARKET_URL", ""),
+			Timeout:   time.Duration(envInt("KALSHI_HTTP_TIMEOUT_SECONDS", 20)) * time.Second,
+		}),
+		cfg:           cfg,
+		store:         store,
+		opportunities: opportunityCache,
+	}
+
+	logging.Infof("[implication-scanner] watching %d relations every %s (budget=%.2f)", len(relations), interval, cfg.BudgetUSD)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for i := range relations {
+			rel := &relations[i]
+			if rel.rejected {
+				continue
+			}
+			if err := s.scan(ctx, rel); err != nil {
+				logging.Errorf("[implication-scanner] %s -> %s: %v", rel.Stricter.MarketID, rel.Looser.MarketID, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relation is a listed implication and whether it has been checked against
+// the live markets.
+type relation struct {
+	matches.Implication
+	validated bool
+	rejected  bool
+}
+
+type scanner struct {
+	pmClient      *polymarket.Client
+	kxClient      *kalshi.Client
+	cfg           arb.Config
+	store         *sqlstore.Store
+	opportunities cache.OpportunityCache
+}
+
+// scan refreshes both markets of one relation concurrently and records any
+// opportunity. The first successful refresh validates the relation; one that
+// does not hold is logged and dropped for the rest of the run.
+func (s scanner) scan(ctx context.Context, rel *relation) error {
+	var (
+		wg                     sync.WaitGroup
+		stricter, looser       *models.MarketSnapshot
+		stricterErr, looserErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		stricter, stricterErr = s.fetch(ctx, rel.Stricter)
+	}()
+	go func() {
+		defer wg.Done()
+		looser, looserErr = s.fetch(ctx, rel.Looser)
+	}()
+	wg.Wait()
+	if stricterErr != nil {
+		return fmt.Errorf("refresh stricter: %w", stricterErr)
+	}
+	if looserErr != nil {
+		return fmt.Errorf("refresh looser: %w", looserErr)
+	}
+
+	if !rel.validated {
+		if err := arb.ValidateImplication(stricter, looser); err != nil {
+			rel.rejected = true
+			logging.Errorf("[implication-scanner] %s -> %s rejected: %v", rel.Stricter.MarketID, rel.Looser.MarketID, err)
+			return nil
+		}
+		rel.validated = true
+	}
+
+	result := arb.EvaluateImplication(stricter, looser, s.cfg)
+	if result.Best == nil {
+		logging.Debugf("[implication-scanner] %s -> %s skipped (%s)", rel.Stricter.MarketID, rel.Looser.MarketID, result.Reason)
+		return nil
+	}
+	payload := matches.NewPayload(*stricter, *looser, 0, 0)
+	payload.Arbitrage = result.Best
+	if !s.shouldEmit(ctx, payload.PairID, result.Best) {
+		logging.Infof("[implication-scanner] %s -> %s suppressed duplicate profit=%.4f", rel.Stricter.MarketID, rel.Looser.MarketID, result.Best.ProfitUSD)
+		return nil
+	}
+	fmt.Printf("[implication-opportunity] stricter=%s looser=%s qty=%.2f cost=%.4f profit_floor=%.4f payout_max=%.2f\n",
+		rel.Stricter.MarketID, rel.Looser.MarketID, result.Best.Quantity, result.Best.TotalCostUSD, result.Best.ProfitUSD, result.Best.PayoutMaxUSD)
+	if err := s.store.InsertArbOpportunity(ctx, &payload, result); err != nil {
+		return fmt.Errorf("sqlite insert: %w", err)
+	}
+	return nil
+}
+
+// shouldEmit records an opportunity unless one at least as profitable was
+// already recorded for the pair.
+func (s scanner) shouldEmit(ctx context.Context, pairID string, best *matches.Opportunity) bool {
+	if s.opportunities == nil {
+		return true
+	}
+	record, ok, err := s.opportunities.Get(ctx, pairID)
+	if err != nil {
+		logging.Errorf("[implication-scanner] opportunity cache pair=%s: %v", pairID, err)
+		return true
+	}
+	if ok && record != nil && record.ProfitUSD >= best.ProfitUSD {
+		return false
+	}
+	if err := s.opportunities.Set(ctx, pairID, cache.OpportunityRecord{
+		ProfitUSD:       best.ProfitUSD,
+		AnnualizedYield: best.AnnualizedYield,
+		Score:           best.Score,
+		Direction:       string(best.Direction),
+		Quantity:        best.Quantity,
+		UpdatedAt:       time.Now().UTC(),
+	}); err != nil {
+		logging.Errorf("[implication-scanner] opportunity cache pair=%s: %v", pairID, err)
+	}
+	return true
+}
+
+func (s scanner) fetch(ctx context.Context, ref matches.MarketRef) (*models.MarketSnapshot, error) {
+	switch ref.Venue {
+	case collectors.VenuePolymarket:
+		return s.pmClient.MarketSnapshot(ctx, ref.EventID, ref.MarketID)
+	case collectors.VenueKalshi:
+		return s.kxClient.MarketSnapshot(ctx, ref.EventID, ref.MarketID, "")
+	default:
+		return nil, fmt.Errorf("unknown venue %q", ref.Venue)
+	}
+}
+
+func mustOpportunityCache() cache.OpportunityCache {
+	addr := envString("REDIS_ADDR", "redis:6379")
+	if addr == "" {
+		return nil
+	}
+	ttlHours := envInt("OPPORTUNITY_CACHE_TTL_HOURS", 72)
+	cacheClient, err := cache.NewRedisOpportunityCache(addr, os.Getenv("REDIS_PASSWORD"), envInt("REDIS_DB", 0), time.Duration(ttlHours)*time.Hour, "implication_best")
+	if err != nil {
+		logging.Fatalf("[implication-scanner] redis opportunity cache: %v", err)
+	}
+	return cacheClient
+}
+
+func envInt(key string, def int) int {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.Atoi(val); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
+func envFloat(key string, def float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.ParseFloat(val, 64); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
+func envString(key, def string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return def
+}
@@ -169,7 +169,8 @@ func publishMatch(ctx context.Context, writer *kafkago.Writer, source *models.Ma
 	payload := matches.NewPayload(sourceCopy, targetCopy, res.Similarity, res.Distance)
 	if res.CachedVerdict {
 		payload.CachedVerdict = true
-		payload.ResolutionVerdict = matches.NewResolutionVerdict(true, "cached SAFE verdict")
+		payload.ResolutionVerdict = matches.NewResolutionVerdict(true, "cached SAFE verdict", res.CachedMapping)
+		payload.ResolutionVerdict.Confidence = res.CachedConfidence
 	}
 	data, err := json.Marshal(payload)
 	if err != nil {
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/arb"
+	"github.com/hetulpatel/Arbitrage/internal/cache"
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/kafka"
+	"github.com/hetulpatel/Arbitrage/internal/kalshi"
+	"github.com/hetulpatel/Arbitrage/internal/logging"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+	"github.com/hetulpatel/Arbitrage/internal/paper"
+	"github.com/hetulpatel/Arbitrage/internal/polymarket"
+	"github.com/hetulpatel/Arbitrage/internal/risk"
+	sqlstore "github.com/hetulpatel/Arbitrage/internal/storage/sqlite"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	logging.InitFromEnv()
+
+	brokers := kafka.Brokers()
+	topic := kafka.TopicFromEnv("OPPORTUNITIES_KAFKA_TOPIC", kafka.DefaultOpportunityTopic)
+	group := envString("PAPER_TRADER_GROUP", "paper-trader")
+	interval := time.Duration(envInt("PAPER_MARK_INTERVAL_SECONDS", 60)) * time.Second
+
+	fees, err := arb.LoadFeeSchedule(os.Getenv("ARB_FEE_SCHEDULE_PATH"))
+	if err != nil {
+		logging.Fatalf("[paper-trader] fee schedule: %v", err)
+	}
+	store, err := sqlstore.Open(envString("SQLITE_PATH", "data/arb.db"))
+	if err != nil {
+		logging.Fatalf("[paper-trader] sqlite open: %v", err)
+	}
+	defer store.Close()
+	if err := store.CreateTables(ctx); err != nil {
+		logging.Fatalf("[paper-trader] sqlite create tables: %v", err)
+	}
+	killSwitch := mustKillSwitch()
+	defer killSwitch.Close()
+
+	t := &trader{
+		pmClient: polymarket.NewClient(polymarket.Config{
+			BaseURL: envString("POLYMARKET_API_URL", ""),
+			BookURL: envString("POLYMARKET_BOOK_URL", ""),
+			Timeout: time.Duration(envInt("POLYMARKET_HTTP_TIMEOUT_SECONDS", 20)) * time.Second,
+		}),
+		kxClient: kalshi.NewClient(kalshi.Config{
+			BaseURL:   envString("KALSHI_API_URL", ""),
+			SeriesURL: envString("KALSHI_SERIES_URL", ""),
+			BookURL:   envString("KALSHI_MARKET_URL", ""),
+			Timeout:   time.Duration(envInt("KALSHI_HTTP_TIMEOUT_SECONDS", 20)) * time.Second,
+		}),
+		store:     store,
+		fees:      fees,
+		fillDelay: time.Duration(envInt("PAPER_FILL_DELAY_SECONDS", 2)) * time.Second,
+		guard:     &risk.Guard{Limits: risk.LimitsFromEnv(), Switch: killSwitch},
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 45*time.Second)
+	if err := kafka.WaitForBroker(waitCtx, brokers); err != nil {
+		logging.Fatalf("[paper-trader] wait for broker: %v", err)
+	}
+	cancel()
+	go t.consume(ctx, brokers, topic, group)
+
+	logging.Infof("[paper-trader] consuming %s with group %s, marking every %s (fill_delay=%s)", topic, group, interval, t.fillDelay)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.markAll(ctx)
+		}
+	}
+}
+
+type trader struct {
+	pmClient *polymarket.Client
+	kxClient *kalshi.Client
+	store    *sqlstore.Store
+	fees     arb.FeeSchedule
+	// fillDelay stands in for order latency: legs fill against books
+	// fetched this long after the opportunity arrives.
+	fillDelay time.Duration
+	// guard applies the live risk limits to the paper book.
+	guard *risk.Guard
+}
+
+func (t *trader) consume(ctx context.Context, brokers []string, topic, group string) {
+	reader := kafka.NewReader(brokers, topic, group)
+	defer reader.Close()
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logging.Errorf("[paper-trader] read error: %v", err)
+			continue
+		}
+		var payload matches.Payload
+		if err := json.Unmarshal(msg.Value, &payload); err != nil {
+			logging.Errorf("[paper-trader] unmarshal error: %v", err)
+			continue
+		}
+		if err := t.open(ctx, &payload); err != nil {
+			logging.Errorf("[paper-trader] pair=%s: %v", payload.PairID, err)
+		}
+	}
+}
+
+// open paper-trades a published opportunity against freshly fetched books.
+// A pair with an open position is not traded again until it settles, and
+// trades the risk guard refuses are recorded instead of filled.
+func (t *trader) open(ctx context.Context, payload *matches.Payload) error {
+	op := payload.FinalOpportunity
+	if op == nil || len(op.Orders) == 0 {
+		return nil
+	}
+	held, err := t.store.HasOpenPaperPosition(ctx, payload.PairID)
+	if err != nil {
+		return fmt.Errorf("check open position: %w", err)
+	}
+	if held {
+		logging.Debugf("[paper-trader] pair=%s already has an open position", payload.PairID)
+		return nil
+	}
+
+	known := payloadSnapshots(payload)
+	var refs []matches.MarketRef
+	for _, order := range op.Orders {
+		snap := known[paper.SnapshotKey(order.Venue, order.MarketID)]
+		if snap == nil {
+			return fmt.Errorf("no snapshot for order market %s %s", order.Venue, order.MarketID)
+		}
+		refs = append(refs, matches.MarketRef{Venue: snap.Venue, EventID: snap.Event.EventID, MarketID: order.MarketID})
+	}
+	if blocked, err := t.blocked(ctx, payload.PairID, op, known); blocked || err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(t.fillDelay):
+	}
+	snaps, err := t.fetchAll(ctx, refs)
+	if err != nil {
+		return err
+	}
+	pos, err := paper.Open(payload.PairID, op, snaps, t.fees, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if err := t.store.SavePaperPosition(ctx, pos); err != nil {
+		return err
+	}
+	fmt.Printf("[paper-open] pair=%s strategy=%s dir=%s hedged=%.2f cost=%.4f planned_profit=%.4f slippage=%.4f mark=%.4f\n",
+		pos.PairID, pos.Strategy, pos.Direction, pos.HedgedQuantity(), pos.EntryCostUSD, pos.PlannedProfitUSD, pos.SlippageUSD, pos.MarkValueUSD)
+	return nil
+}
+
+// blocked checks the trade against the risk limits and the paper book,
+// recording it when refused.
+func (t *trader) blocked(ctx context.Context, pairID string, op *matches.Opportunity, snaps map[string]*models.MarketSnapshot) (bool, error) {
+	trade, err := risk.NewTrade(pairID, op, snaps)
+	if err != nil {
+		return false, err
+	}
+	positions, err := t.store.PaperPositions(ctx, "")
+	if err != nil {
+		return false, fmt.Errorf("load positions: %w", err)
+	}
+	now := time.Now().UTC()
+	block := t.guard.Check(ctx, "paper", risk.PaperBook(positions, risk.DayStart(now)), trade)
+	if block == nil {
+		return false, nil
+	}
+	if err := t.store.SaveRiskBlock(ctx, block); err != nil {
+		return true, err
+	}
+	fmt.Printf("[risk-block] source=%s pair=%s dir=%s reason=%s notional=%.2f detail=%q\n",
+		block.Source, block.PairID, block.Direction, block.Reason, block.NotionalUSD, block.Detail)
+	return true, nil
+}
+
+// markAll settles open positions whose markets have closed, marks the rest
+// to the latest bids and prints P&L per strategy.
+func (t *trader) markAll(ctx context.Context) {
+	open, err := t.store.PaperPositions(ctx, paper.StatusOpen)
+	if err != nil {
+		logging.Errorf("[paper-trader] load open positions: %v", err)
+		return
+	}
+	for i := range open {
+		pos := &open[i]
+		now := time.Now().UTC()
+		if pos.Due(now) {
+			pos.Settle(now)
+			fmt.Printf("[paper-settle] pair=%s strategy=%s settlement=%.4f realized=%.4f\n",
+				pos.PairID, pos.Strategy, pos.SettlementUSD, pos.RealizedUSD())
+		} else {
+			refs := make([]matches.MarketRef, len(pos.Legs))
+			for j, leg := range pos.Legs {
+				refs[j] = leg.MarketRef
+			}
+			snaps, err := t.fetchAll(ctx, refs)
+			if err != nil {
+				logging.Errorf("[paper-trader] mark pair=%s: %v", pos.PairID, err)
+				continue
+			}
+			pos.Mark(snaps, t.fees, now)
+		}
+		if err := t.store.SavePaperPosition(ctx, pos); err != nil {
+			logging.Errorf("[paper-trader] save pair=%s: %v", pos.PairID, err)
+		}
+	}
+
+	all, err := t.store.PaperPositions(ctx, "")
+	if err != nil {
+		logging.Errorf("[paper-trader] load positions: %v", err)
+		return
+	}
+	for _, s := range paper.Summarize(all) {
+		fmt.Printf("[paper-pnl] strategy=%s open=%d settled=%d cost=%.2f planned=%.4f realized=%.4f unrealized=%.4f fees=%.4f slippage=%.4f\n",
+			s.Strategy, s.Open, s.Settled, s.CostUSD, s.PlannedUSD, s.RealizedUSD, s.UnrealizedUSD, s.FeesUSD, s.SlippageUSD)
+	}
+}
+
+// fetchAll refreshes every market concurrently so the legs are priced
+// against books captured at about the same time.
+func (t *trader) fetchAll(ctx context.Context, refs []matches.MarketRef) (map[string]*models.MarketSnapshot, error) {
+	snaps := make([]*models.MarketSnapshot, len(refs))
+	errs := make([]error, len(refs))
+	var wg sync.WaitGroup
+	for i, ref := range refs {
+		wg.Add(1)
+		go func(i int, ref matches.MarketRef) {
+			defer wg.Done()
+			snaps[i], errs[i] = t.fetch(ctx, ref)
+		}(i, ref)
+	}
+	wg.Wait()
+	out := make(map[string]*models.MarketSnapshot, len(refs))
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("refresh %s: %w", refs[i].MarketID, err)
+		}
+		out[paper.SnapshotKey(string(refs[i].Venue), refs[i].MarketID)] = snaps[i]
+	}
+	return out, nil
+}
+
+func (t *trader) fetch(ctx context.Context, ref matches.MarketRef) (*models.MarketSnapshot, error) {
+	switch ref.Venue {
+	case collectors.VenuePolymarket:
+		return t.pmClient.MarketSnapshot(ctx, ref.EventID, ref.MarketID)
+	case collectors.VenueKalshi:
+		return t.kxClient.MarketSnapshot(ctx, ref.EventID, ref.MarketID, "")
+	default:
+		return nil, fmt.Errorf("unknown venue %q", ref.Venue)
+	}
+}
+
+// payloadSnapshots indexes every snapshot the payload carries, preferring
+// the fresh ones from the final stage, so order markets can be resolved to
+// their events.
+func payloadSnapshots(payload *matches.Payload) map[string]*models.MarketSnapshot {
+	out := make(map[string]*models.MarketSnapshot)
+	add := func(snap *models.MarketSnapshot) {
+		if snap == nil || snap.Market.MarketID == "" {
+			return
+		}
+		key := paper.SnapshotKey(string(snap.Venue), snap.Market.MarketID)
+		if _, ok := out[key]; !ok {
+			out[key] = snap
+		}
+	}
+	if payload.Fresh != nil {
+		add(payload.Fresh.Polymarket)
+		add(payload.Fresh.Kalshi)
+	}
+	add(&payload.Source)
+	add(&payload.Target)
+	return out
+}
+
+func mustKillSwitch() cache.KillSwitch {
+	ks, err := cache.NewRedisKillSwitch(envString("REDIS_ADDR", "redis:6379"), os.Getenv("REDIS_PASSWORD"), envInt("REDIS_DB", 0), envString("RISK_KILL_SWITCH_KEY", cache.DefaultKillSwitchKey))
+	if err != nil {
+		logging.Fatalf("[paper-trader] redis kill switch: %v", err)
+	}
+	return ks
+}
+
+func envInt(key string, def int) int {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.Atoi(val); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
+func envString(key, def string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return def
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"github.com/hetulpatel/Arbitrage/internal/arb"
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/kafka"
+	"github.com/hetulpatel/Arbitrage/internal/logging"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	logging.InitFromEnv()
+
+	brokers := kafka.Brokers()
+	topic := kafka.TopicFromEnv("MATCHES_KAFKA_TOPIC", kafka.DefaultMatchTopic)
+	group := envString("QUOTE_WORKER_GROUP", "quote-worker")
+	quoteTopic := kafka.TopicFromEnv("QUOTES_KAFKA_TOPIC", kafka.DefaultQuoteTopic)
+
+	fees, err := arb.LoadFeeSchedule(os.Getenv("ARB_FEE_SCHEDULE_PATH"))
+	if err != nil {
+		logging.Fatalf("[quote-worker] fee schedule: %v", err)
+	}
+	cfg := arb.QuoteConfig{
+		TargetEdgeUSD:   envFloat("QUOTE_TARGET_EDGE_USD", 0.01),
+		BudgetUSD:       envFloat("QUOTE_BUDGET_USD", 100),
+		MaxQuantity:     envFloat("QUOTE_MAX_QUANTITY", 0),
+		Fees:            &fees,
+		MaxSnapshotAge:  time.Duration(envInt("ARB_MAX_SNAPSHOT_AGE_SECONDS", 60)) * time.Second,
+		MaxSnapshotSkew: time.Duration(envInt("QUOTE_MAX_SNAPSHOT_SKEW_SECONDS", 30)) * time.Second,
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 45*time.Second)
+	if err := kafka.WaitForBroker(waitCtx, brokers); err != nil {
+		logging.Fatalf("[quote-worker] wait for broker: %v", err)
+	}
+	cancel()
+	ensureCtx, cancelEnsure := context.WithTimeout(ctx, 30*time.Second)
+	if err := kafka.EnsureTopic(ensureCtx, brokers, quoteTopic); err != nil {
+		logging.Errorf("[quote-worker] ensure topic warning: %v", err)
+	}
+	cancelEnsure()
+	writer := kafka.NewWriter(brokers, quoteTopic)
+	defer writer.Close()
+	reader := kafka.NewReader(brokers, topic, group)
+	defer reader.Close()
+
+	w := &worker{cfg: cfg, writer: writer, pairs: make(map[string]*pairState)}
+	logging.Infof("[quote-worker] consuming %s with group %s publishing to %s (edge=%.4f budget=%.2f)", topic, group, quoteTopic, cfg.TargetEdgeUSD, cfg.BudgetUSD)
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logging.Errorf("[quote-worker] read error: %v", err)
+			continue
+		}
+		var payload matches.Payload
+		if err := json.Unmarshal(msg.Value, &payload); err != nil {
+			logging.Errorf("[quote-worker] unmarshal error: %v", err)
+			continue
+		}
+		if err := w.handle(ctx, &payload); err != nil {
+			logging.Errorf("[quote-worker] pair=%s: %v", payload.PairID, err)
+		}
+	}
+}
+
+// pairState is the newest snapshot seen for each leg of a pair plus the
+// quote currently resting, so a new snapshot of either leg re-prices the
+// pair against the latest view of the other.
+type pairState struct {
+	pm, kx  *models.MarketSnapshot
+	verdict *matches.ResolutionVerdict
+	resting *matches.Quote
+}
+
+type worker struct {
+	cfg    arb.QuoteConfig
+	writer *kafkago.Writer
+	pairs  map[string]*pairState
+}
+
+func (w *worker) handle(ctx context.Context, payload *matches.Payload) error {
+	st := w.pairs[payload.PairID]
+	if st == nil {
+		st = &pairState{}
+		w.pairs[payload.PairID] = st
+	}
+	for _, snap := range []models.MarketSnapshot{payload.Source, payload.Target} {
+		snap := snap
+		switch snap.Venue {
+		case collectors.VenuePolymarket:
+			st.pm = newer(st.pm, &snap)
+		case collectors.VenueKalshi:
+			st.kx = newer(st.kx, &snap)
+		}
+	}
+	if v := payload.ResolutionVerdict; v != nil && v.ValidResolution {
+		st.verdict = v
+	}
+	// Quotes rest for minutes; only pairs the validator has cleared are
+	// quoted, so the outcome mapping is known.
+	if st.verdict == nil || st.pm == nil || st.kx == nil {
+		return nil
+	}
+
+	quotes, reason := arb.ComputeQuotes(st.pm, st.kx, st.verdict, w.cfg, time.Now().UTC())
+	if len(quotes) == 0 {
+		logging.Debugf("[quote-worker] pair=%s no quote (%s)", payload.PairID, reason)
+		if st.resting == nil {
+			return nil
+		}
+		cancel := &matches.Quote{PairID: payload.PairID, Status: matches.QuoteCancel, GeneratedAt: time.Now().UTC()}
+		if err := w.publish(ctx, cancel); err != nil {
+			return err
+		}
+		st.resting = nil
+		return nil
+	}
+
+	best := quotes[0]
+	best.PairID = payload.PairID
+	if sameQuote(st.resting, best) {
+		return nil
+	}
+	fmt.Printf("[quote] pair=%s maker=%s %s %s@%.4f qty=%.2f hedge=%s %s@%.4f edge=%.4f top=%t\n",
+		best.PairID, best.MakerVenue, best.Maker.MarketID, best.Maker.Outcome, best.Maker.LimitPrice, best.Quantity,
+		best.Hedge.Venue, best.Hedge.Outcome, best.Hedge.LimitPrice, best.EdgeUSD, best.AtTop())
+	if err := w.publish(ctx, best); err != nil {
+		return err
+	}
+	st.resting = best
+	return nil
+}
+
+func (w *worker) publish(ctx context.Context, q *matches.Quote) error {
+	data, err := json.Marshal(q)
+	if err != nil {
+		return fmt.Errorf("marshal quote: %w", err)
+	}
+	msg := kafkago.Message{
+		Key:   []byte(q.PairID),
+		Value: data,
+		Time:  q.GeneratedAt,
+	}
+	if err := w.writer.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("publish quote: %w", err)
+	}
+	return nil
+}
+
+// newer keeps whichever snapshot of the same market was captured last; the
+// matched neighbour in a payload can be older than what we already hold.
+func newer(held, snap *models.MarketSnapshot) *models.MarketSnapshot {
+	if held == nil || held.Market.MarketID != snap.Market.MarketID || !snap.CapturedAt.Before(held.CapturedAt) {
+		return snap
+	}
+	return held
+}
+
+// sameQuote reports whether republishing q would change nothing for the
+// resting order.
+func sameQuote(resting, q *matches.Quote) bool {
+	if resting == nil || resting.Maker == nil || q.Maker == nil {
+		return false
+	}
+	return resting.Direction == q.Direction &&
+		resting.MakerVenue == q.MakerVenue &&
+		resting.Quantity == q.Quantity &&
+		resting.Maker.LimitPrice == q.Maker.LimitPrice &&
+		resting.Hedge.LimitPrice == q.Hedge.LimitPrice
+}
+
+func envInt(key string, def int) int {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.Atoi(val); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
+func envFloat(key string, def float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.ParseFloat(val, 64); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
+func envString(key, def string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return def
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/kalshi"
+	"github.com/hetulpatel/Arbitrage/internal/ledger"
+	"github.com/hetulpatel/Arbitrage/internal/logging"
+	"github.com/hetulpatel/Arbitrage/internal/polymarket"
+	sqlstore "github.com/hetulpatel/Arbitrage/internal/storage/sqlite"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	logging.InitFromEnv()
+
+	interval := time.Duration(envInt("SETTLEMENT_INTERVAL_SECONDS", 300)) * time.Second
+	store, err := sqlstore.Open(envString("SQLITE_PATH", "data/arb.db"))
+	if err != nil {
+		logging.Fatalf("[settlement-worker] sqlite open: %v", err)
+	}
+	defer store.Close()
+	if err := store.CreateTables(ctx); err != nil {
+		logging.Fatalf("[settlement-worker] sqlite create tables: %v", err)
+	}
+
+	w := &worker{
+		pmClient: polymarket.NewClient(polymarket.Config{
+			MarketURL: envString("POLYMARKET_MARKET_URL", ""),
+			Timeout:   time.Duration(envInt("POLYMARKET_HTTP_TIMEOUT_SECONDS", 20)) * time.Second,
+		}),
+		kxClient: kalshi.NewClient(kalshi.Config{
+			BookURL: envString("KALSHI_MARKET_URL", ""),
+			Timeout: time.Duration(envInt("KALSHI_HTTP_TIMEOUT_SECONDS", 20)) * time.Second,
+		}),
+		store: store,
+	}
+
+	logging.Infof("[settlement-worker] settling the ledger every %s", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		w.run(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+type worker struct {
+	pmClient *polymarket.Client
+	kxClient *kalshi.Client
+	store    *sqlstore.Store
+}
+
+// run brings new trades into the ledger, settles positions whose markets
+// have resolved and prints P&L per source and strategy.
+func (w *worker) run(ctx context.Context) {
+	if err := w.sync(ctx); err != nil {
+		logging.Errorf("[settlement-worker] sync ledger: %v", err)
+	}
+
+	open, err := w.store.LedgerPositions(ctx, ledger.StatusOpen)
+	if err != nil {
+		logging.Errorf("[settlement-worker] load open positions: %v", err)
+		return
+	}
+	// Positions share markets; fetch each resolution once per pass.
+	resolutions := make(map[string]collectors.Resolution)
+	for i := range open {
+		pos := &open[i]
+		now := time.Now().UTC()
+		if !pos.Due(now) {
+			continue
+		}
+		if err := w.resolve(ctx, pos, resolutions); err != nil {
+			logging.Errorf("[settlement-worker] resolve pair=%s: %v", pos.PairID, err)
+			continue
+		}
+		if !pos.Settle(resolutions, now) {
+			continue
+		}
+		if err := w.store.SaveLedgerPosition(ctx, pos); err != nil {
+			logging.Errorf("[settlement-worker] save pair=%s: %v", pos.PairID, err)
+			continue
+		}
+		fmt.Printf("[settle] source=%s pair=%s strategy=%s results=%s payout=%.4f realized=%.4f\n",
+			pos.Source, pos.PairID, pos.Strategy, results(pos), pos.PayoutUSD, pos.RealizedUSD())
+		if pos.Divergent {
+			fmt.Printf("[settle-divergent] source=%s pair=%s strategy=%s dir=%s results=%s set_payout=%.2f expected=%.2f realized=%.4f\n",
+				pos.Source, pos.PairID, pos.Strategy, pos.Direction, results(pos), pos.SetPayout, pos.PayoutPerUnit, pos.RealizedUSD())
+		}
+	}
+
+	all, err := w.store.LedgerPositions(ctx, "")
+	if err != nil {
+		logging.Errorf("[settlement-worker] load positions: %v", err)
+		return
+	}
+	for _, s := range ledger.Summarize(all) {
+		fmt.Printf("[ledger-pnl] source=%s strategy=%s open=%d settled=%d divergent=%d cost=%.2f realized=%.4f\n",
+			s.Source, s.Strategy, s.Open, s.Settled, s.Divergent, s.CostUSD, s.RealizedUSD)
+	}
+}
+
+// sync adds finished executions and paper positions the ledger has not
+// seen yet; rows already present are left alone.
+func (w *worker) sync(ctx context.Context) error {
+	execs, err := w.store.Executions(ctx, "")
+	if err != nil {
+		return fmt.Errorf("load executions: %w", err)
+	}
+	for i := range execs {
+		if !execs[i].Status.Terminal() {
+			continue
+		}
+		pos, ok := ledger.FromExecution(&execs[i])
+		if !ok {
+			continue
+		}
+		if err := w.store.SaveLedgerPosition(ctx, &pos); err != nil {
+			return err
+		}
+	}
+	papers, err := w.store.PaperPositions(ctx, "")
+	if err != nil {
+		return fmt.Errorf("load paper positions: %w", err)
+	}
+	for i := range papers {
+		pos := ledger.FromPaper(&papers[i])
+		if err := w.store.SaveLedgerPosition(ctx, &pos); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve fetches the resolution of every leg market not already known.
+func (w *worker) resolve(ctx context.Context, pos *ledger.Position, resolutions map[string]collectors.Resolution) error {
+	for _, leg := range pos.Legs {
+		key := ledger.MarketKey(leg.Venue, leg.MarketID)
+		if _, ok := resolutions[key]; ok {
+			continue
+		}
+		var (
+			res collectors.Resolution
+			err error
+		)
+		switch leg.Venue {
+		case collectors.VenuePolymarket:
+			res, err = w.pmClient.MarketResolution(ctx, leg.MarketID)
+		case collectors.VenueKalshi:
+			res, err = w.kxClient.MarketResolution(ctx, leg.MarketID)
+		default:
+			err = fmt.Errorf("unknown venue %q", leg.Venue)
+		}
+		if err != nil {
+			return err
+		}
+		resolutions[key] = res
+	}
+	return nil
+}
+
+// results lists each leg's market result, e.g. "kalshi:yes,polymarket:no".
+func results(pos *ledger.Position) string {
+	parts := make([]string, len(pos.Legs))
+	for i, leg := range pos.Legs {
+		parts[i] = string(leg.Venue) + ":" + leg.Result
+	}
+	return strings.Join(parts, ",")
+}
+
+func envInt(key string, def int) int {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.Atoi(val); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
+func envString(key, def string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return def
+}
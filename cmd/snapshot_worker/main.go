@@ -7,19 +7,27 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	kafkago "github.com/segmentio/kafka-go"
+
 	"github.com/hetulpatel/Arbitrage/internal/arb"
 	"github.com/hetulpatel/Arbitrage/internal/cache"
 	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/execution"
 	"github.com/hetulpatel/Arbitrage/internal/kafka"
 	"github.com/hetulpatel/Arbitrage/internal/kalshi"
+	"github.com/hetulpatel/Arbitrage/internal/ledger"
 	"github.com/hetulpatel/Arbitrage/internal/llm"
 	"github.com/hetulpatel/Arbitrage/internal/logging"
 	"github.com/hetulpatel/Arbitrage/internal/matches"
 	"github.com/hetulpatel/Arbitrage/internal/models"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+	"github.com/hetulpatel/Arbitrage/internal/paper"
 	"github.com/hetulpatel/Arbitrage/internal/polymarket"
+	"github.com/hetulpatel/Arbitrage/internal/risk"
 	sqlstore "github.com/hetulpatel/Arbitrage/internal/storage/sqlite"
 	"github.com/hetulpatel/Arbitrage/internal/validator"
 )
@@ -45,6 +53,15 @@ func main() {
 	}
 	cancel()
 
+	oppTopic := kafka.TopicFromEnv("OPPORTUNITIES_KAFKA_TOPIC", kafka.DefaultOpportunityTopic)
+	ensureCtx, cancelEnsure := context.WithTimeout(ctx, 30*time.Second)
+	if err := kafka.EnsureTopic(ensureCtx, brokers, oppTopic); err != nil {
+		logging.Errorf("[snapshot-worker] ensure topic warning: %v", err)
+	}
+	cancelEnsure()
+	oppWriter := kafka.NewWriter(brokers, oppTopic)
+	defer oppWriter.Close()
+
 	llmClient := mustLLMClient()
 	valSvc := mustValidatorService(llmClient)
 	pmClient := mustPolymarketClient()
@@ -59,6 +76,34 @@ func main() {
 	}
 	store := mustSQLiteStore()
 	defer store.Close()
+	fees := mustFeeSchedule()
+	tradability := mustTradabilityPolicy()
+	rankBy := matches.ParseRankMetric(envString("ARB_RANK_BY", string(matches.RankByScore)))
+	scoring := mustScoreWeights()
+	firstVenue := collectors.Venue(envString("EXECUTOR_FIRST_VENUE", string(collectors.VenueKalshi)))
+	executor := mustExecutor(ctx, store, fees, firstVenue)
+	var guard *risk.Guard
+	if executor != nil {
+		killSwitch := mustKillSwitch()
+		defer killSwitch.Close()
+		guard = &risk.Guard{Limits: risk.LimitsFromEnv(), Switch: killSwitch}
+	}
+
+	bookChanges := arb.NewBookChangeTracker(map[collectors.Venue]float64{
+		collectors.VenueKalshi:     envFloat("LEG_RISK_KALSHI_CHANGES_PER_SECOND", 0.2),
+		collectors.VenuePolymarket: envFloat("LEG_RISK_POLYMARKET_CHANGES_PER_SECOND", 0.5),
+	}, time.Duration(envInt("LEG_RISK_MIN_HISTORY_SECONDS", 600))*time.Second)
+	if history, err := store.BookChangeHistory(ctx); err != nil {
+		logging.Errorf("[snapshot-worker] load book change history: %v", err)
+	} else {
+		bookChanges.Restore(history)
+	}
+	go saveBookChanges(ctx, store, bookChanges, time.Duration(envInt("LEG_RISK_SAVE_SECONDS", 60))*time.Second)
+	defer func() {
+		if err := store.SaveBookChangeHistory(context.Background(), bookChanges.History()); err != nil {
+			logging.Errorf("[snapshot-worker] save book change history: %v", err)
+		}
+	}()
 
 	logging.Infof("[snapshot-worker] consuming %s with group %s (%d workers, budget=%.2f)", topic, group, workerCount, budget)
 	runWorkers(ctx, brokers, topic, group, workerCount, budget, workerDeps{
@@ -66,9 +111,31 @@ func main() {
 		pmClient:         pmClient,
 		kxClient:         kxClient,
 		finalBudget:      budget,
+		sweepBudgets:     envFloats("SNAPSHOT_WORKER_BUDGETS_USD"),
+		fees:             fees,
+		tradability:      tradability,
+		maxSnapshotAge:   time.Duration(envInt("ARB_MAX_SNAPSHOT_AGE_SECONDS", 60)) * time.Second,
+		maxSnapshotSkew:  time.Duration(envInt("ARB_MAX_SNAPSHOT_SKEW_SECONDS", 10)) * time.Second,
+		rankBy:           rankBy,
+		scoring:          scoring,
+		minScore:         envFloat("ARB_MIN_SCORE", 0),
 		verdictCache:     verdictCache,
 		opportunityCache: opportunityCache,
 		store:            store,
+		oppWriter:        oppWriter,
+		bookChanges:      bookChanges,
+		legRisk: arb.LegRiskConfig{
+			Fees:            fees,
+			Trials:          envInt("LEG_RISK_TRIALS", 2000),
+			LegDelay:        time.Duration(envInt("LEG_RISK_DELAY_MS", 2000)) * time.Millisecond,
+			AdverseMoveProb: envFloat("LEG_RISK_ADVERSE_MOVE_PROB", 0.6),
+			PartialFillProb: envFloat("LEG_RISK_PARTIAL_FILL_PROB", 0.1),
+		},
+		maxLossProb: envFloat("LEG_RISK_MAX_LOSS_PROB", 0.05),
+		executor:    executor,
+		firstVenue:  firstVenue,
+		guard:       guard,
+		execMu:      &sync.Mutex{},
 	})
 }
 
@@ -77,9 +144,27 @@ type workerDeps struct {
 	pmClient         *polymarket.Client
 	kxClient         *kalshi.Client
 	finalBudget      float64
+	sweepBudgets     []float64
+	fees             *arb.FeeSchedule
+	tradability      *arb.TradabilityPolicy
+	maxSnapshotAge   time.Duration
+	maxSnapshotSkew  time.Duration
+	rankBy           matches.RankMetric
+	scoring          *matches.ScoreWeights
+	minScore         float64
 	verdictCache     cache.VerdictCache
 	opportunityCache cache.OpportunityCache
 	store            *sqlstore.Store
+	oppWriter        *kafkago.Writer
+	bookChanges      *arb.BookChangeTracker
+	legRisk          arb.LegRiskConfig
+	firstVenue       collectors.Venue
+	maxLossProb      float64
+	executor         *execution.Executor
+	guard            *risk.Guard
+	// execMu serializes risk checks and executions across consumer
+	// goroutines so two trades cannot both pass the same limit.
+	execMu *sync.Mutex
 }
 
 func runWorkers(ctx context.Context, brokers []string, topic, group string, workerCount int, budget float64, deps workerDeps) {
@@ -111,6 +196,11 @@ func consume(ctx context.Context, brokers []string, topic, group string, budget
 	cfg := arb.Config{
 		BudgetUSD:    budget,
 		ForceVerdict: forceFirst,
+		Fees:         deps.fees,
+		Tradability:  deps.tradability,
+		RankBy:       deps.rankBy,
+		Scoring:      deps.scoring,
+		AnyMapping:   true,
 	}
 	for {
 		msg, err := reader.ReadMessage(ctx)
@@ -127,6 +217,8 @@ func consume(ctx context.Context, brokers []string, topic, group string, budget
 			logging.Errorf("[snapshot-worker] unmarshal error: %v", err)
 			continue
 		}
+		deps.bookChanges.Observe(&payload.Source)
+		deps.bookChanges.Observe(&payload.Target)
 
 		if payload.CachedVerdict && payload.ResolutionVerdict != nil && payload.ResolutionVerdict.ValidResolution {
 			logging.Infof("[snapshot-worker] pair=%s using cached SAFE verdict", payload.PairID)
@@ -143,7 +235,7 @@ func consume(ctx context.Context, brokers []string, topic, group string, budget
 			logging.Infof("[snapshot-worker] pair=%s skipped (untradable: %s)", payload.PairID, result.Reason)
 			continue
 		}
-		if result.Best == nil || result.Best.ProfitUSD <= profitEpsilon || result.Best.Quantity <= profitEpsilon {
+		if result.Best == nil || result.Best.ProfitUSD <= 0 || result.Best.Quantity <= profitEpsilon {
 			logging.Infof("[snapshot-worker] pair=%s skipped (no profitable direction)", payload.PairID)
 			continue
 		}
@@ -163,24 +255,25 @@ func consume(ctx context.Context, brokers []string, topic, group string, budget
 
 		var verdict *matches.ResolutionVerdict
 		if bypassLLM {
-			verdict = matches.NewResolutionVerdict(true, "bypassed via SNAPSHOT_WORKER_BYPASS_LLM")
+			verdict = matches.NewResolutionVerdict(true, "bypassed via SNAPSHOT_WORKER_BYPASS_LLM", matches.OutcomeSame)
 		} else {
 			res, err := deps.validator.Validate(ctx, &payload)
 			if err != nil {
 				logging.Errorf("[snapshot-worker] validator error pair=%s: %v", payload.PairID, err)
 				continue
 			}
-			verdict = matches.NewResolutionVerdict(res.ValidResolution, res.ResolutionReason)
+			verdict = matches.NewResolutionVerdict(res.ValidResolution, res.ResolutionReason, matches.ParseOutcomeMapping(res.OutcomeMapping))
+			verdict.Confidence = res.Confidence
 		}
 
 		payload.ResolutionVerdict = verdict
 		logLLMResult(&payload)
 		appendValidationLog(&payload)
 		if deps.verdictCache != nil && verdictKey != "" {
-			if err := deps.verdictCache.Set(ctx, verdictKey, verdict.ValidResolution); err != nil {
+			if err := deps.verdictCache.Set(ctx, verdictKey, cache.Verdict{Valid: verdict.ValidResolution, Inverted: verdict.Inverted(), Confidence: verdict.Confidence}); err != nil {
 				logging.Errorf("[verdict-cache] set error key=%s: %v", verdictKey, err)
 			} else {
-				logging.Infof("[verdict-cache] stored key=%s valid=%t", verdictKey, verdict.ValidResolution)
+				logging.Infof("[verdict-cache] stored key=%s valid=%t mapping=%s", verdictKey, verdict.ValidResolution, verdict.OutcomeMapping)
 			}
 		}
 
@@ -198,8 +291,8 @@ func logLLMResult(payload *matches.Payload) {
 	}
 	pm := questionForVenue(payload, collectors.VenuePolymarket)
 	kx := questionForVenue(payload, collectors.VenueKalshi)
-	logging.Infof("[snapshot-worker] LLM pair=%s polymarket=\"%s\" kalshi=\"%s\" valid=%t reason=%s",
-		payload.PairID, pm, kx, payload.ResolutionVerdict.ValidResolution, payload.ResolutionVerdict.ResolutionReason)
+	logging.Infof("[snapshot-worker] LLM pair=%s polymarket=\"%s\" kalshi=\"%s\" valid=%t mapping=%s reason=%s",
+		payload.PairID, pm, kx, payload.ResolutionVerdict.ValidResolution, payload.ResolutionVerdict.OutcomeMapping, payload.ResolutionVerdict.ResolutionReason)
 }
 
 func questionForVenue(payload *matches.Payload, venue collectors.Venue) string {
@@ -275,28 +368,70 @@ func (d workerDeps) runFinalStage(parentCtx context.Context, payload *matches.Pa
 	ctx, cancel := context.WithTimeout(parentCtx, timeout)
 	defer cancel()
 
-	freshPM, err := d.pmClient.MarketSnapshot(ctx, pmSnap.Event.EventID, pmSnap.Market.MarketID)
-	if err != nil {
-		return fmt.Errorf("refresh polymarket: %w", err)
+	// Refresh both legs concurrently so their capture times are comparable.
+	var (
+		wg           sync.WaitGroup
+		freshPM      *models.MarketSnapshot
+		freshKX      *models.MarketSnapshot
+		pmErr, kxErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		freshPM, pmErr = d.pmClient.MarketSnapshot(ctx, pmSnap.Event.EventID, pmSnap.Market.MarketID)
+	}()
+	go func() {
+		defer wg.Done()
+		freshKX, kxErr = d.kxClient.MarketSnapshot(ctx, kxSnap.Event.EventID, kxSnap.Market.MarketID, "")
+	}()
+	wg.Wait()
+	if pmErr != nil {
+		return fmt.Errorf("refresh polymarket: %w", pmErr)
 	}
-	freshKX, err := d.kxClient.MarketSnapshot(ctx, kxSnap.Event.EventID, kxSnap.Market.MarketID, "")
-	if err != nil {
-		return fmt.Errorf("refresh kalshi: %w", err)
+	if kxErr != nil {
+		return fmt.Errorf("refresh kalshi: %w", kxErr)
 	}
 
 	payload.Fresh = &matches.FreshSnapshots{
 		Polymarket: freshPM,
 		Kalshi:     freshKX,
 	}
+	d.bookChanges.Observe(freshPM)
+	d.bookChanges.Observe(freshKX)
 
 	freshPayload := matches.Payload{
-		PairID:    payload.PairID,
-		Source:    *freshPM,
-		Target:    *freshKX,
-		MatchedAt: time.Now().UTC(),
+		PairID:            payload.PairID,
+		Similarity:        payload.Similarity,
+		Distance:          payload.Distance,
+		Source:            *freshPM,
+		Target:            *freshKX,
+		MatchedAt:         time.Now().UTC(),
+		ResolutionVerdict: payload.ResolutionVerdict,
+	}
+	result := arb.Evaluate(&freshPayload, arb.Config{
+		BudgetUSD:       d.finalBudget,
+		Budgets:         d.sweepBudgets,
+		Fees:            d.fees,
+		RankBy:          d.rankBy,
+		Scoring:         d.scoring,
+		Tradability:     d.tradability,
+		MaxSnapshotAge:  d.maxSnapshotAge,
+		MaxSnapshotSkew: d.maxSnapshotSkew,
+	})
+	if result.Best != nil {
+		// Keep the orders in the sequence the executor sends them, so the
+		// leg-risk check below prices the sequence that trades.
+		result.Best = execution.SendOrder(result.Best, d.firstVenue)
+		result.Opportunities[result.Best.Direction] = result.Best
 	}
-	result := arb.Evaluate(&freshPayload, arb.Config{BudgetUSD: d.finalBudget})
 	payload.FinalOpportunity = result.Best
+	payload.BudgetSweep = result.Sweep
+
+	if result.Stale {
+		fmt.Printf("[snapshot-worker] final pair=%s stale after refresh (%s)\n", payload.PairID, result.Reason)
+		appendFinalLog(payload)
+		return nil
+	}
 
 	if result.Best == nil {
 		fmt.Printf("[snapshot-worker] final pair=%s no profitable direction after refresh\n", payload.PairID)
@@ -304,6 +439,30 @@ func (d workerDeps) runFinalStage(parentCtx context.Context, payload *matches.Pa
 		return nil
 	}
 
+	if result.Best.Score < d.minScore {
+		fmt.Printf("[snapshot-worker] final pair=%s score=%.3f below ARB_MIN_SCORE=%.3f\n", payload.PairID, result.Best.Score, d.minScore)
+		appendFinalLog(payload)
+		return nil
+	}
+
+	// The edge has to survive the gap between sending the two legs, in the
+	// order they are sent.
+	legRiskCfg := d.legRisk
+	legRiskCfg.ChangeRates = d.bookChanges.Rates()
+	legRisk := arb.SimulateLegRisk(result.Best, payload.Fresh, legRiskCfg)
+	result.Best.LegRisk = legRisk
+	if legRisk != nil && (legRisk.ExpectedProfitUSD <= profitEpsilon || legRisk.LossProbability > d.maxLossProb) {
+		fmt.Printf("[snapshot-worker] final pair=%s fails leg risk expected=%.4f worst_loss=%.4f p_loss=%.3f\n",
+			payload.PairID, legRisk.ExpectedProfitUSD, legRisk.WorstCaseLossUSD, legRisk.LossProbability)
+		appendFinalLog(payload)
+		return nil
+	}
+
+	// The allocator only sizes what it saw in its last window, so every
+	// confirmed opportunity goes out, including repeats the dedup below
+	// keeps out of SQLite.
+	d.publishOpportunity(parentCtx, payload)
+
 	emitOpportunity := true
 	var prevRecord *cache.OpportunityRecord
 	if d.opportunityCache != nil {
@@ -315,11 +474,11 @@ func (d workerDeps) runFinalStage(parentCtx context.Context, payload *matches.Pa
 		prevRecord = prev
 	}
 	if !emitOpportunity {
-		prevProfit := 0.0
+		prevScore := 0.0
 		if prevRecord != nil {
-			prevProfit = prevRecord.ProfitUSD
+			prevScore = recordScore(d.rankBy, prevRecord)
 		}
-		logging.Infof("[snapshot-worker] pair=%s suppressed duplicate opportunity profit_new=%.4f profit_previous=%.4f", payload.PairID, result.Best.ProfitUSD, prevProfit)
+		logging.Infof("[snapshot-worker] pair=%s suppressed duplicate opportunity %s_new=%.4f %s_previous=%.4f", payload.PairID, d.rankBy, d.rankBy.Score(result.Best), d.rankBy, prevScore)
 		return nil
 	}
 
@@ -327,11 +486,97 @@ func (d workerDeps) runFinalStage(parentCtx context.Context, payload *matches.Pa
 		logging.Errorf("[snapshot-worker] sqlite insert error pair=%s: %v", payload.PairID, err)
 	}
 
-	fmt.Printf("[snapshot-worker] final pair=%s dir=%s qty=%.2f profit=%.4f\n", payload.PairID, result.Best.Direction, result.Best.Quantity, result.Best.ProfitUSD)
+	fmt.Printf("[snapshot-worker] final pair=%s dir=%s qty=%.2f profit=%.4f score=%.3f\n", payload.PairID, result.Best.Direction, result.Best.Quantity, result.Best.ProfitUSD, result.Best.Score)
 	appendFinalLog(payload)
+	d.execute(parentCtx, payload)
 	return nil
 }
 
+// execute trades an emitted opportunity against the fresh books, or
+// simulates it in dry-run mode, once the risk guard allows it.
+func (d workerDeps) execute(ctx context.Context, payload *matches.Payload) {
+	if d.executor == nil {
+		return
+	}
+	d.execMu.Lock()
+	defer d.execMu.Unlock()
+	block, err := d.checkRisk(ctx, payload)
+	if err != nil {
+		logging.Errorf("[executor] pair=%s risk check: %v", payload.PairID, err)
+		return
+	}
+	if block != nil {
+		fmt.Printf("[risk-block] source=%s pair=%s dir=%s reason=%s notional=%.2f detail=%q\n",
+			block.Source, block.PairID, block.Direction, block.Reason, block.NotionalUSD, block.Detail)
+		return
+	}
+	exec, err := d.executor.Execute(ctx, payload.PairID, payload.FinalOpportunity, payload.Fresh)
+	if err != nil {
+		logging.Errorf("[executor] pair=%s: %v", payload.PairID, err)
+	}
+	if exec == nil {
+		return
+	}
+	fmt.Printf("[execution] pair=%s id=%d status=%s dry_run=%t hedged=%.2f unhedged=%.2f loss=%.4f\n",
+		exec.PairID, exec.ID, exec.Status, exec.DryRun, exec.HedgedQuantity(), exec.UnhedgedQuantity(), exec.LossUSD)
+}
+
+// checkRisk runs the risk guard against the book of the executor's own mode
+// (live or dry-run executions) and records a refused trade.
+func (d workerDeps) checkRisk(ctx context.Context, payload *matches.Payload) (*risk.Block, error) {
+	snaps := make(map[string]*models.MarketSnapshot)
+	if payload.Fresh != nil {
+		for _, snap := range []*models.MarketSnapshot{payload.Fresh.Polymarket, payload.Fresh.Kalshi} {
+			if snap != nil {
+				snaps[paper.SnapshotKey(string(snap.Venue), snap.Market.MarketID)] = snap
+			}
+		}
+	}
+	trade, err := risk.NewTrade(payload.PairID, payload.FinalOpportunity, snaps)
+	if err != nil {
+		return nil, err
+	}
+	source, dryRun := ledger.SourceLive, d.executor.DryRun()
+	if dryRun {
+		source = ledger.SourceDryRun
+	}
+	now := time.Now().UTC()
+	dayStart := risk.DayStart(now)
+	execs, err := d.store.RiskExecutions(ctx, dryRun, now, dayStart)
+	if err != nil {
+		return nil, fmt.Errorf("load executions: %w", err)
+	}
+	settled, err := d.store.SettledLedgerPositions(ctx, source, dayStart)
+	if err != nil {
+		return nil, fmt.Errorf("load settled positions: %w", err)
+	}
+	block := d.guard.Check(ctx, string(source), risk.ExecutionBook(execs, settled, now, dayStart), trade)
+	if block == nil {
+		return nil, nil
+	}
+	return block, d.store.SaveRiskBlock(ctx, block)
+}
+
+// publishOpportunity forwards an emitted opportunity to the allocator.
+func (d workerDeps) publishOpportunity(ctx context.Context, payload *matches.Payload) {
+	if d.oppWriter == nil {
+		return
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		logging.Errorf("[snapshot-worker] marshal opportunity pair=%s: %v", payload.PairID, err)
+		return
+	}
+	msg := kafkago.Message{
+		Key:   []byte(payload.PairID),
+		Value: data,
+		Time:  time.Now().UTC(),
+	}
+	if err := d.oppWriter.WriteMessages(ctx, msg); err != nil {
+		logging.Errorf("[snapshot-worker] publish opportunity pair=%s: %v", payload.PairID, err)
+	}
+}
+
 func mustLLMClient() *llm.Client {
 	cfg := llm.Config{
 		APIKey:      os.Getenv("NEBIUS_API_KEY"),
@@ -389,6 +634,14 @@ func mustOpportunityCache() cache.OpportunityCache {
 	return cacheClient
 }
 
+func mustKillSwitch() cache.KillSwitch {
+	ks, err := cache.NewRedisKillSwitch(envString("REDIS_ADDR", "redis:6379"), os.Getenv("REDIS_PASSWORD"), envInt("REDIS_DB", 0), envString("RISK_KILL_SWITCH_KEY", cache.DefaultKillSwitchKey))
+	if err != nil {
+		logging.Fatalf("[snapshot-worker] redis kill switch: %v", err)
+	}
+	return ks
+}
+
 func mustPolymarketClient() *polymarket.Client {
 	cfg := polymarket.Config{
 		BaseURL: envString("POLYMARKET_API_URL", ""),
@@ -440,14 +693,16 @@ func (d workerDeps) shouldEmitOpportunity(ctx context.Context, pairID string, be
 	if err != nil {
 		return true, nil, err
 	}
-	if ok && record != nil && record.ProfitUSD >= best.ProfitUSD-profitEpsilon {
+	if ok && record != nil && recordScore(d.rankBy, record) >= d.rankBy.Score(best)-profitEpsilon {
 		return false, record, nil
 	}
 	newRecord := cache.OpportunityRecord{
-		ProfitUSD: best.ProfitUSD,
-		Direction: string(best.Direction),
-		Quantity:  best.Quantity,
-		UpdatedAt: time.Now().UTC(),
+		ProfitUSD:       best.ProfitUSD,
+		AnnualizedYield: best.AnnualizedYield,
+		Score:           best.Score,
+		Direction:       string(best.Direction),
+		Quantity:        best.Quantity,
+		UpdatedAt:       time.Now().UTC(),
 	}
 	if err := d.opportunityCache.Set(ctx, pairID, newRecord); err != nil {
 		return true, record, err
@@ -456,6 +711,18 @@ func (d workerDeps) shouldEmitOpportunity(ctx context.Context, pairID string, be
 	return true, record, nil
 }
 
+// recordScore reads the cached value of the metric used for dedup.
+func recordScore(metric matches.RankMetric, record *cache.OpportunityRecord) float64 {
+	switch metric {
+	case matches.RankByYield:
+		return record.AnnualizedYield
+	case matches.RankByScore:
+		return record.Score
+	default:
+		return record.ProfitUSD.Float()
+	}
+}
+
 func envInt(key string, def int) int {
 	if val := os.Getenv(key); val != "" {
 		if parsed, err := strconv.Atoi(val); err == nil {
@@ -481,6 +748,17 @@ func envString(key, def string) string {
 	return def
 }
 
+// envFloats parses a comma-separated list of floats, skipping bad entries.
+func envFloats(key string) []float64 {
+	var out []float64
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if parsed, err := strconv.ParseFloat(strings.TrimSpace(part), 64); err == nil {
+			out = append(out, parsed)
+		}
+	}
+	return out
+}
+
 func envBool(key string, def bool) bool {
 	if val := os.Getenv(key); val != "" {
 		if parsed, err := strconv.ParseBool(val); err == nil {
@@ -490,6 +768,127 @@ func envBool(key string, def bool) bool {
 	return def
 }
 
+func mustFeeSchedule() *arb.FeeSchedule {
+	sched, err := arb.LoadFeeSchedule(os.Getenv("ARB_FEE_SCHEDULE_PATH"))
+	if err != nil {
+		logging.Fatalf("[snapshot-worker] fee schedule: %v", err)
+	}
+	return &sched
+}
+
+func mustScoreWeights() *matches.ScoreWeights {
+	weights, err := matches.ParseScoreWeights(os.Getenv("ARB_SCORE_WEIGHTS"))
+	if err != nil {
+		logging.Fatalf("[snapshot-worker] score weights: %v", err)
+	}
+	return &weights
+}
+
+func mustTradabilityPolicy() *arb.TradabilityPolicy {
+	policy, err := arb.LoadTradabilityPolicy(os.Getenv("ARB_TRADABILITY_PATH"))
+	if err != nil {
+		logging.Fatalf("[snapshot-worker] tradability policy: %v", err)
+	}
+	return &policy
+}
+
+// mustExecutor builds the executor EXECUTOR_MODE selects: off (the
+// default), dry_run (fills simulated against the fresh books) or live.
+func mustExecutor(ctx context.Context, store *sqlstore.Store, fees *arb.FeeSchedule, firstVenue collectors.Venue) *execution.Executor {
+	cfg := execution.Config{
+		MaxLossUSD: money.FromFloat(envFloat("EXECUTOR_MAX_LOSS_USD", 5)),
+		FirstVenue: firstVenue,
+		Fees:       *fees,
+	}
+	switch mode := envString("EXECUTOR_MODE", "off"); mode {
+	case "off":
+		return nil
+	case "dry_run":
+		cfg.DryRun = true
+		logging.Infof("[executor] dry run (max_loss=%.2f first=%s)", cfg.MaxLossUSD, cfg.FirstVenue)
+		return execution.New(cfg, nil, store)
+	case "live":
+		venues := map[collectors.Venue]execution.Venue{
+			collectors.VenueKalshi:     execution.KalshiVenue{Client: mustKalshiTradingClient()},
+			collectors.VenuePolymarket: execution.PolymarketVenue{Client: mustPolymarketTradingClient(ctx)},
+		}
+		logging.Infof("[executor] LIVE trading (max_loss=%.2f first=%s)", cfg.MaxLossUSD, cfg.FirstVenue)
+		return execution.New(cfg, venues, store)
+	default:
+		logging.Fatalf("[snapshot-worker] unknown EXECUTOR_MODE %q", mode)
+		return nil
+	}
+}
+
+func mustKalshiTradingClient() *kalshi.TradingClient {
+	key, err := kalshi.LoadPrivateKey(os.Getenv("KALSHI_PRIVATE_KEY_PATH"))
+	if err != nil {
+		logging.Fatalf("[snapshot-worker] kalshi trading key: %v", err)
+	}
+	client, err := kalshi.NewTradingClient(kalshi.TradingConfig{
+		BaseURL:    envString("KALSHI_TRADE_API_URL", ""),
+		KeyID:      os.Getenv("KALSHI_KEY_ID"),
+		PrivateKey: key,
+		Timeout:    time.Duration(envInt("KALSHI_HTTP_TIMEOUT_SECONDS", 20)) * time.Second,
+	})
+	if err != nil {
+		logging.Fatalf("[snapshot-worker] kalshi trading client: %v", err)
+	}
+	return client
+}
+
+// mustPolymarketTradingClient uses the configured API credentials, or
+// creates / derives them from the wallet key when none are set.
+func mustPolymarketTradingClient(ctx context.Context) *polymarket.TradingClient {
+	var creds *polymarket.APICreds
+	if key := os.Getenv("POLYMARKET_API_KEY"); key != "" {
+		creds = &polymarket.APICreds{
+			APIKey:     key,
+			Secret:     os.Getenv("POLYMARKET_API_SECRET"),
+			Passphrase: os.Getenv("POLYMARKET_API_PASSPHRASE"),
+		}
+	}
+	client, err := polymarket.NewTradingClient(polymarket.TradingConfig{
+		BaseURL:       envString("POLYMARKET_CLOB_URL", ""),
+		PrivateKey:    os.Getenv("POLYMARKET_PRIVATE_KEY"),
+		Funder:        os.Getenv("POLYMARKET_FUNDER"),
+		SignatureType: polymarket.SignatureType(envInt("POLYMARKET_SIGNATURE_TYPE", 0)),
+		Creds:         creds,
+		Timeout:       time.Duration(envInt("POLYMARKET_HTTP_TIMEOUT_SECONDS", 20)) * time.Second,
+	})
+	if err != nil {
+		logging.Fatalf("[snapshot-worker] polymarket trading client: %v", err)
+	}
+	if creds == nil {
+		credCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		if _, err := client.CreateOrDeriveAPIKey(credCtx); err != nil {
+			logging.Fatalf("[snapshot-worker] polymarket api key: %v", err)
+		}
+	}
+	return client
+}
+
+// saveBookChanges persists the observed book-change totals every interval so
+// leg-risk rates survive restarts.
+func saveBookChanges(ctx context.Context, store *sqlstore.Store, tracker *arb.BookChangeTracker, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := store.SaveBookChangeHistory(ctx, tracker.History()); err != nil {
+				logging.Errorf("[snapshot-worker] save book change history: %v", err)
+			}
+		}
+	}
+}
+
 func mustSQLiteStore() *sqlstore.Store {
 	path := os.Getenv("SQLITE_PATH")
 	if path == "" {
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"github.com/hetulpatel/Arbitrage/internal/arb"
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/kafka"
+	"github.com/hetulpatel/Arbitrage/internal/kalshi"
+	"github.com/hetulpatel/Arbitrage/internal/ledger"
+	"github.com/hetulpatel/Arbitrage/internal/logging"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+	"github.com/hetulpatel/Arbitrage/internal/polymarket"
+	sqlstore "github.com/hetulpatel/Arbitrage/internal/storage/sqlite"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	logging.InitFromEnv()
+
+	interval := time.Duration(envInt("UNWIND_SCANNER_INTERVAL_SECONDS", 30)) * time.Second
+
+	fees, err := arb.LoadFeeSchedule(os.Getenv("ARB_FEE_SCHEDULE_PATH"))
+	if err != nil {
+		logging.Fatalf("[unwind-scanner] fee schedule: %v", err)
+	}
+	cfg := arb.UnwindConfig{
+		DiscountRate:    envFloat("UNWIND_DISCOUNT_RATE", 0.05),
+		MinEdgeUSD:      envFloat("UNWIND_MIN_EDGE_USD", 0.01),
+		Fees:            &fees,
+		MaxSnapshotAge:  time.Duration(envInt("ARB_MAX_SNAPSHOT_AGE_SECONDS", 60)) * time.Second,
+		MaxSnapshotSkew: time.Duration(envInt("ARB_MAX_SNAPSHOT_SKEW_SECONDS", 10)) * time.Second,
+	}
+
+	brokers := kafka.Brokers()
+	topic := kafka.TopicFromEnv("EXITS_KAFKA_TOPIC", kafka.DefaultExitTopic)
+	waitCtx, cancel := context.WithTimeout(ctx, 45*time.Second)
+	if err := kafka.WaitForBroker(waitCtx, brokers); err != nil {
+		logging.Fatalf("[unwind-scanner] wait for broker: %v", err)
+	}
+	cancel()
+	ensureCtx, cancelEnsure := context.WithTimeout(ctx, 30*time.Second)
+	if err := kafka.EnsureTopic(ensureCtx, brokers, topic); err != nil {
+		logging.Errorf("[unwind-scanner] ensure topic warning: %v", err)
+	}
+	cancelEnsure()
+	writer := kafka.NewWriter(brokers, topic)
+	defer writer.Close()
+
+	store, err := sqlstore.Open(os.Getenv("SQLITE_PATH"))
+	if err != nil {
+		logging.Fatalf("[unwind-scanner] open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	s := &scanner{
+		store:  store,
+		source: ledger.Source(os.Getenv("UNWIND_SCANNER_SOURCE")),
+		path:   os.Getenv("POSITIONS_PATH"),
+		pmClient: polymarket.NewClient(polymarket.Config{
+			BaseURL: envString("POLYMARKET_API_URL", ""),
+			BookURL: envString("POLYMARKET_BOOK_URL", ""),
+			Timeout: time.Duration(envInt("POLYMARKET_HTTP_TIMEOUT_SECONDS", 20)) * time.Second,
+		}),
+		kxClient: kalshi.NewClient(kalshi.Config{
+			BaseURL:   envString("KALSHI_API_URL", ""),
+			SeriesURL: envString("KALSHI_SERIES_URL", ""),
+			BookURL:   envString("KALSHI_MARKET_URL", ""),
+			Timeout:   time.Duration(envInt("KALSHI_HTTP_TIMEOUT_SECONDS", 20)) * time.Second,
+		}),
+		cfg:    cfg,
+		writer: writer,
+		sent:   make(map[string]string),
+	}
+
+	logging.Infof("[unwind-scanner] watching open ledger positions (source=%q, overrides=%q) every %s publishing to %s (discount=%.3f)", s.source, s.path, interval, topic, cfg.DiscountRate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// Reloaded every pass so new fills and closed positions are picked up.
+		positions := s.load(ctx)
+		live := make(map[string]bool, len(positions))
+		for _, h := range positions {
+			live[h.key] = true
+			if err := s.scan(ctx, h); err != nil {
+				logging.Errorf("[unwind-scanner] pair=%s: %v", h.pos.PairID, err)
+			}
+		}
+		for key := range s.sent {
+			if !live[key] {
+				delete(s.sent, key)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+type scanner struct {
+	store    *sqlstore.Store
+	source   ledger.Source
+	path     string
+	pmClient *polymarket.Client
+	kxClient *kalshi.Client
+	cfg      arb.UnwindConfig
+	writer   *kafkago.Writer
+	// sent holds the last signal published per position, so an unchanged
+	// exit is not republished every interval.
+	sent map[string]string
+}
+
+// heldPosition is a position to watch and the key its signals are
+// deduplicated under.
+type heldPosition struct {
+	key string
+	pos matches.Position
+}
+
+// load returns the open ledger positions, limited to the configured source
+// when one is set. Positions in the POSITIONS_PATH file replace the ledger
+// entries of the same pair and add any pair the ledger does not hold.
+func (s *scanner) load(ctx context.Context) []heldPosition {
+	var overrides []matches.Position
+	if s.path != "" {
+		loaded, err := matches.LoadPositions(s.path)
+		if err != nil {
+			logging.Errorf("[unwind-scanner] %v", err)
+		}
+		overrides = loaded
+	}
+	replaced := make(map[string]bool, len(overrides))
+	for _, pos := range overrides {
+		replaced[pos.PairID] = true
+	}
+
+	open, err := s.store.LedgerPositions(ctx, ledger.StatusOpen)
+	if err != nil {
+		logging.Errorf("[unwind-scanner] ledger positions: %v", err)
+	}
+	var out []heldPosition
+	for i := range open {
+		entry := &open[i]
+		if (s.source != "" && entry.Source != s.source) || replaced[entry.PairID] {
+			continue
+		}
+		if pos, ok := entry.Holding(); ok {
+			out = append(out, heldPosition{key: fmt.Sprintf("%s:%d", entry.Source, entry.ID), pos: pos})
+		}
+	}
+	for _, pos := range overrides {
+		out = append(out, heldPosition{key: "file:" + pos.PairID, pos: pos})
+	}
+	return out
+}
+
+// scan refreshes every leg of a position concurrently and publishes an exit
+// signal when selling beats holding, unless the same quantity and limit
+// prices were already published for the position.
+func (s *scanner) scan(ctx context.Context, h heldPosition) error {
+	pos := h.pos
+	snaps := make([]*models.MarketSnapshot, len(pos.Legs))
+	errs := make([]error, len(pos.Legs))
+	var wg sync.WaitGroup
+	for i, leg := range pos.Legs {
+		wg.Add(1)
+		go func(i int, ref matches.MarketRef) {
+			defer wg.Done()
+			snaps[i], errs[i] = s.fetch(ctx, ref)
+		}(i, leg.MarketRef)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("refresh %s: %w", pos.Legs[i].MarketID, err)
+		}
+	}
+
+	sig, reason := arb.EvaluateUnwind(pos, snaps, s.cfg, time.Now().UTC())
+	if sig == nil {
+		delete(s.sent, h.key)
+		logging.Debugf("[unwind-scanner] pair=%s hold (%s)", pos.PairID, reason)
+		return nil
+	}
+	fingerprint := signalFingerprint(sig)
+	if s.sent[h.key] == fingerprint {
+		logging.Debugf("[unwind-scanner] pair=%s exit unchanged since last signal", pos.PairID)
+		return nil
+	}
+	fmt.Printf("[exit-signal] pair=%s qty=%.2f proceeds=%.4f hold_value=%.4f edge=%.4f realized=%.4f\n",
+		sig.PairID, sig.Quantity, sig.ProceedsUSD, sig.HoldValueUSD, sig.EdgeUSD, sig.RealizedProfitUSD)
+	data, err := json.Marshal(sig)
+	if err != nil {
+		return fmt.Errorf("marshal exit signal: %w", err)
+	}
+	msg := kafkago.Message{
+		Key:   []byte(sig.PairID),
+		Value: data,
+		Time:  sig.GeneratedAt,
+	}
+	if err := s.writer.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("publish exit signal: %w", err)
+	}
+	s.sent[h.key] = fingerprint
+	return nil
+}
+
+// signalFingerprint identifies an exit by its size and the limit price of
+// each sell order.
+func signalFingerprint(sig *matches.ExitSignal) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%.6f", sig.Quantity)
+	for _, o := range sig.Orders {
+		fmt.Fprintf(&b, "|%s:%s:%d:%.6f", o.Venue, o.MarketID, int64(o.LimitPrice), o.Quantity)
+	}
+	return b.String()
+}
+
+func (s *scanner) fetch(ctx context.Context, ref matches.MarketRef) (*models.MarketSnapshot, error) {
+	switch ref.Venue {
+	case collectors.VenuePolymarket:
+		return s.pmClient.MarketSnapshot(ctx, ref.EventID, ref.MarketID)
+	case collectors.VenueKalshi:
+		return s.kxClient.MarketSnapshot(ctx, ref.EventID, ref.MarketID, "")
+	default:
+		return nil, fmt.Errorf("unknown venue %q", ref.Venue)
+	}
+}
+
+func envInt(key string, def int) int {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.Atoi(val); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
+func envFloat(key string, def float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.ParseFloat(val, 64); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
+func envString(key, def string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return def
+}
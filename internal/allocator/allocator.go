@@ -0,0 +1,407 @@
+// Package allocator splits shared venue balances across concurrent arbitrage
+// opportunities instead of sizing each one against the full budget.
+package allocator
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/arb"
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+const epsilon = 1e-9
+
+// Config bounds an allocation run.
+type Config struct {
+	// Balances is the spendable capital per venue (USD on Kalshi, USDC on
+	// Polymarket). A venue without an entry has no capital.
+	Balances map[collectors.Venue]float64
+	// MaxEventExposureUSD caps the capital committed to any single event
+	// across all allocations. Zero disables the cap.
+	MaxEventExposureUSD float64
+	// MinEdge skips ladder slices whose marginal profit per dollar is below
+	// it (e.g. 0.01 for 1%).
+	MinEdge float64
+	// Lots overrides per-venue lot sizes; missing venues use
+	// arb.DefaultLotRules.
+	Lots map[collectors.Venue]arb.LotRule
+	// Fees prices the orders of partial allocations; nil uses
+	// arb.DefaultFeeSchedule.
+	Fees *arb.FeeSchedule
+}
+
+// Candidate is one live opportunity competing for capital.
+type Candidate struct {
+	PairID string
+	// Events identifies the events the legs settle on ("venue:event_id").
+	// The candidate's full cost counts against each event's exposure cap.
+	Events      []string
+	Opportunity *matches.Opportunity
+	ObservedAt  time.Time
+	// Snapshots are the markets the orders trade; they select each order's
+	// fee model when the allocation is resized.
+	Snapshots []models.MarketSnapshot
+}
+
+// Allocation is the capital assigned to one candidate.
+type Allocation struct {
+	PairID    string            `json:"pair_id"`
+	Direction matches.Direction `json:"direction"`
+	Quantity  float64           `json:"quantity"`
+	CostUSD   float64           `json:"cost_usd"`
+	ProfitUSD float64           `json:"profit_usd"`
+	Edge      float64           `json:"edge"`
+	Events    []string          `json:"events,omitempty"`
+	// Orders is the candidate's order plan resized to Quantity. Fees are
+	// recomputed and rounded per order when the allocation is smaller than
+	// the opportunity.
+	Orders []matches.Order `json:"orders,omitempty"`
+}
+
+// Plan is the output of one allocation run.
+type Plan struct {
+	CreatedAt     time.Time                    `json:"created_at"`
+	Candidates    int                          `json:"candidates"`
+	Allocations   []Allocation                 `json:"allocations"`
+	TotalCostUSD  float64                      `json:"total_cost_usd"`
+	ProfitUSD     float64                      `json:"profit_usd"`
+	VenueUsageUSD map[collectors.Venue]float64 `json:"venue_usage_usd"`
+}
+
+// slice is one step of a candidate's profit curve.
+type slice struct {
+	qty, cost, profit float64
+}
+
+func (s slice) edge() float64 {
+	if s.cost <= epsilon {
+		return 0
+	}
+	return s.profit / s.cost
+}
+
+type state struct {
+	cand   Candidate
+	slices []slice
+	next   int
+	// shares is each venue's fraction of the candidate's cost.
+	shares map[collectors.Venue]float64
+	qty    float64
+	done   bool
+}
+
+// Allocate assigns capital greedily to the ladder slices with the highest
+// marginal edge. Profit curves are concave (deeper levels cost more), so
+// this maximises total profit when a single balance binds; with several
+// binding limits it is a close, deterministic approximation.
+func Allocate(cands []Candidate, cfg Config, now time.Time) Plan {
+	plan := Plan{
+		CreatedAt:     now,
+		Candidates:    len(cands),
+		VenueUsageUSD: make(map[collectors.Venue]float64),
+	}
+	remaining := make(map[collectors.Venue]float64, len(cfg.Balances))
+	for v, b := range cfg.Balances {
+		remaining[v] = b
+	}
+	exposure := make(map[string]float64)
+
+	states := make([]*state, 0, len(cands))
+	for _, c := range cands {
+		if st := newState(c); st != nil {
+			states = append(states, st)
+		}
+	}
+	// Stable order so ties resolve the same way on every run.
+	sort.SliceStable(states, func(i, j int) bool {
+		return states[i].cand.PairID < states[j].cand.PairID
+	})
+
+	for {
+		var pick *state
+		for _, st := range states {
+			if st.done || st.next >= len(st.slices) {
+				continue
+			}
+			if pick == nil || st.slices[st.next].edge() > pick.slices[pick.next].edge()+epsilon {
+				pick = st
+			}
+		}
+		if pick == nil {
+			break
+		}
+		s := pick.slices[pick.next]
+		if s.profit <= 0 || s.edge() < cfg.MinEdge {
+			pick.done = true
+			continue
+		}
+
+		frac := 1.0
+		for v, share := range pick.shares {
+			if need := s.cost * share; need > epsilon {
+				frac = math.Min(frac, remaining[v]/need)
+			}
+		}
+		if cfg.MaxEventExposureUSD > 0 {
+			for _, ev := range pick.cand.Events {
+				frac = math.Min(frac, (cfg.MaxEventExposureUSD-exposure[ev])/s.cost)
+			}
+		}
+		if frac <= epsilon {
+			pick.done = true
+			continue
+		}
+
+		spent := s.cost * frac
+		for v, share := range pick.shares {
+			remaining[v] -= spent * share
+		}
+		for _, ev := range pick.cand.Events {
+			exposure[ev] += spent
+		}
+		pick.qty += s.qty * frac
+		pick.next++
+		if frac < 1 {
+			pick.done = true
+		}
+	}
+
+	// Resolve allocations against the limits again: lot flooring and
+	// repricing happen here, so each one is fitted to what is still left.
+	for v, b := range cfg.Balances {
+		remaining[v] = b
+	}
+	exposure = make(map[string]float64)
+	for _, st := range states {
+		alloc, ok := st.allocation(cfg, st.maxCost(cfg, remaining, exposure))
+		if !ok {
+			continue
+		}
+		plan.Allocations = append(plan.Allocations, alloc)
+		plan.TotalCostUSD += alloc.CostUSD
+		plan.ProfitUSD += alloc.ProfitUSD
+		for v, share := range st.shares {
+			plan.VenueUsageUSD[v] += alloc.CostUSD * share
+			remaining[v] -= alloc.CostUSD * share
+		}
+		for _, ev := range st.cand.Events {
+			exposure[ev] += alloc.CostUSD
+		}
+	}
+	sort.SliceStable(plan.Allocations, func(i, j int) bool {
+		return plan.Allocations[i].ProfitUSD > plan.Allocations[j].ProfitUSD
+	})
+	return plan
+}
+
+func newState(c Candidate) *state {
+	op := c.Opportunity
+	if op == nil || op.Quantity <= epsilon || op.TotalCostUSD <= 0 || op.ProfitUSD <= 0 {
+		return nil
+	}
+	shares := venueShares(op)
+	if len(shares) == 0 {
+		return nil
+	}
+	return &state{cand: c, slices: curveSlices(op), shares: shares}
+}
+
+// curveSlices converts the cumulative curve into per-slice deltas, capped at
+// the executable quantity. Opportunities without a curve become one slice.
+func curveSlices(op *matches.Opportunity) []slice {
+	var out []slice
+	var prev matches.CurvePoint
+	for _, p := range op.Curve {
+		if prev.Quantity >= op.Quantity-epsilon {
+			break
+		}
+		s := slice{
+			qty:    p.Quantity - prev.Quantity,
+			cost:   p.TotalCostUSD - prev.TotalCostUSD,
+			profit: p.ProfitUSD - prev.ProfitUSD,
+		}
+		if p.Quantity > op.Quantity+epsilon {
+			frac := (op.Quantity - prev.Quantity) / s.qty
+			s = slice{qty: s.qty * frac, cost: s.cost * frac, profit: s.profit * frac}
+		}
+		if s.qty > epsilon {
+			out = append(out, s)
+		}
+		prev = p
+	}
+	if len(out) == 0 {
+		out = append(out, slice{qty: op.Quantity, cost: op.TotalCostUSD.Float(), profit: op.ProfitUSD.Float()})
+	}
+	return out
+}
+
+// venueShares splits the opportunity's cost (legs plus fees) by venue.
+func venueShares(op *matches.Opportunity) map[collectors.Venue]float64 {
+	spend := make(map[collectors.Venue]float64)
+	for _, leg := range op.Legs {
+		spend[collectors.Venue(leg.Venue)] += leg.CostUSD.Float()
+	}
+	spend[collectors.VenueKalshi] += op.KalshiFeesUSD.Float()
+	spend[collectors.VenuePolymarket] += op.PolymarketFeesUSD.Float()
+	total := 0.0
+	for _, v := range spend {
+		total += v
+	}
+	out := make(map[collectors.Venue]float64)
+	if total <= epsilon {
+		return out
+	}
+	for v, amt := range spend {
+		if amt > epsilon {
+			out[v] = amt / total
+		}
+	}
+	return out
+}
+
+// allocation resolves the allocated quantity into an Allocation costing at
+// most maxCost. A full allocation reuses the opportunity as-is. A partial
+// one is cut to what maxCost buys on the profit curve, floored to the lot
+// size every order's venue accepts and dropped below the venues' minimum
+// order size. It costs what the curve prefix up to that quantity costs, the
+// same figure the slices were funded at; the orders are resized with the
+// venue fee recomputed and rounded at the new size.
+func (st *state) allocation(cfg Config, maxCost float64) (Allocation, bool) {
+	op := st.cand.Opportunity
+	alloc := Allocation{
+		PairID:    st.cand.PairID,
+		Direction: op.Direction,
+		Events:    st.cand.Events,
+	}
+	if st.qty >= op.Quantity-epsilon && op.TotalCostUSD.Float() <= maxCost+epsilon {
+		alloc.Quantity = op.Quantity
+		alloc.CostUSD = op.TotalCostUSD.Float()
+		alloc.ProfitUSD = op.ProfitUSD.Float()
+		alloc.Orders = append([]matches.Order(nil), op.Orders...)
+	} else {
+		rule := st.lotRule(cfg)
+		qty := rule.Floor(math.Min(st.qty, st.qtyFor(maxCost)))
+		if qty <= epsilon || qty+epsilon < rule.MinQty {
+			return Allocation{}, false
+		}
+		// What one unit pays is the same at every size: $1, or the floor
+		// of strategies that can pay more.
+		payout := (op.TotalCostUSD + op.ProfitUSD).Float() / op.Quantity
+		alloc.Orders = st.resize(qty, cfg)
+		alloc.Quantity = qty
+		alloc.CostUSD = st.costOf(qty)
+		alloc.ProfitUSD = payout*qty - alloc.CostUSD
+	}
+	if alloc.ProfitUSD <= epsilon || alloc.CostUSD <= epsilon {
+		return Allocation{}, false
+	}
+	alloc.Edge = alloc.ProfitUSD / alloc.CostUSD
+	return alloc, true
+}
+
+// lotRule is the order size rule every venue of the candidate accepts.
+func (st *state) lotRule(cfg Config) arb.LotRule {
+	defaults := arb.DefaultLotRules()
+	var rules []arb.LotRule
+	for _, o := range st.cand.Opportunity.Orders {
+		venue := collectors.Venue(o.Venue)
+		rule, ok := cfg.Lots[venue]
+		if !ok {
+			rule = defaults[venue]
+		}
+		rules = append(rules, rule)
+	}
+	return arb.CombineLots(rules...)
+}
+
+// maxCost is the most the candidate may still cost: the smallest of what
+// each of its venues has left for its share and what each of its events
+// has left under the exposure cap.
+func (st *state) maxCost(cfg Config, remaining map[collectors.Venue]float64, exposure map[string]float64) float64 {
+	limit := math.Inf(1)
+	for v, share := range st.shares {
+		if share > epsilon {
+			limit = math.Min(limit, remaining[v]/share)
+		}
+	}
+	if cfg.MaxEventExposureUSD > 0 {
+		for _, ev := range st.cand.Events {
+			limit = math.Min(limit, cfg.MaxEventExposureUSD-exposure[ev])
+		}
+	}
+	return math.Max(limit, 0)
+}
+
+// costOf is the cost of the curve prefix up to qty, interpolated inside the
+// last slice as the allocation loop funds it.
+func (st *state) costOf(qty float64) float64 {
+	var cost, filled float64
+	for _, s := range st.slices {
+		if qty-filled <= epsilon {
+			break
+		}
+		take := math.Min(s.qty, qty-filled)
+		cost += s.cost * take / s.qty
+		filled += take
+	}
+	return cost
+}
+
+// qtyFor is the largest quantity whose curve prefix costs at most budget.
+func (st *state) qtyFor(budget float64) float64 {
+	var cost, qty float64
+	for _, s := range st.slices {
+		if cost+s.cost > budget+epsilon {
+			if s.cost > epsilon {
+				qty += s.qty * math.Max(budget-cost, 0) / s.cost
+			}
+			break
+		}
+		cost += s.cost
+		qty += s.qty
+	}
+	return qty
+}
+
+// resize scales every order to qty, recomputing each venue fee at the leg's
+// average fill price.
+func (st *state) resize(qty float64, cfg Config) []matches.Order {
+	op := st.cand.Opportunity
+	fees := arb.DefaultFeeSchedule()
+	if cfg.Fees != nil {
+		fees = *cfg.Fees
+	}
+	orders := make([]matches.Order, len(op.Orders))
+	for i, o := range op.Orders {
+		price := o.LimitPrice.Float()
+		// Legs and orders are built in step; the leg holds the average fill.
+		if len(op.Legs) == len(op.Orders) && op.Legs[i].Quantity > epsilon {
+			price = op.Legs[i].AvgPrice
+		}
+		model := st.feeModel(fees, o)
+		o.Quantity = qty
+		o.FeeUSD = money.FromFloat(model.Round(model.TakerFee(qty, price)))
+		orders[i] = o
+	}
+	return orders
+}
+
+// feeModel finds the fee model of the market an order trades, falling back
+// to the venue default when the candidate carries no snapshot of it.
+func (st *state) feeModel(fees arb.FeeSchedule, o matches.Order) arb.FeeModel {
+	for i := range st.cand.Snapshots {
+		snap := &st.cand.Snapshots[i]
+		if string(snap.Venue) == o.Venue && snap.Market.MarketID == o.MarketID {
+			return fees.ModelFor(snap)
+		}
+	}
+	return fees.ModelFor(&models.MarketSnapshot{
+		Venue:  collectors.Venue(o.Venue),
+		Market: collectors.Market{MarketID: o.MarketID},
+	})
+}
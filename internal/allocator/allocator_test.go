@@ -0,0 +1,89 @@
+package allocator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+// steepOpportunity buys 100 sets for $90 in total, but the first 20 cost
+// only $0.80 each: a partial allocation is much cheaper per set than the
+// full opportunity's average.
+func steepOpportunity() *matches.Opportunity {
+	leg := func(venue collectors.Venue, market string) (matches.Leg, matches.Order) {
+		return matches.Leg{
+			Venue: string(venue), MarketID: market, Side: "buy", Outcome: "yes",
+			Quantity: 100, CostUSD: money.FromFloat(45), AvgPrice: 0.45,
+		}, matches.Order{
+			Venue: string(venue), MarketID: market, Side: "buy", Outcome: "yes",
+			Type: "limit", LimitPrice: money.FromFloat(0.50), Quantity: 100,
+		}
+	}
+	kxLeg, kxOrder := leg(collectors.VenueKalshi, "KX-1")
+	pmLeg, pmOrder := leg(collectors.VenuePolymarket, "pm-1")
+	return &matches.Opportunity{
+		Quantity:     100,
+		TotalCostUSD: money.FromFloat(90),
+		ProfitUSD:    money.FromFloat(10),
+		Legs:         []matches.Leg{kxLeg, pmLeg},
+		Orders:       []matches.Order{kxOrder, pmOrder},
+		Curve: []matches.CurvePoint{
+			{Quantity: 20, TotalCostUSD: 16, ProfitUSD: 4},
+			{Quantity: 100, TotalCostUSD: 90, ProfitUSD: 10},
+		},
+	}
+}
+
+func TestPartialAllocationStaysWithinBalances(t *testing.T) {
+	cfg := Config{Balances: map[collectors.Venue]float64{
+		collectors.VenueKalshi:     5,
+		collectors.VenuePolymarket: 5,
+	}}
+	plan := Allocate([]Candidate{{PairID: "pair", Opportunity: steepOpportunity()}}, cfg, time.Now())
+	if len(plan.Allocations) != 1 {
+		t.Fatalf("allocations = %d, want 1", len(plan.Allocations))
+	}
+	alloc := plan.Allocations[0]
+	// $10 buys 12.5 sets at $0.80; Kalshi lots floor that to 12.
+	if alloc.Quantity != 12 {
+		t.Errorf("quantity = %g, want 12", alloc.Quantity)
+	}
+	if alloc.CostUSD > 9.6+epsilon {
+		t.Errorf("cost = %.4f, want at most 9.60", alloc.CostUSD)
+	}
+	for venue, used := range plan.VenueUsageUSD {
+		if used > cfg.Balances[venue]+epsilon {
+			t.Errorf("%s usage = %.4f, above its balance %.2f", venue, used, cfg.Balances[venue])
+		}
+	}
+	for _, o := range alloc.Orders {
+		if o.Quantity != alloc.Quantity {
+			t.Errorf("%s order quantity = %g, want %g", o.Venue, o.Quantity, alloc.Quantity)
+		}
+	}
+}
+
+func TestPartialAllocationStaysWithinEventCap(t *testing.T) {
+	cfg := Config{
+		Balances: map[collectors.Venue]float64{
+			collectors.VenueKalshi:     100,
+			collectors.VenuePolymarket: 100,
+		},
+		MaxEventExposureUSD: 30,
+	}
+	cands := []Candidate{
+		{PairID: "a", Events: []string{"kalshi:E1"}, Opportunity: steepOpportunity()},
+		{PairID: "b", Events: []string{"kalshi:E1"}, Opportunity: steepOpportunity()},
+	}
+	plan := Allocate(cands, cfg, time.Now())
+	total := 0.0
+	for _, alloc := range plan.Allocations {
+		total += alloc.CostUSD
+	}
+	if total > cfg.MaxEventExposureUSD+epsilon {
+		t.Errorf("event exposure = %.4f, above the %.2f cap", total, cfg.MaxEventExposureUSD)
+	}
+}
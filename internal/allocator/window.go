@@ -0,0 +1,78 @@
+package allocator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+)
+
+// Window keeps the latest opportunity per pair seen within a time span.
+// It is safe for concurrent use.
+type Window struct {
+	mu     sync.Mutex
+	span   time.Duration
+	byPair map[string]Candidate
+}
+
+// NewWindow returns a window that forgets candidates older than span.
+func NewWindow(span time.Duration) *Window {
+	return &Window{span: span, byPair: make(map[string]Candidate)}
+}
+
+// Add records a candidate, replacing any older one for the same pair.
+func (w *Window) Add(c Candidate) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if prev, ok := w.byPair[c.PairID]; ok && prev.ObservedAt.After(c.ObservedAt) {
+		return
+	}
+	w.byPair[c.PairID] = c
+}
+
+// Live prunes expired candidates and returns the rest.
+func (w *Window) Live(now time.Time) []Candidate {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	out := make([]Candidate, 0, len(w.byPair))
+	for id, c := range w.byPair {
+		if w.span > 0 && now.Sub(c.ObservedAt) > w.span {
+			delete(w.byPair, id)
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// CandidateFromPayload builds a candidate from a final-stage match payload,
+// preferring the refreshed opportunity and snapshots when present.
+func CandidateFromPayload(p *matches.Payload, observedAt time.Time) (Candidate, bool) {
+	if p == nil {
+		return Candidate{}, false
+	}
+	op := p.FinalOpportunity
+	if op == nil {
+		op = p.Arbitrage
+	}
+	if op == nil {
+		return Candidate{}, false
+	}
+	source, target := p.Source, p.Target
+	if p.Fresh != nil && p.Fresh.Polymarket != nil && p.Fresh.Kalshi != nil {
+		source, target = *p.Fresh.Polymarket, *p.Fresh.Kalshi
+	}
+	events := []string{fmt.Sprintf("%s:%s", source.Venue, source.Event.EventID)}
+	if key := fmt.Sprintf("%s:%s", target.Venue, target.Event.EventID); key != events[0] {
+		events = append(events, key)
+	}
+	return Candidate{
+		PairID:      p.PairID,
+		Events:      events,
+		Opportunity: op,
+		ObservedAt:  observedAt,
+		Snapshots:   []models.MarketSnapshot{source, target},
+	}, true
+}
@@ -0,0 +1,87 @@
+package arb
+
+import (
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+)
+
+// EvaluateBox looks for a single-venue box: buying YES and NO on the same
+// market pays exactly $1, so a combined ask below $1 after fees is an
+// arbitrage that needs no cross-venue match or resolution check.
+func EvaluateBox(snap *models.MarketSnapshot, cfg Config) Result {
+	if cfg.BudgetUSD <= 0 {
+		cfg.BudgetUSD = 100
+	}
+	res := Result{Opportunities: make(map[matches.Direction]*matches.Opportunity)}
+	if snap == nil {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectMissingData, Detail: "missing snapshot"}
+		return res
+	}
+
+	if reason, stale := checkFreshness(cfg, time.Now().UTC(), snap); stale {
+		res.Stale = true
+		res.Reason = reason
+		return res
+	}
+
+	var yesBook, noBook collectors.Orderbook
+	var dir matches.Direction
+	switch snap.Venue {
+	case collectors.VenuePolymarket:
+		yesBook = getPMOrderbook(&snap.Market, true)
+		noBook = getPMOrderbook(&snap.Market, false)
+		dir = matches.DirectionBoxPolymarket
+	case collectors.VenueKalshi:
+		yesBook = snap.Market.Orderbooks["yes"]
+		noBook = snap.Market.Orderbooks["no"]
+		dir = matches.DirectionBoxKalshi
+	default:
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectUnknownVenue, Venue: snap.Venue}
+		return res
+	}
+	if len(yesBook.Asks) == 0 || len(noBook.Asks) == 0 {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectNoAsk, Venue: snap.Venue}
+		return res
+	}
+
+	op := simulateBox(cfg, dir, snap, yesBook, noBook)
+	res.Sweep = sweepBudgets(cfg, func(c Config) *matches.Opportunity {
+		return simulateBox(c, dir, snap, yesBook, noBook)
+	})
+	if op == nil {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectNoProfit, Venue: snap.Venue}
+		return res
+	}
+	res.Opportunities[op.Direction] = op
+	res.Best = op
+	return res
+}
+
+func simulateBox(cfg Config, dir matches.Direction, snap *models.MarketSnapshot, yesBook, noBook collectors.Orderbook) *matches.Opportunity {
+	feeModel := cfg.feeSchedule().ModelFor(snap)
+	ladders := [][]collectors.OrderbookLevel{yesBook.Asks, noBook.Asks}
+	feeModels := []FeeModel{feeModel, feeModel}
+
+	w := walkLadders(cfg.BudgetUSD, 0, ladders, feeModels)
+	if w.best.qty <= epsilon {
+		return nil
+	}
+	exec, ok := executableFill(w.best, cfg.BudgetUSD, ladders, feeModels, cfg.lotRule(snap.Venue))
+	if !ok {
+		return nil
+	}
+
+	op := newOpportunity(dir, cfg.BudgetUSD, w, exec)
+	addLeg(op, snap.Venue, snap.Market.MarketID, "yes", exec.legs[0], snap.Market.TickSize)
+	addLeg(op, snap.Venue, snap.Market.MarketID, "no", exec.legs[1], snap.Market.TickSize)
+	applyYield(op, time.Now().UTC(), closeTime(snap))
+	applyScore(cfg, op, ladders, snap)
+	return op
+}
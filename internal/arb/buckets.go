@@ -0,0 +1,142 @@
+package arb
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+)
+
+// bucket is the interval a range market resolves YES on: [lo, hi) unless
+// loOpen excludes lo or hiClosed includes hi. Unbounded sides are ±Inf and
+// never open or closed.
+type bucket struct {
+	lo, hi           float64
+	loOpen, hiClosed bool
+	market           *collectors.Market
+}
+
+func (b bucket) interval() matches.Interval {
+	out := matches.NewInterval(b.lo, b.hi)
+	out.LoOpen = b.loOpen
+	out.HiClosed = b.hiClosed
+	return out
+}
+
+const bucketNum = `(-?\d+(?:\.\d+)?)([kmb]?)\b`
+
+var (
+	bucketBetweenRe = regexp.MustCompile(`between\s+\$?` + bucketNum + `\D*?\s+and\s+\$?` + bucketNum)
+	bucketRangeRe   = regexp.MustCompile(`\$?` + bucketNum + `[^\d\s]*\s*(?:-|–|to)\s*\$?` + bucketNum)
+	bucketAtLeastRe = regexp.MustCompile(`(?:at least|no less than)\s+\$?` + bucketNum + `|\$?` + bucketNum + `[^\d\s]*\s*(?:or (?:above|more|higher|greater)|\+)`)
+	bucketAboveRe   = regexp.MustCompile(`(?:above|over|greater than|more than|higher than|exceeds?)\s+\$?` + bucketNum)
+	bucketAtMostRe  = regexp.MustCompile(`(?:at most|no more than)\s+\$?` + bucketNum + `|\$?` + bucketNum + `[^\d\s]*\s*or (?:below|less|lower|fewer)`)
+	bucketBelowRe   = regexp.MustCompile(`(?:below|under|less than|lower than|fewer than)\s+\$?` + bucketNum)
+)
+
+// parseBucket reads the YES range of a market from its subtitle, falling
+// back to the question. step is the resolution granularity of the
+// underlying quantity (e.g. 1 for whole degrees, 0.1 for CPI prints): closed
+// bounds such as "70 to 71" or "70 or below" are widened to the half-open
+// grid so adjacent buckets line up exactly. With step 0 the quantity is
+// treated as continuous and each bound keeps whether it is inclusive, so
+// "70 or below" and "below 70" stay different buckets.
+func parseBucket(m *collectors.Market, step float64) (bucket, bool) {
+	for _, text := range []string{m.Subtitle, m.Question} {
+		if b, ok := parseBucketText(text, step); ok {
+			b.market = m
+			return b, true
+		}
+	}
+	return bucket{}, false
+}
+
+// IsRangeMarket reports whether a market's YES outcome is a numeric range
+// EvaluateRanges can parse.
+func IsRangeMarket(m *collectors.Market) bool {
+	_, ok := parseBucket(m, 0)
+	return ok
+}
+
+func parseBucketText(text string, step float64) (bucket, bool) {
+	t := strings.ToLower(strings.ReplaceAll(text, ",", ""))
+	if t == "" {
+		return bucket{}, false
+	}
+	inf := math.Inf(1)
+	if m := bucketBetweenRe.FindStringSubmatch(t); m != nil {
+		return closedBucket(parseNum(m[1], m[2]), parseNum(m[3], m[4]), step)
+	}
+	if m := bucketAtLeastRe.FindStringSubmatch(t); m != nil {
+		lo := firstNum(m[1:])
+		return bucket{lo: lo, hi: inf}, !math.IsNaN(lo)
+	}
+	if m := bucketAtMostRe.FindStringSubmatch(t); m != nil {
+		hi := firstNum(m[1:])
+		if step > 0 {
+			return bucket{lo: -inf, hi: hi + step}, !math.IsNaN(hi)
+		}
+		return bucket{lo: -inf, hi: hi, hiClosed: true}, !math.IsNaN(hi)
+	}
+	if m := bucketAboveRe.FindStringSubmatch(t); m != nil {
+		lo := parseNum(m[1], m[2])
+		if step > 0 {
+			return bucket{lo: lo + step, hi: inf}, true
+		}
+		return bucket{lo: lo, hi: inf, loOpen: true}, true
+	}
+	if m := bucketBelowRe.FindStringSubmatch(t); m != nil {
+		return bucket{lo: -inf, hi: parseNum(m[1], m[2])}, true
+	}
+	if m := bucketRangeRe.FindStringSubmatch(t); m != nil {
+		return closedBucket(parseNum(m[1], m[2]), parseNum(m[3], m[4]), step)
+	}
+	return bucket{}, false
+}
+
+func closedBucket(lo, hi, step float64) (bucket, bool) {
+	if hi < lo {
+		return bucket{}, false
+	}
+	if step > 0 {
+		return bucket{lo: lo, hi: hi + step}, true
+	}
+	return bucket{lo: lo, hi: hi, hiClosed: true}, true
+}
+
+// contains reports whether b lies inside target, bound inclusivity included.
+func (target bucket) contains(b bucket) bool {
+	loInside := b.lo > target.lo+epsilon || (sameBound(b.lo, target.lo) && (!target.loOpen || b.loOpen))
+	hiInside := b.hi < target.hi-epsilon || (sameBound(b.hi, target.hi) && (target.hiClosed || !b.hiClosed))
+	return loInside && hiInside
+}
+
+// firstNum parses the first populated (number, suffix) pair of a regexp
+// match with alternatives.
+func firstNum(groups []string) float64 {
+	for i := 0; i+1 < len(groups); i += 2 {
+		if groups[i] != "" {
+			return parseNum(groups[i], groups[i+1])
+		}
+	}
+	return math.NaN()
+}
+
+func parseNum(raw, suffix string) float64 {
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return math.NaN()
+	}
+	switch suffix {
+	case "k":
+		v *= 1e3
+	case "m":
+		v *= 1e6
+	case "b":
+		v *= 1e9
+	}
+	return v
+}
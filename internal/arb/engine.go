@@ -1,24 +1,122 @@
 package arb
 
 import (
-	"math"
 	"sort"
+	"time"
 
 	"github.com/hetulpatel/Arbitrage/internal/collectors"
 	"github.com/hetulpatel/Arbitrage/internal/matches"
 	"github.com/hetulpatel/Arbitrage/internal/models"
+	"github.com/hetulpatel/Arbitrage/internal/money"
 )
 
 type Config struct {
-	BudgetUSD    float64
+	BudgetUSD float64
+	// Budgets optionally re-runs the evaluation at several bankrolls; the
+	// best opportunity per budget is returned in Result.Sweep.
+	Budgets      []float64
 	ForceVerdict bool
+	// Fees selects per-venue fee models; nil uses DefaultFeeSchedule.
+	Fees *FeeSchedule
+	// Lots overrides per-venue lot sizes; missing venues use DefaultLotRules.
+	Lots map[collectors.Venue]LotRule
+	// RankBy picks the best direction; defaults to profit.
+	RankBy matches.RankMetric
+	// Tradability filters dead books; nil uses DefaultTradabilityPolicy.
+	Tradability *TradabilityPolicy
+	// MaxSnapshotAge and MaxSnapshotSkew bound how old each leg's snapshot
+	// may be and how far apart the legs were captured. Zero disables.
+	MaxSnapshotAge  time.Duration
+	MaxSnapshotSkew time.Duration
+	// AnyMapping prices both outcome mappings when the payload carries no
+	// validator verdict yet. Used by pre-checks so inverted pairs reach the
+	// validator instead of being dropped as unprofitable.
+	AnyMapping bool
+	// Scoring weighs the composite Opportunity.Score; nil uses
+	// matches.DefaultScoreWeights. Set RankBy to matches.RankByScore to rank
+	// by it.
+	Scoring *matches.ScoreWeights
+
+	match matchContext
+}
+
+func (c Config) feeSchedule() FeeSchedule {
+	if c.Fees == nil {
+		return DefaultFeeSchedule()
+	}
+	return *c.Fees
+}
+
+func (c Config) tradability() TradabilityPolicy {
+	if c.Tradability == nil {
+		return DefaultTradabilityPolicy()
+	}
+	return *c.Tradability
+}
+
+func (c Config) lotRule(venue collectors.Venue) LotRule {
+	if r, ok := c.Lots[venue]; ok {
+		return r
+	}
+	return DefaultLotRules()[venue]
 }
 
 type Result struct {
 	Opportunities map[matches.Direction]*matches.Opportunity
 	Best          *matches.Opportunity
 	Untradable    bool
-	Reason        string
+	// Stale is set when the snapshots are too old or too far apart to be
+	// priced together; Reason carries the code.
+	Stale  bool
+	Reason Reject
+	// Alternatives lists every profitable opportunity, best first, for
+	// evaluators that can find several at once (range buckets).
+	Alternatives []*matches.Opportunity
+	// Sweep holds one opportunity per Config.Budgets entry in ascending
+	// budget order. Budgets with no profitable direction get an empty
+	// opportunity with DirectionNone.
+	Sweep []*matches.Opportunity
+}
+
+// RejectCode identifies the rule that rejected an evaluation so rejects can
+// be counted per rule.
+type RejectCode string
+
+const (
+	RejectMissingData    RejectCode = "missing_data"
+	RejectUnknownVenue   RejectCode = "unknown_venue"
+	RejectZeroLiquidity  RejectCode = "zero_liquidity"
+	RejectNoAsk          RejectCode = "no_ask"
+	RejectNoBid          RejectCode = "no_bid"
+	RejectCrossedBook    RejectCode = "crossed_book"
+	RejectWideSpread     RejectCode = "wide_spread"
+	RejectDustBid        RejectCode = "dust_bid"
+	RejectLowAsk         RejectCode = "low_ask"
+	RejectOutcomeMapping RejectCode = "outcome_mapping"
+	RejectNotExclusive   RejectCode = "not_exclusive"
+	RejectNotExhaustive  RejectCode = "not_exhaustive"
+	RejectNoProfit       RejectCode = "no_profit"
+	RejectStale          RejectCode = "stale_snapshot"
+	RejectClockSkew      RejectCode = "clock_skew"
+)
+
+// Reject explains why a Result is untradable. Venue is set when the reject
+// is attributable to one venue.
+type Reject struct {
+	Code   RejectCode       `json:"code"`
+	Venue  collectors.Venue `json:"venue,omitempty"`
+	Detail string           `json:"detail,omitempty"`
+}
+
+func (r Reject) String() string {
+	out := string(r.Code)
+	if r.Venue != "" {
+		out = string(r.Venue) + " " + out
+	}
+	if r.Detail != "" {
+		out += " (" + r.Detail + ")"
+	}
+	return out
 }
 
 const epsilon = 1e-9
@@ -33,7 +131,7 @@ func Evaluate(match *matches.Payload, cfg Config) Result {
 	pmSnap, kxSnap := extractSnapshots(match)
 	if pmSnap == nil || kxSnap == nil {
 		res.Untradable = true
-		res.Reason = "missing snapshots"
+		res.Reason = Reject{Code: RejectMissingData, Detail: "missing snapshots"}
 		return res
 	}
 
@@ -41,8 +139,8 @@ func Evaluate(match *matches.Payload, cfg Config) Result {
 		forced := &matches.Opportunity{
 			Direction:    matches.DirectionBuyYesPMBuyNoKalshi,
 			Quantity:     1,
-			ProfitUSD:    0.01,
-			TotalCostUSD: 0.99,
+			ProfitUSD:    money.Cent,
+			TotalCostUSD: 99 * money.Cent,
 			BudgetUSD:    cfg.BudgetUSD,
 		}
 		res.Opportunities[forced.Direction] = forced
@@ -50,107 +148,92 @@ func Evaluate(match *matches.Payload, cfg Config) Result {
 		return res
 	}
 
-	if reason, untradable := isUntradable(pmSnap, kxSnap); untradable {
-		res.Untradable = true
+	if reason, stale := checkFreshness(cfg, time.Now().UTC(), pmSnap, kxSnap); stale {
+		res.Stale = true
 		res.Reason = reason
 		return res
 	}
 
-	opA := simulateDirection(cfg.BudgetUSD, matches.DirectionBuyYesPMBuyNoKalshi, pmSnap, kxSnap)
-	if opA != nil {
-		res.Opportunities[opA.Direction] = opA
-		if res.Best == nil || opA.ProfitUSD > res.Best.ProfitUSD {
-			res.Best = opA
-		}
+	if reason, untradable := checkTradability(cfg.tradability(), pmSnap, kxSnap); untradable {
+		res.Untradable = true
+		res.Reason = reason
+		return res
 	}
 
-	opB := simulateDirection(cfg.BudgetUSD, matches.DirectionBuyNoPMBuyYesKalshi, pmSnap, kxSnap)
-	if opB != nil {
-		res.Opportunities[opB.Direction] = opB
-		if res.Best == nil || opB.ProfitUSD > res.Best.ProfitUSD {
-			res.Best = opB
-		}
-	}
+	cfg.match = newMatchContext(match)
+	dirs := pairDirections(cfg, match.ResolutionVerdict)
+	res.Best = evaluateDirections(cfg, dirs, pmSnap, kxSnap, res.Opportunities)
+	res.Sweep = sweepBudgets(cfg, func(c Config) *matches.Opportunity {
+		return evaluateDirections(c, dirs, pmSnap, kxSnap, nil)
+	})
 
 	if res.Best == nil {
 		res.Untradable = true
-		res.Reason = "no profitable direction"
+		res.Reason = Reject{Code: RejectNoProfit}
 	}
 
 	return res
 }
 
-func isUntradable(pm, kx *models.MarketSnapshot) (string, bool) {
-	// Assumes prices are in [0,1]. epsilon should be small (e.g., 1e-9).
-
-	// Tunables (picked to mean "pass unless it's basically useless")
-	const (
-		MAX_SPREAD = 0.05 // >5c top-of-book spread = basically not tradable
-		DUST_BID   = 0.01 // 1c bid
-		DUST_ASK   = 0.03 // 3c ask
-		LOW_ASK    = 0.05 // <=5c ask region often indicates longshot "dust"
-		LOW_SPREAD = 0.02 // >=2c spread at penny prices => dust
-	)
-
-	// sideBad returns true if that side is effectively untradable (no real executable quote, absurd spread, or "dust").
-	sideBad := func(bid, ask float64) bool {
-		// Missing / non-executable
-		if ask <= epsilon || bid < 0.0 || ask < 0.0 {
-			return true
-		}
-
-		// If bid is basically missing, you can't realistically sell; if ask missing, can't buy
-		// (We treat bid<=epsilon as "bad" too because it often signals empty book.)
-		if bid <= epsilon {
-			return true
-		}
-
-		spread := ask - bid
-		if spread < 0 {
-			// Crossed/locked book: not necessarily untradable, but data is suspect; treat as bad for safety.
-			return true
-		}
+// pairDirections returns the hedged directions for the verdict's outcome
+// mapping. Without a verdict the pair is assumed to share polarity unless
+// cfg.AnyMapping asks for both.
+func pairDirections(cfg Config, verdict *matches.ResolutionVerdict) []matches.Direction {
+	same := []matches.Direction{matches.DirectionBuyYesPMBuyNoKalshi, matches.DirectionBuyNoPMBuyYesKalshi}
+	inverted := []matches.Direction{matches.DirectionBuyYesPMBuyYesKalshi, matches.DirectionBuyNoPMBuyNoKalshi}
+	switch {
+	case verdict.Inverted():
+		return inverted
+	case verdict == nil && cfg.AnyMapping:
+		return append(same, inverted...)
+	default:
+		return same
+	}
+}
 
-		// Absurd spread
-		if spread > MAX_SPREAD {
-			return true
+// evaluateDirections simulates the given pair directions and returns the
+// best one by cfg.RankBy. Non-nil opportunities are recorded in out when
+// given.
+func evaluateDirections(cfg Config, dirs []matches.Direction, pmSnap, kxSnap *models.MarketSnapshot, out map[matches.Direction]*matches.Opportunity) *matches.Opportunity {
+	var best *matches.Opportunity
+	for _, dir := range dirs {
+		op := simulateDirection(cfg, dir, pmSnap, kxSnap)
+		if op == nil {
+			continue
 		}
-
-		// "Dust" patterns: penny bids with a few-cent ask, or very low ask with wide relative spread
-		if bid <= DUST_BID && ask >= DUST_ASK {
-			return true
+		if out != nil {
+			out[op.Direction] = op
 		}
-		if ask <= LOW_ASK && spread >= LOW_SPREAD {
-			return true
+		if best == nil || cfg.RankBy.Score(op) > cfg.RankBy.Score(best) {
+			best = op
 		}
-
-		return false
-	}
-
-	// venueBad returns true if BOTH sides are bad (meaning: you can't sensibly trade either Yes or No on that venue).
-	venueBad := func(ms *models.MarketSnapshot) bool {
-		yesBad := sideBad(ms.Market.Price.YesBid, ms.Market.Price.YesAsk)
-		noBad := sideBad(ms.Market.Price.NoBid, ms.Market.Price.NoAsk)
-		return yesBad && noBad
 	}
+	return best
+}
 
-	// 1) Basic liquidity sanity: at least one ask on each venue (otherwise dead)
-	if pm.Market.Price.YesAsk <= epsilon && pm.Market.Price.NoAsk <= epsilon {
-		return "polymarket zero liquidity (asks)", true
-	}
-	if kx.Market.Price.YesAsk <= epsilon && kx.Market.Price.NoAsk <= epsilon {
-		return "kalshi zero liquidity (asks)", true
+// sweepBudgets runs eval once per configured budget, smallest first.
+func sweepBudgets(cfg Config, eval func(Config) *matches.Opportunity) []*matches.Opportunity {
+	if len(cfg.Budgets) == 0 {
+		return nil
 	}
-
-	// 2) Tradability check: only fail a venue if BOTH sides are bad
-	if venueBad(pm) {
-		return "polymarket both sides effectively untradable", true
+	budgets := make([]float64, 0, len(cfg.Budgets))
+	for _, b := range cfg.Budgets {
+		if b > 0 {
+			budgets = append(budgets, b)
+		}
 	}
-	if venueBad(kx) {
-		return "kalshi both sides effectively untradable", true
+	sort.Float64s(budgets)
+	sweep := make([]*matches.Opportunity, 0, len(budgets))
+	for _, b := range budgets {
+		c := cfg
+		c.BudgetUSD = b
+		op := eval(c)
+		if op == nil {
+			op = &matches.Opportunity{Direction: matches.DirectionNone, BudgetUSD: b}
+		}
+		sweep = append(sweep, op)
 	}
-
-	return "", false
+	return sweep
 }
 
 func extractSnapshots(match *matches.Payload) (*models.MarketSnapshot, *models.MarketSnapshot) {
@@ -177,120 +260,74 @@ func extractSnapshots(match *matches.Payload) (*models.MarketSnapshot, *models.M
 	return pm, kx
 }
 
-func simulateDirection(budget float64, dir matches.Direction, pmSnap, kxSnap *models.MarketSnapshot) *matches.Opportunity {
+// simulateDirection walks both ask ladders for one direction and sizes the
+// trade at the quantity that maximizes profit, stopping short of the budget
+// once the marginal pair cost (incl. fees) reaches $1. The optimum is then
+// rounded down to lot sizes; opportunities that vanish after rounding are
+// dropped.
+func simulateDirection(cfg Config, dir matches.Direction, pmSnap, kxSnap *models.MarketSnapshot) *matches.Opportunity {
 	if pmSnap == nil || kxSnap == nil {
 		return nil
 	}
+	fees := cfg.feeSchedule()
 	pmMarket := pmSnap.Market
 	kxMarket := kxSnap.Market
 
-	var pmBook, kxBook collectors.Orderbook
-	var pmOutcome, kxOutcome string
-
-	switch dir {
-	case matches.DirectionBuyYesPMBuyNoKalshi:
-		pmBook = getPMOrderbook(&pmMarket, true)
-		kxBook = kxMarket.Orderbooks["no"]
-		pmOutcome = "yes"
-		kxOutcome = "no"
-	case matches.DirectionBuyNoPMBuyYesKalshi:
-		pmBook = getPMOrderbook(&pmMarket, false)
-		kxBook = kxMarket.Orderbooks["yes"]
-		pmOutcome = "no"
-		kxOutcome = "yes"
-	default:
+	pmYes, kxYes, ok := directionOutcomes(dir)
+	if !ok {
 		return nil
 	}
+	pmBook := venueBook(collectors.VenuePolymarket, &pmMarket, pmYes)
+	kxBook := venueBook(collectors.VenueKalshi, &kxMarket, kxYes)
+	pmOutcome, kxOutcome := outcomeName(pmYes), outcomeName(kxYes)
 
 	if len(pmBook.Asks) == 0 || len(kxBook.Asks) == 0 {
 		return nil
 	}
 
-	pmIter := newAskIterator(pmBook.Asks)
-	kxIter := newAskIterator(kxBook.Asks)
+	ladders := [][]collectors.OrderbookLevel{pmBook.Asks, kxBook.Asks}
+	feeModels := []FeeModel{fees.ModelFor(pmSnap), fees.ModelFor(kxSnap)}
 
-	totalQty := 0.0
-	polyCost := 0.0
-	kalshiCost := 0.0
-	kalshiFees := 0.0
-
-	for {
-		yesQty := pmIter.peekQty()
-		noQty := kxIter.peekQty()
-		if yesQty <= epsilon || noQty <= epsilon {
-			break
-		}
-		pricePM := pmIter.peekPrice()
-		priceKX := kxIter.peekPrice()
-		budgetRemaining := budget - (polyCost + kalshiCost + kalshiFees)
-		if budgetRemaining <= epsilon {
-			break
-		}
-		estimatedCost := pricePM + priceKX
-		if estimatedCost <= epsilon {
-			break
-		}
-		delta := math.Min(yesQty, noQty)
-		delta = math.Min(delta, budgetRemaining/estimatedCost)
-		if delta <= epsilon {
-			break
-		}
-
-		costPM, ok := pmIter.take(delta)
-		if !ok {
-			break
-		}
-		costKX, ok := kxIter.take(delta)
-		if !ok {
-			break
-		}
-
-		fee := calcKalshiTakerFee(delta, priceKX)
-		polyCost += costPM
-		kalshiCost += costKX
-		kalshiFees += fee
-		totalQty += delta
-		if budget-(polyCost+kalshiCost+kalshiFees) <= epsilon {
-			break
-		}
+	w := walkLadders(cfg.BudgetUSD, 0, ladders, feeModels)
+	if w.best.qty <= epsilon {
+		return nil
 	}
-
-	if totalQty <= epsilon {
+	rule := CombineLots(cfg.lotRule(collectors.VenuePolymarket), cfg.lotRule(collectors.VenueKalshi))
+	exec, ok := executableFill(w.best, cfg.BudgetUSD, ladders, feeModels, rule)
+	if !ok {
 		return nil
 	}
 
-	op := &matches.Opportunity{
-		Direction:         dir,
-		Quantity:          totalQty,
-		PolymarketFeesUSD: 0,
-		KalshiFeesUSD:     kalshiFees,
-		BudgetUSD:         budget,
-	}
-	op.TotalCostUSD = polyCost + kalshiCost + kalshiFees
-	op.ProfitUSD = totalQty - op.TotalCostUSD
+	op := newOpportunity(dir, cfg.BudgetUSD, w, exec)
+	addLeg(op, collectors.VenuePolymarket, pmMarket.MarketID, pmOutcome, exec.legs[0], pmMarket.TickSize)
+	addLeg(op, collectors.VenueKalshi, kxMarket.MarketID, kxOutcome, exec.legs[1], kxMarket.TickSize)
+	applyYield(op, time.Now().UTC(), closeTime(pmSnap), closeTime(kxSnap))
+	applyScore(cfg, op, ladders, pmSnap, kxSnap)
+	return op
+}
 
-	pmLeg := matches.Leg{
-		Venue:    "polymarket",
-		Side:     "buy",
-		Outcome:  pmOutcome,
-		Quantity: totalQty,
-		CostUSD:  polyCost,
-	}
-	if totalQty > 0 {
-		pmLeg.AvgPrice = polyCost / totalQty
-	}
-	kxLeg := matches.Leg{
-		Venue:    "kalshi",
-		Side:     "buy",
-		Outcome:  kxOutcome,
-		Quantity: totalQty,
-		CostUSD:  kalshiCost,
+// directionOutcomes reports which outcome each venue buys in a cross-venue
+// direction (true for YES).
+func directionOutcomes(dir matches.Direction) (pmYes, kxYes, ok bool) {
+	switch dir {
+	case matches.DirectionBuyYesPMBuyNoKalshi:
+		return true, false, true
+	case matches.DirectionBuyNoPMBuyYesKalshi:
+		return false, true, true
+	case matches.DirectionBuyYesPMBuyYesKalshi:
+		return true, true, true
+	case matches.DirectionBuyNoPMBuyNoKalshi:
+		return false, false, true
+	default:
+		return false, false, false
 	}
-	if totalQty > 0 {
-		kxLeg.AvgPrice = kalshiCost / totalQty
+}
+
+func outcomeName(yes bool) string {
+	if yes {
+		return "yes"
 	}
-	op.Legs = []matches.Leg{pmLeg, kxLeg}
-	return op
+	return "no"
 }
 
 func getPMOrderbook(m *collectors.Market, yes bool) collectors.Orderbook {
@@ -314,6 +351,17 @@ func getPMOrderbook(m *collectors.Market, yes bool) collectors.Orderbook {
 	return collectors.Orderbook{}
 }
 
+// venueBook returns a market's YES or NO book on either venue.
+func venueBook(venue collectors.Venue, m *collectors.Market, yes bool) collectors.Orderbook {
+	if venue == collectors.VenuePolymarket {
+		return getPMOrderbook(m, yes)
+	}
+	if yes {
+		return m.Orderbooks["yes"]
+	}
+	return m.Orderbooks["no"]
+}
+
 type askIterator struct {
 	levels []collectors.OrderbookLevel
 	idx    int
@@ -338,7 +386,7 @@ func (it *askIterator) peekQty() float64 {
 	return 0
 }
 
-func (it *askIterator) peekPrice() float64 {
+func (it *askIterator) peekPrice() money.Micros {
 	for it.idx < len(it.levels) {
 		if qty := it.levels[it.idx].Quantity; qty > epsilon {
 			return it.levels[it.idx].Price
@@ -348,7 +396,9 @@ func (it *askIterator) peekPrice() float64 {
 	return 0
 }
 
-func (it *askIterator) take(q float64) (float64, bool) {
+// take consumes q contracts from the current level and returns their exact
+// cost.
+func (it *askIterator) take(q float64) (money.Micros, bool) {
 	for it.idx < len(it.levels) {
 		lvl := &it.levels[it.idx]
 		if lvl.Quantity <= epsilon {
@@ -359,7 +409,7 @@ func (it *askIterator) take(q float64) (float64, bool) {
 			return 0, false
 		}
 		lvl.Quantity -= q
-		cost := q * lvl.Price
+		cost := lvl.Price.Mul(q)
 		if lvl.Quantity <= epsilon {
 			it.idx++
 		}
@@ -367,8 +417,3 @@ func (it *askIterator) take(q float64) (float64, bool) {
 	}
 	return 0, false
 }
-
-func calcKalshiTakerFee(quantity, price float64) float64 {
-	raw := 0.07 * quantity * price * (1 - price)
-	return math.Ceil(raw*100) / 100
-}
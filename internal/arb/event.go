@@ -0,0 +1,301 @@
+package arb
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+)
+
+// OutcomeMap pairs a Polymarket market ID with the Kalshi market ID that
+// represents the same outcome inside a mutually exclusive event.
+type OutcomeMap map[string]string
+
+// EventConfig controls the categorical (multi-outcome) evaluator.
+type EventConfig struct {
+	BudgetUSD float64
+	// Budgets optionally sweeps the basket across several bankrolls.
+	Budgets []float64
+	// Outcomes optionally pins the Polymarket->Kalshi market mapping. When
+	// empty, outcomes are paired by label similarity.
+	Outcomes OutcomeMap
+	// MinLabelScore is the minimum token overlap (0-1) required to pair two
+	// outcome labels automatically. Defaults to 0.6.
+	MinLabelScore float64
+	// Fees selects per-venue fee models; nil uses DefaultFeeSchedule.
+	Fees *FeeSchedule
+	// Lots overrides per-venue lot sizes; missing venues use DefaultLotRules.
+	Lots map[collectors.Venue]LotRule
+}
+
+// EvaluateEvents prices a YES basket across two mutually exclusive event
+// families (e.g. a Kalshi event with many markets and a Polymarket neg-risk
+// event). Exactly one outcome pays $1, so buying YES on every outcome from
+// whichever venue is cheaper locks in a profit whenever the basket costs
+// less than $1 after fees. Both venues must flag the event as mutually
+// exclusive and list every outcome by name; a catch-all "Other" outcome or
+// an unlisted market could pay instead of the basket.
+func EvaluateEvents(pmEvent, kxEvent *collectors.Event, cfg EventConfig) Result {
+	if cfg.BudgetUSD <= 0 {
+		cfg.BudgetUSD = 100
+	}
+	if cfg.MinLabelScore <= 0 {
+		cfg.MinLabelScore = 0.6
+	}
+	res := Result{Opportunities: make(map[matches.Direction]*matches.Opportunity)}
+
+	if pmEvent == nil || kxEvent == nil || len(pmEvent.Markets) == 0 || len(kxEvent.Markets) == 0 {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectMissingData, Detail: "missing events"}
+		return res
+	}
+	if pmEvent.Venue != collectors.VenuePolymarket || kxEvent.Venue != collectors.VenueKalshi {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectUnknownVenue, Detail: "unexpected event venues"}
+		return res
+	}
+	for _, ev := range []*collectors.Event{pmEvent, kxEvent} {
+		if reason := checkExhaustive(ev); reason.Code != "" {
+			res.Untradable = true
+			res.Reason = reason
+			return res
+		}
+	}
+
+	pairs, reason := mapOutcomes(pmEvent.Markets, kxEvent.Markets, cfg)
+	if reason != "" {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectOutcomeMapping, Detail: reason}
+		return res
+	}
+
+	basketCfg := Config{BudgetUSD: cfg.BudgetUSD, Budgets: cfg.Budgets, Fees: cfg.Fees, Lots: cfg.Lots}
+	op := simulateBasket(basketCfg, pairs, pmEvent, kxEvent)
+	res.Sweep = sweepBudgets(basketCfg, func(c Config) *matches.Opportunity {
+		return simulateBasket(c, pairs, pmEvent, kxEvent)
+	})
+	if op == nil {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectNoProfit}
+		return res
+	}
+	res.Opportunities[op.Direction] = op
+	res.Best = op
+	return res
+}
+
+type outcomePair struct {
+	pm *collectors.Market
+	kx *collectors.Market
+}
+
+// mapOutcomes requires a one-to-one mapping between both families; an
+// outcome listed on only one venue means the basket would not be exhaustive.
+func mapOutcomes(pmMarkets, kxMarkets []collectors.Market, cfg EventConfig) ([]outcomePair, string) {
+	if len(pmMarkets) != len(kxMarkets) {
+		return nil, "outcome count mismatch"
+	}
+	kxByID := make(map[string]*collectors.Market, len(kxMarkets))
+	for i := range kxMarkets {
+		kxByID[kxMarkets[i].MarketID] = &kxMarkets[i]
+	}
+
+	pairs := make([]outcomePair, 0, len(pmMarkets))
+	used := make(map[string]bool, len(kxMarkets))
+
+	if len(cfg.Outcomes) > 0 {
+		for i := range pmMarkets {
+			kxID, ok := cfg.Outcomes[pmMarkets[i].MarketID]
+			if !ok {
+				return nil, "unmapped polymarket outcome " + pmMarkets[i].MarketID
+			}
+			kx, ok := kxByID[kxID]
+			if !ok || used[kxID] {
+				return nil, "invalid kalshi outcome " + kxID
+			}
+			used[kxID] = true
+			pairs = append(pairs, outcomePair{pm: &pmMarkets[i], kx: kx})
+		}
+		return pairs, ""
+	}
+
+	type candidate struct {
+		pm, kx int
+		score  float64
+	}
+	var cands []candidate
+	for i := range pmMarkets {
+		pmTokens := labelTokens(&pmMarkets[i])
+		for j := range kxMarkets {
+			score := tokenOverlap(pmTokens, labelTokens(&kxMarkets[j]))
+			if score >= cfg.MinLabelScore {
+				cands = append(cands, candidate{pm: i, kx: j, score: score})
+			}
+		}
+	}
+	sort.SliceStable(cands, func(a, b int) bool {
+		return cands[a].score > cands[b].score
+	})
+	pmUsed := make(map[int]bool, len(pmMarkets))
+	kxUsed := make(map[int]bool, len(kxMarkets))
+	for _, c := range cands {
+		if pmUsed[c.pm] || kxUsed[c.kx] {
+			continue
+		}
+		pmUsed[c.pm] = true
+		kxUsed[c.kx] = true
+		pairs = append(pairs, outcomePair{pm: &pmMarkets[c.pm], kx: &kxMarkets[c.kx]})
+	}
+	if len(pairs) != len(pmMarkets) {
+		return nil, "could not map every outcome"
+	}
+	return pairs, ""
+}
+
+// checkExhaustive rejects families where buying every listed outcome does
+// not guarantee exactly one winner.
+func checkExhaustive(ev *collectors.Event) Reject {
+	if !ev.MutuallyExclusive {
+		return Reject{Code: RejectNotExclusive, Venue: ev.Venue, Detail: "event " + ev.EventID + " is not mutually exclusive"}
+	}
+	if ev.Unlisted > 0 {
+		return Reject{Code: RejectNotExhaustive, Venue: ev.Venue, Detail: fmt.Sprintf("event %s has %d unlisted outcomes", ev.EventID, ev.Unlisted)}
+	}
+	for i := range ev.Markets {
+		if isCatchAllOutcome(&ev.Markets[i]) {
+			return Reject{Code: RejectNotExhaustive, Venue: ev.Venue, Detail: "catch-all outcome " + ev.Markets[i].MarketID}
+		}
+	}
+	return Reject{}
+}
+
+var catchAllRe = regexp.MustCompile(`(?i)\b(other|others|another|anyone else|someone else|none of the above|field)\b`)
+
+// isCatchAllOutcome spots "Other" / "Another candidate" style outcomes whose
+// meaning depends on which outcomes each venue lists.
+func isCatchAllOutcome(m *collectors.Market) bool {
+	label := m.Subtitle
+	if label == "" || len(label) > 80 {
+		label = m.Question
+	}
+	return catchAllRe.MatchString(label)
+}
+
+var labelTokenRe = regexp.MustCompile(`[a-z0-9]+`)
+
+var labelStopwords = map[string]bool{
+	"will": true, "the": true, "be": true, "a": true, "an": true, "of": true,
+	"in": true, "on": true, "by": true, "to": true, "win": true, "wins": true,
+	"yes": true, "is": true, "for": true, "and": true,
+}
+
+func labelTokens(m *collectors.Market) map[string]bool {
+	text := m.Subtitle
+	if text == "" || len(text) > 80 {
+		text = m.Question
+	}
+	out := make(map[string]bool)
+	for _, tok := range labelTokenRe.FindAllString(strings.ToLower(text), -1) {
+		if !labelStopwords[tok] {
+			out[tok] = true
+		}
+	}
+	return out
+}
+
+func tokenOverlap(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for tok := range a {
+		if b[tok] {
+			shared++
+		}
+	}
+	smaller := len(a)
+	if len(b) < smaller {
+		smaller = len(b)
+	}
+	return float64(shared) / float64(smaller)
+}
+
+// basketLeg is the chosen venue ladder for one outcome.
+type basketLeg struct {
+	venue    collectors.Venue
+	market   *collectors.Market
+	asks     []collectors.OrderbookLevel
+	feeModel FeeModel
+}
+
+func (l *basketLeg) unitCost() float64 {
+	price := newAskIterator(l.asks).peekPrice().Float()
+	return price + l.feeModel.TakerFee(1, price)
+}
+
+func simulateBasket(cfg Config, pairs []outcomePair, pmEvent, kxEvent *collectors.Event) *matches.Opportunity {
+	schedule := cfg.feeSchedule()
+	legs := make([]*basketLeg, 0, len(pairs))
+	ladders := make([][]collectors.OrderbookLevel, 0, len(pairs))
+	feeModels := make([]FeeModel, 0, len(pairs))
+	var rules []LotRule
+	for _, p := range pairs {
+		leg := cheaperYesLeg(p, schedule.modelFor(collectors.VenuePolymarket, pmEvent, p.pm), schedule.modelFor(collectors.VenueKalshi, kxEvent, p.kx))
+		if leg == nil {
+			return nil
+		}
+		legs = append(legs, leg)
+		ladders = append(ladders, leg.asks)
+		feeModels = append(feeModels, leg.feeModel)
+		rules = append(rules, cfg.lotRule(leg.venue))
+	}
+
+	w := walkLadders(cfg.BudgetUSD, 0, ladders, feeModels)
+	if w.best.qty <= epsilon {
+		return nil
+	}
+	exec, ok := executableFill(w.best, cfg.BudgetUSD, ladders, feeModels, CombineLots(rules...))
+	if !ok {
+		return nil
+	}
+
+	op := newOpportunity(matches.DirectionBuyYesBasket, cfg.BudgetUSD, w, exec)
+	closes := make([]time.Time, 0, len(pairs)*2)
+	for i, leg := range legs {
+		addLeg(op, leg.venue, leg.market.MarketID, "yes", exec.legs[i], leg.market.TickSize)
+	}
+	for _, p := range pairs {
+		closes = append(closes, marketCloseTime(pmEvent, p.pm), marketCloseTime(kxEvent, p.kx))
+	}
+	applyYield(op, time.Now().UTC(), closes...)
+	applyScore(cfg, op, ladders)
+	return op
+}
+
+// cheaperYesLeg picks the venue with the lower fee-inclusive best YES ask.
+func cheaperYesLeg(p outcomePair, pmFees, kxFees FeeModel) *basketLeg {
+	pmBook := getPMOrderbook(p.pm, true)
+	kxBook := p.kx.Orderbooks["yes"]
+
+	var pmLeg, kxLeg *basketLeg
+	if len(pmBook.Asks) > 0 {
+		pmLeg = &basketLeg{venue: collectors.VenuePolymarket, market: p.pm, asks: pmBook.Asks, feeModel: pmFees}
+	}
+	if len(kxBook.Asks) > 0 {
+		kxLeg = &basketLeg{venue: collectors.VenueKalshi, market: p.kx, asks: kxBook.Asks, feeModel: kxFees}
+	}
+	switch {
+	case pmLeg == nil:
+		return kxLeg
+	case kxLeg == nil:
+		return pmLeg
+	}
+	if kxLeg.unitCost() < pmLeg.unitCost() {
+		return kxLeg
+	}
+	return pmLeg
+}
@@ -0,0 +1,159 @@
+package arb
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+)
+
+// FeeModel prices venue fees for a fill of quantity contracts at price.
+// Fees are returned unrounded; Round applies the venue's rounding rule.
+type FeeModel interface {
+	TakerFee(quantity, price float64) float64
+	MakerFee(quantity, price float64) float64
+	Round(fee float64) float64
+}
+
+// Fee formulas understood by FeeRule.
+const (
+	// FeeFormulaNone charges nothing.
+	FeeFormulaNone = "none"
+	// FeeFormulaQuadratic is Kalshi's rate * C * P * (1-P).
+	FeeFormulaQuadratic = "quadratic"
+	// FeeFormulaMinPrice is Polymarket's rate * C * min(P, 1-P).
+	FeeFormulaMinPrice = "min_price"
+)
+
+// FeeRule is one configurable fee schedule.
+type FeeRule struct {
+	Formula     string  `json:"formula"`
+	TakerRate   float64 `json:"taker_rate"`
+	MakerRate   float64 `json:"maker_rate"`
+	RoundToCent bool    `json:"round_to_cent"`
+}
+
+// VenueFees holds the default rule for a venue plus overrides. Lookups go
+// market -> series -> category -> default.
+type VenueFees struct {
+	Default    FeeRule            `json:"default"`
+	Series     map[string]FeeRule `json:"series,omitempty"`
+	Markets    map[string]FeeRule `json:"markets,omitempty"`
+	Categories map[string]FeeRule `json:"categories,omitempty"`
+}
+
+// FeeSchedule selects a FeeModel per venue and market.
+type FeeSchedule struct {
+	Kalshi     VenueFees `json:"kalshi"`
+	Polymarket VenueFees `json:"polymarket"`
+}
+
+// DefaultFeeSchedule mirrors the published general schedules: Kalshi takers
+// pay 0.07*C*P*(1-P) rounded up to the cent, makers pay nothing, and
+// Polymarket is fee-free unless overridden.
+func DefaultFeeSchedule() FeeSchedule {
+	return FeeSchedule{
+		Kalshi: VenueFees{
+			Default: FeeRule{Formula: FeeFormulaQuadratic, TakerRate: 0.07, RoundToCent: true},
+		},
+		Polymarket: VenueFees{
+			Default: FeeRule{Formula: FeeFormulaNone},
+		},
+	}
+}
+
+// LoadFeeSchedule reads a JSON schedule from path. An empty path returns the
+// default schedule.
+func LoadFeeSchedule(path string) (FeeSchedule, error) {
+	if path == "" {
+		return DefaultFeeSchedule(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FeeSchedule{}, fmt.Errorf("read fee schedule: %w", err)
+	}
+	sched := DefaultFeeSchedule()
+	if err := json.Unmarshal(data, &sched); err != nil {
+		return FeeSchedule{}, fmt.Errorf("parse fee schedule: %w", err)
+	}
+	return sched, nil
+}
+
+// ModelFor returns the fee model for the snapshot's venue and market.
+func (s FeeSchedule) ModelFor(snap *models.MarketSnapshot) FeeModel {
+	if snap == nil {
+		return ruleFee{}
+	}
+	return s.modelFor(snap.Venue, &snap.Event, &snap.Market)
+}
+
+func (s FeeSchedule) modelFor(venue collectors.Venue, ev *collectors.Event, m *collectors.Market) FeeModel {
+	var vf VenueFees
+	switch venue {
+	case collectors.VenueKalshi:
+		vf = s.Kalshi
+	case collectors.VenuePolymarket:
+		vf = s.Polymarket
+	default:
+		return ruleFee{}
+	}
+	if m != nil {
+		if r, ok := vf.Markets[m.MarketID]; ok {
+			return ruleFee(r)
+		}
+	}
+	if ev != nil {
+		if r, ok := vf.Series[seriesTicker(venue, ev.EventID)]; ok {
+			return ruleFee(r)
+		}
+		if r, ok := vf.Categories[ev.Category]; ok {
+			return ruleFee(r)
+		}
+	}
+	return ruleFee(vf.Default)
+}
+
+// seriesTicker derives the Kalshi series from the event ticker
+// (e.g. KXBTC-25DEC31 -> KXBTC).
+func seriesTicker(venue collectors.Venue, eventID string) string {
+	if venue != collectors.VenueKalshi || eventID == "" {
+		return ""
+	}
+	if idx := strings.Index(eventID, "-"); idx > 0 {
+		return eventID[:idx]
+	}
+	return eventID
+}
+
+type ruleFee FeeRule
+
+func (r ruleFee) TakerFee(quantity, price float64) float64 {
+	return r.apply(r.TakerRate, quantity, price)
+}
+
+func (r ruleFee) MakerFee(quantity, price float64) float64 {
+	return r.apply(r.MakerRate, quantity, price)
+}
+
+func (r ruleFee) apply(rate, quantity, price float64) float64 {
+	switch r.Formula {
+	case FeeFormulaQuadratic:
+		return rate * quantity * price * (1 - price)
+	case FeeFormulaMinPrice:
+		return rate * quantity * math.Min(price, 1-price)
+	default:
+		return 0
+	}
+}
+
+func (r ruleFee) Round(fee float64) float64 {
+	if !r.RoundToCent || fee <= 0 {
+		return fee
+	}
+	// Guard against float noise pushing an exact cent amount up a cent.
+	return math.Ceil(fee*100-epsilon) / 100
+}
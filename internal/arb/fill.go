@@ -0,0 +1,231 @@
+package arb
+
+import (
+	"math"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+// fill is a running total of every leg while walking the ask ladders. All
+// legs are filled to the same quantity.
+type fill struct {
+	qty  float64
+	legs []legFill
+}
+
+// legFill accumulates what will become a single limit order: the contracts
+// taken from one ladder, their cost, the unrounded fee, and the worst price
+// crossed. Fees are rounded once per order, the way venues charge them,
+// rather than once per ladder level.
+type legFill struct {
+	fees   FeeModel
+	qty    float64
+	cost   money.Micros
+	rawFee float64
+	worst  money.Micros
+}
+
+func newFill(models ...FeeModel) fill {
+	f := fill{legs: make([]legFill, len(models))}
+	for i, m := range models {
+		f.legs[i].fees = m
+	}
+	return f
+}
+
+func (f fill) clone() fill {
+	out := fill{qty: f.qty, legs: make([]legFill, len(f.legs))}
+	copy(out.legs, f.legs)
+	return out
+}
+
+func (f *fill) add(leg int, qty float64, cost, price money.Micros) {
+	l := &f.legs[leg]
+	l.qty += qty
+	l.cost += cost
+	l.rawFee += l.fees.TakerFee(qty, price.Float())
+	if price > l.worst {
+		l.worst = price
+	}
+}
+
+func (f fill) totalCost() money.Micros {
+	var total money.Micros
+	for _, l := range f.legs {
+		total += l.cost + l.fee()
+	}
+	return total
+}
+
+// profit is the $1-per-contract payout minus everything paid for it.
+func (f fill) profit() money.Micros {
+	if len(f.legs) == 0 {
+		return 0
+	}
+	return money.Dollar.Mul(f.qty) - f.totalCost()
+}
+
+// fee is the venue-rounded fee for the whole order. Fee formulas are
+// real-valued; the result is fixed to micros once, here.
+func (l legFill) fee() money.Micros {
+	if l.fees == nil {
+		return 0
+	}
+	return money.FromFloat(l.fees.Round(l.rawFee))
+}
+
+// order turns the leg into one limit order priced at the worst level crossed,
+// rounded up to the market's tick so the order still reaches that level.
+func (l legFill) order(leg matches.Leg, tick float64) matches.Order {
+	return matches.Order{
+		Venue:      leg.Venue,
+		MarketID:   leg.MarketID,
+		Side:       leg.Side,
+		Outcome:    leg.Outcome,
+		Type:       "limit",
+		LimitPrice: roundUpToTick(l.worst, tick),
+		Quantity:   l.qty,
+		FeeUSD:     l.fee(),
+	}
+}
+
+// walk is the outcome of walking a set of ladders in lockstep.
+type walk struct {
+	best  fill // highest-profit prefix
+	last  fill // everything walked
+	curve []matches.CurvePoint
+}
+
+// walkLadders buys the same quantity on every ladder, one price slice at a
+// time, until the budget or a book runs out (or maxQty is reached when
+// positive). Every slice is recorded on the profit curve.
+func walkLadders(budget, maxQty float64, ladders [][]collectors.OrderbookLevel, fees []FeeModel) walk {
+	iters := make([]*askIterator, len(ladders))
+	for i, levels := range ladders {
+		iters[i] = newAskIterator(levels)
+	}
+	cur := newFill(fees...)
+	out := walk{best: newFill(fees...)}
+
+	for {
+		delta := math.Inf(1)
+		unit := 0.0
+		for _, it := range iters {
+			q := it.peekQty()
+			if q <= epsilon {
+				delta = 0
+				break
+			}
+			delta = math.Min(delta, q)
+			unit += it.peekPrice().Float()
+		}
+		if delta <= epsilon || unit <= epsilon {
+			break
+		}
+		before := cur.totalCost()
+		remaining := budget - before.Float()
+		if remaining <= epsilon {
+			break
+		}
+		budgetBound := remaining/unit < delta
+		delta = math.Min(delta, remaining/unit)
+		if maxQty > 0 {
+			delta = math.Min(delta, maxQty-cur.qty)
+		}
+		if delta <= epsilon {
+			break
+		}
+
+		prev := cur.clone()
+		ok := true
+		for i, it := range iters {
+			price := it.peekPrice()
+			cost, took := it.take(delta)
+			if !took {
+				ok = false
+				break
+			}
+			cur.add(i, delta, cost, price)
+		}
+		if !ok {
+			break
+		}
+		// Once the leftover budget buys less than a micro per slice the
+		// cost stops growing and the walk would spin on dust forever.
+		// Slices that exhaust a level always make progress.
+		if budgetBound && cur.totalCost() == before {
+			cur = prev
+			break
+		}
+		cur.qty += delta
+
+		out.curve = append(out.curve, matches.CurvePoint{
+			Quantity:     cur.qty,
+			TotalCostUSD: cur.totalCost().Float(),
+			ProfitUSD:    cur.profit().Float(),
+			MarginalCost: (cur.totalCost() - before).Float() / delta,
+		})
+		if cur.profit() > out.best.profit() {
+			out.best = cur.clone()
+		}
+	}
+	out.last = cur
+	return out
+}
+
+// executableFill rounds the theoretical optimum down to the venue lot size,
+// then re-walks the books to price exactly that quantity. It reports false
+// when the rounded trade is below the minimum order size or no longer
+// profitable.
+func executableFill(theoretical fill, budget float64, ladders [][]collectors.OrderbookLevel, fees []FeeModel, rule LotRule) (fill, bool) {
+	qty := rule.Floor(theoretical.qty)
+	if qty <= epsilon || qty+epsilon < rule.MinQty {
+		return fill{}, false
+	}
+	w := walkLadders(budget, qty, ladders, fees)
+	if w.last.qty+epsilon < qty || w.last.profit() <= 0 {
+		return fill{}, false
+	}
+	return w.last, true
+}
+
+// newOpportunity fills the sizing fields shared by every strategy: the
+// executable (lot-rounded) trade plus the theoretical optimum it came from.
+func newOpportunity(dir matches.Direction, budget float64, w walk, exec fill) *matches.Opportunity {
+	return &matches.Opportunity{
+		Direction:            dir,
+		Quantity:             exec.qty,
+		ProfitUSD:            exec.profit(),
+		TotalCostUSD:         exec.totalCost(),
+		BudgetUSD:            budget,
+		TheoreticalQuantity:  w.best.qty,
+		TheoreticalProfitUSD: w.best.profit(),
+		Curve:                w.curve,
+	}
+}
+
+// addLeg records one leg of the executable fill as both a Leg and an Order
+// and books its fee against the right venue.
+func addLeg(op *matches.Opportunity, venue collectors.Venue, marketID, outcome string, lf legFill, tick float64) {
+	leg := matches.Leg{
+		Venue:    string(venue),
+		MarketID: marketID,
+		Side:     "buy",
+		Outcome:  outcome,
+		Quantity: lf.qty,
+		CostUSD:  lf.cost,
+	}
+	if lf.qty > 0 {
+		leg.AvgPrice = lf.cost.Float() / lf.qty
+	}
+	switch venue {
+	case collectors.VenueKalshi:
+		op.KalshiFeesUSD += lf.fee()
+	case collectors.VenuePolymarket:
+		op.PolymarketFeesUSD += lf.fee()
+	}
+	op.Legs = append(op.Legs, leg)
+	op.Orders = append(op.Orders, lf.order(leg, tick))
+}
@@ -0,0 +1,42 @@
+package arb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/models"
+)
+
+// checkFreshness rejects snapshots older than cfg.MaxSnapshotAge and legs
+// captured more than cfg.MaxSnapshotSkew apart. A capture time that is ahead
+// of now by more than the skew allowance means the producing host's clock
+// is off and is also rejected. Zero limits disable the respective check.
+func checkFreshness(cfg Config, now time.Time, snaps ...*models.MarketSnapshot) (Reject, bool) {
+	if cfg.MaxSnapshotAge <= 0 && cfg.MaxSnapshotSkew <= 0 {
+		return Reject{}, false
+	}
+	var oldest, newest time.Time
+	for _, snap := range snaps {
+		captured := snap.CapturedAt
+		if captured.IsZero() {
+			return Reject{Code: RejectStale, Venue: snap.Venue, Detail: "missing captured_at"}, true
+		}
+		age := now.Sub(captured)
+		if cfg.MaxSnapshotAge > 0 && age > cfg.MaxSnapshotAge {
+			return Reject{Code: RejectStale, Venue: snap.Venue, Detail: fmt.Sprintf("age=%s", age.Round(time.Second))}, true
+		}
+		if cfg.MaxSnapshotSkew > 0 && -age > cfg.MaxSnapshotSkew {
+			return Reject{Code: RejectClockSkew, Venue: snap.Venue, Detail: fmt.Sprintf("captured %s in the future", (-age).Round(time.Second))}, true
+		}
+		if oldest.IsZero() || captured.Before(oldest) {
+			oldest = captured
+		}
+		if captured.After(newest) {
+			newest = captured
+		}
+	}
+	if skew := newest.Sub(oldest); cfg.MaxSnapshotSkew > 0 && skew > cfg.MaxSnapshotSkew {
+		return Reject{Code: RejectClockSkew, Detail: fmt.Sprintf("skew=%s", skew.Round(time.Millisecond))}, true
+	}
+	return Reject{}, false
+}
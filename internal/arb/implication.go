@@ -0,0 +1,183 @@
+package arb
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+)
+
+// implicationMinOverlap is the share of the shorter question's topic words
+// the other market must share for the two to count as the same underlying.
+const implicationMinOverlap = 0.5
+
+// implicationMaxCloseGap bounds how long before the looser market the
+// stricter one may resolve.
+const implicationMaxCloseGap = 24 * time.Hour
+
+// ValidateImplication checks that a hand-listed relation really holds before
+// it is traded: both markets are thresholds on the same underlying (their
+// questions share most topic words once numbers, dates and comparison words
+// are dropped), the stricter market closes no later than the looser one and
+// at most a day before it, and every value that resolves the stricter market
+// YES also resolves the looser one YES. A stricter market closing later could
+// resolve YES after the looser one resolved NO, and both legs would pay 0.
+func ValidateImplication(stricter, looser *models.MarketSnapshot) error {
+	if stricter == nil || looser == nil {
+		return fmt.Errorf("missing snapshots")
+	}
+	if overlap := tokenOverlap(underlyingTokens(stricter), underlyingTokens(looser)); overlap < implicationMinOverlap {
+		return fmt.Errorf("questions do not share an underlying (overlap %.2f)", overlap)
+	}
+	sc, lc := closeTime(stricter), closeTime(looser)
+	if !sc.IsZero() && !lc.IsZero() {
+		if sc.After(lc) {
+			return fmt.Errorf("stricter market closes %s after the looser one", sc.Sub(lc))
+		}
+		if gap := lc.Sub(sc); gap > implicationMaxCloseGap {
+			return fmt.Errorf("stricter market closes %s before the looser one", gap)
+		}
+	}
+	strict, ok := parseBucket(&stricter.Market, 0)
+	if !ok {
+		return fmt.Errorf("stricter market %s has no parseable threshold", stricter.Market.MarketID)
+	}
+	loose, ok := parseBucket(&looser.Market, 0)
+	if !ok {
+		return fmt.Errorf("looser market %s has no parseable threshold", looser.Market.MarketID)
+	}
+	if !loose.contains(strict) {
+		return fmt.Errorf("stricter range %s is not inside looser range %s", strict.interval(), loose.interval())
+	}
+	return nil
+}
+
+var underlyingNumRe = regexp.MustCompile(`\$?\d[\d,.]*[kmb%°]?`)
+
+var underlyingStopwords = map[string]bool{
+	"above": true, "below": true, "over": true, "under": true, "more": true,
+	"less": true, "than": true, "least": true, "most": true, "or": true,
+	"at": true, "greater": true, "higher": true, "lower": true, "fewer": true,
+	"between": true, "exceed": true, "exceeds": true, "reach": true,
+	"hit": true, "price": true, "no": true,
+}
+
+// monthWords are dropped from the underlying comparison; dates are checked
+// through the close times instead.
+var monthWords = map[string]bool{
+	"jan": true, "january": true, "feb": true, "february": true, "mar": true,
+	"march": true, "apr": true, "april": true, "may": true, "jun": true,
+	"june": true, "jul": true, "july": true, "aug": true, "august": true,
+	"sep": true, "sept": true, "september": true, "oct": true, "october": true,
+	"nov": true, "november": true, "dec": true, "december": true,
+}
+
+// underlyingTokens are the topic words of a market's event title and
+// question with thresholds and comparison words removed.
+func underlyingTokens(snap *models.MarketSnapshot) map[string]bool {
+	text := underlyingNumRe.ReplaceAllString(strings.ToLower(snap.Event.Title+" "+snap.Market.Question), " ")
+	out := make(map[string]bool)
+	for _, tok := range labelTokenRe.FindAllString(text, -1) {
+		if !labelStopwords[tok] && !underlyingStopwords[tok] && !monthWords[tok] {
+			out[tok] = true
+		}
+	}
+	return out
+}
+
+// EvaluateImplication prices a validated "stricter implies looser" pair by
+// buying YES on the looser market and NO on the stricter one. The position
+// pays $1 when both resolve the same way and $2 when only the looser market
+// resolves YES; the impossible state (stricter YES, looser NO) is excluded by
+// the relation. Sizing and ProfitUSD use the $1 floor, so any opportunity is
+// a guaranteed profit with the $2 state as upside.
+func EvaluateImplication(stricter, looser *models.MarketSnapshot, cfg Config) Result {
+	if cfg.BudgetUSD <= 0 {
+		cfg.BudgetUSD = 100
+	}
+	res := Result{Opportunities: make(map[matches.Direction]*matches.Opportunity)}
+	if stricter == nil || looser == nil {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectMissingData, Detail: "missing snapshots"}
+		return res
+	}
+	if reason, stale := checkFreshness(cfg, time.Now().UTC(), stricter, looser); stale {
+		res.Stale = true
+		res.Reason = reason
+		return res
+	}
+
+	noBook, ok := outcomeBook(stricter, false)
+	if !ok {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectUnknownVenue, Venue: stricter.Venue}
+		return res
+	}
+	yesBook, ok := outcomeBook(looser, true)
+	if !ok {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectUnknownVenue, Venue: looser.Venue}
+		return res
+	}
+	if len(noBook.Asks) == 0 {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectNoAsk, Venue: stricter.Venue, Detail: "stricter NO"}
+		return res
+	}
+	if len(yesBook.Asks) == 0 {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectNoAsk, Venue: looser.Venue, Detail: "looser YES"}
+		return res
+	}
+
+	op := simulateImplication(cfg, stricter, looser, noBook, yesBook)
+	res.Sweep = sweepBudgets(cfg, func(c Config) *matches.Opportunity {
+		return simulateImplication(c, stricter, looser, noBook, yesBook)
+	})
+	if op == nil {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectNoProfit}
+		return res
+	}
+	res.Opportunities[op.Direction] = op
+	res.Best = op
+	return res
+}
+
+func simulateImplication(cfg Config, stricter, looser *models.MarketSnapshot, noBook, yesBook collectors.Orderbook) *matches.Opportunity {
+	fees := cfg.feeSchedule()
+	ladders := [][]collectors.OrderbookLevel{noBook.Asks, yesBook.Asks}
+	feeModels := []FeeModel{fees.ModelFor(stricter), fees.ModelFor(looser)}
+
+	w := walkLadders(cfg.BudgetUSD, 0, ladders, feeModels)
+	if w.best.qty <= epsilon {
+		return nil
+	}
+	rule := CombineLots(cfg.lotRule(stricter.Venue), cfg.lotRule(looser.Venue))
+	exec, ok := executableFill(w.best, cfg.BudgetUSD, ladders, feeModels, rule)
+	if !ok {
+		return nil
+	}
+
+	op := newOpportunity(matches.DirectionImplication, cfg.BudgetUSD, w, exec)
+	addLeg(op, stricter.Venue, stricter.Market.MarketID, "no", exec.legs[0], stricter.Market.TickSize)
+	addLeg(op, looser.Venue, looser.Market.MarketID, "yes", exec.legs[1], looser.Market.TickSize)
+	op.PayoutFloorUSD = op.Quantity
+	op.PayoutMaxUSD = 2 * op.Quantity
+	op.PayoutPerUnitUSD = 1
+	applyYield(op, time.Now().UTC(), closeTime(stricter), closeTime(looser))
+	applyScore(cfg, op, ladders, stricter, looser)
+	return op
+}
+
+// outcomeBook returns the YES or NO book of a snapshot on either venue.
+func outcomeBook(snap *models.MarketSnapshot, yes bool) (collectors.Orderbook, bool) {
+	if snap.Venue != collectors.VenuePolymarket && snap.Venue != collectors.VenueKalshi {
+		return collectors.Orderbook{}, false
+	}
+	return venueBook(snap.Venue, &snap.Market, yes), true
+}
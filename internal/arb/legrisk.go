@@ -0,0 +1,348 @@
+package arb
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+// LegRiskConfig parameterises the leg-risk Monte Carlo.
+type LegRiskConfig struct {
+	// Trials is the number of simulated executions (default 2000).
+	Trials int
+	// LegDelay is the time between sending consecutive legs (default 2s).
+	LegDelay time.Duration
+	// ChangeRates is the expected number of best-ask changes per second per
+	// venue, usually from a BookChangeTracker.
+	ChangeRates map[collectors.Venue]float64
+	// Fees prices chasing and unwinding legs; nil uses DefaultFeeSchedule.
+	Fees *FeeSchedule
+	// AdverseMoveProb is the chance a book change moves the ask against us
+	// (default 0.6: edges tend to close, not widen).
+	AdverseMoveProb float64
+	// PartialFillProb is the chance the first leg only partly fills; the
+	// filled fraction is then uniform in (0, 1].
+	PartialFillProb float64
+	// Seed makes runs reproducible; zero seeds from the clock.
+	Seed int64
+}
+
+func (c LegRiskConfig) withDefaults() LegRiskConfig {
+	if c.Trials <= 0 {
+		c.Trials = 2000
+	}
+	if c.LegDelay <= 0 {
+		c.LegDelay = 2 * time.Second
+	}
+	if c.AdverseMoveProb <= 0 {
+		c.AdverseMoveProb = 0.6
+	}
+	if c.Seed == 0 {
+		c.Seed = time.Now().UnixNano()
+	}
+	if c.Fees == nil {
+		fees := DefaultFeeSchedule()
+		c.Fees = &fees
+	}
+	return c
+}
+
+// riskLeg is one order of the plan with what it takes to chase or unwind it.
+// ask is the best ask when the plan was priced, where drift starts.
+type riskLeg struct {
+	venue    collectors.Venue
+	qty      float64
+	avgPrice float64
+	limit    float64
+	fee      float64
+	ask      float64
+	bid      float64
+	tick     float64
+	fees     FeeModel
+}
+
+// SimulateLegRisk replays the opportunity's order plan one leg at a time.
+// The first leg fills immediately (possibly partially); each later leg waits
+// LegDelay per position in the plan, during which its best ask takes a
+// Poisson number of one-tick steps from the current top of book. If the ask
+// ends above the leg's limit the executor either chases at the new ask or
+// sells the legs already filled at the fresh bids, whichever loses less;
+// both pay taker fees. Asks, bids and tick sizes come from fresh.
+func SimulateLegRisk(op *matches.Opportunity, fresh *matches.FreshSnapshots, cfg LegRiskConfig) *matches.LegRisk {
+	if op == nil || len(op.Orders) == 0 || op.Quantity <= epsilon {
+		return nil
+	}
+	cfg = cfg.withDefaults()
+	legs := riskLegs(op, fresh, *cfg.Fees)
+	rng := rand.New(rand.NewSource(cfg.Seed))
+
+	var sum, worst float64
+	losses := 0
+	worst = math.Inf(1)
+	for i := 0; i < cfg.Trials; i++ {
+		profit := simulateLegTrial(rng, op, legs, cfg)
+		sum += profit
+		if profit < worst {
+			worst = profit
+		}
+		if profit < -epsilon {
+			losses++
+		}
+	}
+	return &matches.LegRisk{
+		Trials:            cfg.Trials,
+		LegDelaySeconds:   cfg.LegDelay.Seconds(),
+		ExpectedProfitUSD: sum / float64(cfg.Trials),
+		WorstCaseLossUSD:  math.Max(0, -worst),
+		LossProbability:   float64(losses) / float64(cfg.Trials),
+	}
+}
+
+func simulateLegTrial(rng *rand.Rand, op *matches.Opportunity, legs []riskLeg, cfg LegRiskConfig) float64 {
+	frac := 1.0
+	if rng.Float64() < cfg.PartialFillProb {
+		frac = 1 - rng.Float64()
+	}
+	// Everything is scaled to the quantity the first leg actually got.
+	extra := 0.0
+	for i := 1; i < len(legs); i++ {
+		leg := legs[i]
+		elapsed := cfg.LegDelay.Seconds() * float64(i)
+		steps := poisson(rng, cfg.ChangeRates[leg.venue]*elapsed)
+		drift := 0
+		for s := 0; s < steps; s++ {
+			if rng.Float64() < cfg.AdverseMoveProb {
+				drift++
+			} else {
+				drift--
+			}
+		}
+		ask := leg.ask + float64(drift)*leg.tick
+		if ask <= leg.limit+epsilon {
+			continue
+		}
+		// Chasing buys the whole leg at the new ask instead of the planned
+		// average, and pays that price's fee instead of the planned one.
+		qty := leg.qty * frac
+		chase := (ask-leg.avgPrice)*qty + takerFee(leg.fees, qty, ask) - leg.fee*frac
+		if ask >= 1 {
+			chase = math.Inf(1)
+		}
+		// Unwinding sells what already filled at the bid: the spread, the
+		// buy fee already paid and the sell fee are lost.
+		unwind := 0.0
+		for _, filled := range legs[:i] {
+			q := filled.qty * frac
+			unwind += (filled.avgPrice-filled.bid)*q + filled.fee*frac + takerFee(filled.fees, q, filled.bid)
+		}
+		if unwind < chase {
+			return -extra - unwind
+		}
+		extra += chase
+	}
+	return op.ProfitUSD.Float()*frac - extra
+}
+
+func riskLegs(op *matches.Opportunity, fresh *matches.FreshSnapshots, fees FeeSchedule) []riskLeg {
+	legs := make([]riskLeg, 0, len(op.Orders))
+	for i, o := range op.Orders {
+		venue := collectors.Venue(o.Venue)
+		leg := riskLeg{
+			venue: venue,
+			qty:   o.Quantity,
+			limit: o.LimitPrice.Float(),
+			fee:   o.FeeUSD.Float(),
+			tick:  defaultTick,
+			fees:  fees.modelFor(venue, nil, &collectors.Market{MarketID: o.MarketID}),
+		}
+		leg.avgPrice = o.LimitPrice.Float()
+		if i < len(op.Legs) && op.Legs[i].Quantity > epsilon {
+			leg.avgPrice = op.Legs[i].AvgPrice
+		}
+		leg.ask = leg.avgPrice
+		if snap := freshSnapshot(fresh, venue); snap != nil && snap.Market.MarketID == o.MarketID {
+			if snap.Market.TickSize > 0 {
+				leg.tick = snap.Market.TickSize
+			}
+			book := venueBook(venue, &snap.Market, o.Outcome == "yes")
+			leg.bid = bestBid(book)
+			if ask := bestAsk(book); ask > 0 {
+				leg.ask = ask
+			}
+			leg.fees = fees.ModelFor(snap)
+		}
+		legs = append(legs, leg)
+	}
+	return legs
+}
+
+// takerFee is the venue-rounded taker fee for one order.
+func takerFee(model FeeModel, qty, price float64) float64 {
+	if model == nil || qty <= epsilon {
+		return 0
+	}
+	return model.Round(model.TakerFee(qty, price))
+}
+
+func freshSnapshot(fresh *matches.FreshSnapshots, venue collectors.Venue) *models.MarketSnapshot {
+	if fresh == nil {
+		return nil
+	}
+	switch venue {
+	case collectors.VenuePolymarket:
+		return fresh.Polymarket
+	case collectors.VenueKalshi:
+		return fresh.Kalshi
+	}
+	return nil
+}
+
+func bestBid(book collectors.Orderbook) float64 {
+	var best money.Micros
+	for _, lvl := range book.Bids {
+		if lvl.Quantity > epsilon && lvl.Price > best {
+			best = lvl.Price
+		}
+	}
+	return best.Float()
+}
+
+func bestAsk(book collectors.Orderbook) float64 {
+	var best money.Micros
+	for _, lvl := range book.Asks {
+		if lvl.Quantity > epsilon && (best == 0 || lvl.Price < best) {
+			best = lvl.Price
+		}
+	}
+	return best.Float()
+}
+
+// poisson draws from a Poisson distribution (Knuth; lambda is small here).
+func poisson(rng *rand.Rand, lambda float64) int {
+	if lambda <= 0 {
+		return 0
+	}
+	limit := math.Exp(-lambda)
+	k := 0
+	p := rng.Float64()
+	for p > limit {
+		k++
+		p *= rng.Float64()
+	}
+	return k
+}
+
+// BookChangeTracker estimates how often each venue's best asks change from
+// successive snapshots of the same market. Changes between two observations
+// count once, so rates are a lower bound that tightens as snapshots arrive
+// more often. It is safe for concurrent use.
+type BookChangeTracker struct {
+	mu       sync.Mutex
+	last     map[string]bookObservation
+	changes  map[collectors.Venue]float64
+	seconds  map[collectors.Venue]float64
+	fallback map[collectors.Venue]float64
+	// minSeconds of observed history before the estimate replaces fallback.
+	minSeconds float64
+}
+
+type bookObservation struct {
+	at            time.Time
+	yesAsk, noAsk float64
+}
+
+// NewBookChangeTracker returns a tracker that reports fallback rates until a
+// venue has minHistory of observed time.
+func NewBookChangeTracker(fallback map[collectors.Venue]float64, minHistory time.Duration) *BookChangeTracker {
+	return &BookChangeTracker{
+		last:       make(map[string]bookObservation),
+		changes:    make(map[collectors.Venue]float64),
+		seconds:    make(map[collectors.Venue]float64),
+		fallback:   fallback,
+		minSeconds: minHistory.Seconds(),
+	}
+}
+
+// Observe records a snapshot's top of book.
+func (t *BookChangeTracker) Observe(snap *models.MarketSnapshot) {
+	if t == nil || snap == nil || snap.CapturedAt.IsZero() {
+		return
+	}
+	key := string(snap.Venue) + ":" + snap.Market.MarketID
+	obs := bookObservation{at: snap.CapturedAt, yesAsk: snap.Market.Price.YesAsk, noAsk: snap.Market.Price.NoAsk}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	prev, ok := t.last[key]
+	if ok && !obs.at.After(prev.at) {
+		return
+	}
+	t.last[key] = obs
+	if !ok {
+		return
+	}
+	t.seconds[snap.Venue] += obs.at.Sub(prev.at).Seconds()
+	if math.Abs(obs.yesAsk-prev.yesAsk) > epsilon || math.Abs(obs.noAsk-prev.noAsk) > epsilon {
+		t.changes[snap.Venue]++
+	}
+}
+
+// BookChangeHistory is what a BookChangeTracker has observed on one venue:
+// best-ask changes over seconds of snapshot history.
+type BookChangeHistory struct {
+	Changes float64
+	Seconds float64
+}
+
+// History returns the observed totals for every venue, for persisting.
+func (t *BookChangeTracker) History() map[collectors.Venue]BookChangeHistory {
+	out := make(map[collectors.Venue]BookChangeHistory)
+	if t == nil {
+		return out
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for v, secs := range t.seconds {
+		out[v] = BookChangeHistory{Changes: t.changes[v], Seconds: secs}
+	}
+	return out
+}
+
+// Restore adds previously persisted totals, so rates survive restarts
+// instead of falling back until minHistory has been observed again.
+func (t *BookChangeTracker) Restore(history map[collectors.Venue]BookChangeHistory) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for v, h := range history {
+		t.changes[v] += h.Changes
+		t.seconds[v] += h.Seconds
+	}
+}
+
+// Rates returns the current changes-per-second estimate for every venue.
+func (t *BookChangeTracker) Rates() map[collectors.Venue]float64 {
+	out := make(map[collectors.Venue]float64)
+	if t == nil {
+		return out
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for v, r := range t.fallback {
+		out[v] = r
+	}
+	for v, secs := range t.seconds {
+		if secs >= t.minSeconds && secs > 0 {
+			out[v] = t.changes[v] / secs
+		}
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package arb
+
+import (
+	"math"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+// defaultTick is used when a market does not report its tick size.
+const defaultTick = 0.01
+
+// LotRule describes a venue's order size constraints.
+type LotRule struct {
+	LotSize float64 // quantity increment; 0 means continuous
+	MinQty  float64 // minimum contracts per order
+}
+
+// DefaultLotRules: Kalshi trades whole contracts, Polymarket accepts sizes in
+// hundredths of a share with a 5-share minimum order.
+func DefaultLotRules() map[collectors.Venue]LotRule {
+	return map[collectors.Venue]LotRule{
+		collectors.VenueKalshi:     {LotSize: 1, MinQty: 1},
+		collectors.VenuePolymarket: {LotSize: 0.01, MinQty: 5},
+	}
+}
+
+// Floor rounds qty down to a whole number of lots.
+func (r LotRule) Floor(qty float64) float64 {
+	if r.LotSize <= 0 {
+		return qty
+	}
+	return math.Floor(qty/r.LotSize+epsilon) * r.LotSize
+}
+
+// CombineLots returns the rule every leg can satisfy at once: the coarsest
+// lot size and the largest minimum.
+func CombineLots(rules ...LotRule) LotRule {
+	var out LotRule
+	for _, r := range rules {
+		out.LotSize = math.Max(out.LotSize, r.LotSize)
+		out.MinQty = math.Max(out.MinQty, r.MinQty)
+	}
+	return out
+}
+
+// roundUpToTick and roundDownToTick snap an exact price to the market's
+// tick grid.
+func roundUpToTick(price money.Micros, tick float64) money.Micros {
+	if tick <= 0 {
+		tick = defaultTick
+	}
+	return price.CeilTo(money.FromFloat(tick))
+}
+
+func roundDownToTick(price money.Micros, tick float64) money.Micros {
+	if tick <= 0 {
+		tick = defaultTick
+	}
+	return price.FloorTo(money.FromFloat(tick))
+}
@@ -0,0 +1,95 @@
+package arb
+
+import (
+	"math"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+// OrderFill is the simulated result of one order against a snapshot: the
+// quantity that traded, what it cost (or returned, for sells) before fees,
+// and the venue-rounded fee.
+type OrderFill struct {
+	Quantity float64
+	CostUSD  money.Micros
+	FeeUSD   money.Micros
+}
+
+// AvgPrice is the volume-weighted price of the fill.
+func (f OrderFill) AvgPrice() float64 {
+	if f.Quantity <= epsilon {
+		return 0
+	}
+	return f.CostUSD.Float() / f.Quantity
+}
+
+// SimulateBuy takes a buy limit order against the ask ladder of its outcome,
+// best price first, crossing levels up to the limit price. Whatever the book
+// cannot supply at or under the limit is left unfilled.
+func SimulateBuy(order matches.Order, snap *models.MarketSnapshot, fees FeeSchedule) OrderFill {
+	if snap == nil || order.Quantity <= epsilon {
+		return OrderFill{}
+	}
+	book, ok := outcomeBook(snap, order.Outcome == "yes")
+	if !ok {
+		return OrderFill{}
+	}
+	lf := legFill{fees: fees.ModelFor(snap)}
+	it := newAskIterator(book.Asks)
+	for lf.qty < order.Quantity-epsilon {
+		price := it.peekPrice()
+		q := math.Min(it.peekQty(), order.Quantity-lf.qty)
+		if q <= epsilon || (order.LimitPrice > 0 && price > order.LimitPrice) {
+			break
+		}
+		cost, took := it.take(q)
+		if !took {
+			break
+		}
+		lf.qty += q
+		lf.cost += cost
+		lf.rawFee += lf.fees.TakerFee(q, price.Float())
+	}
+	return OrderFill{Quantity: lf.qty, CostUSD: lf.cost, FeeUSD: lf.fee()}
+}
+
+// SimulateSell sells an order's quantity of its outcome into the bid
+// ladder, highest price first, down to the limit price (no floor when the
+// limit is zero). CostUSD holds the gross proceeds; quantity the bids cannot
+// absorb is left unsold.
+func SimulateSell(order matches.Order, snap *models.MarketSnapshot, fees FeeSchedule) OrderFill {
+	qty := order.Quantity
+	if snap == nil || qty <= epsilon {
+		return OrderFill{}
+	}
+	book, ok := outcomeBook(snap, order.Outcome == "yes")
+	if !ok {
+		return OrderFill{}
+	}
+	l := exitLeg{fees: fees.ModelFor(snap)}
+	it := newBidIterator(book.Bids)
+	for l.qty < qty-epsilon {
+		price := it.peekPrice()
+		q := math.Min(it.peekQty(), qty-l.qty)
+		if q <= epsilon || (order.LimitPrice > 0 && price < order.LimitPrice) {
+			break
+		}
+		proceeds, took := it.take(q)
+		if !took {
+			break
+		}
+		l.qty += q
+		l.proceeds += proceeds
+		l.rawFee += l.fees.TakerFee(q, price.Float())
+	}
+	return OrderFill{Quantity: l.qty, CostUSD: l.proceeds, FeeUSD: l.fee()}
+}
+
+// CloseTime is the settlement time of a snapshot's market, falling back to
+// its event's close time.
+func CloseTime(snap *models.MarketSnapshot) time.Time {
+	return closeTime(snap)
+}
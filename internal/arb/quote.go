@@ -0,0 +1,207 @@
+package arb
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+// QuoteConfig controls the maker-taker quote strategy.
+type QuoteConfig struct {
+	// TargetEdgeUSD is the profit per contract a filled quote must lock in
+	// after the hedge and every fee (default 0.01).
+	TargetEdgeUSD float64
+	// BudgetUSD caps the capital of a fully filled quote plus its hedge
+	// (default 100).
+	BudgetUSD float64
+	// MaxQuantity caps the quoted size; zero leaves only the budget.
+	MaxQuantity float64
+	// Fees selects per-venue fee models; nil uses DefaultFeeSchedule.
+	Fees *FeeSchedule
+	// Lots overrides per-venue lot sizes; missing venues use DefaultLotRules.
+	Lots map[collectors.Venue]LotRule
+	// MaxSnapshotAge and MaxSnapshotSkew bound how old each leg's snapshot
+	// may be and how far apart the legs were captured. Zero disables.
+	MaxSnapshotAge  time.Duration
+	MaxSnapshotSkew time.Duration
+}
+
+func (c QuoteConfig) base() Config {
+	budget := c.BudgetUSD
+	if budget <= 0 {
+		budget = 100
+	}
+	return Config{BudgetUSD: budget, Fees: c.Fees, Lots: c.Lots, MaxSnapshotAge: c.MaxSnapshotAge, MaxSnapshotSkew: c.MaxSnapshotSkew}
+}
+
+func (c QuoteConfig) targetEdge() money.Micros {
+	if c.TargetEdgeUSD <= 0 {
+		return money.Cent
+	}
+	return money.FromFloat(c.TargetEdgeUSD)
+}
+
+// ComputeQuotes prices resting quotes for a matched pair when taking both
+// legs does not pay. For every hedged direction (per the verdict's outcome
+// mapping) and each choice of maker venue, it walks the other venue's ask
+// ladder and finds the highest post-only bid that still leaves
+// TargetEdgeUSD per contract after the hedge and fees. Viable quotes are
+// returned best first: quotes that improve the maker's best bid, then by
+// locked edge. PairID is left for the caller to set.
+func ComputeQuotes(pmSnap, kxSnap *models.MarketSnapshot, verdict *matches.ResolutionVerdict, cfg QuoteConfig, now time.Time) ([]*matches.Quote, Reject) {
+	if pmSnap == nil || kxSnap == nil {
+		return nil, Reject{Code: RejectMissingData, Detail: "missing snapshots"}
+	}
+	base := cfg.base()
+	if reason, stale := checkFreshness(base, now, pmSnap, kxSnap); stale {
+		return nil, reason
+	}
+
+	var out []*matches.Quote
+	for _, dir := range pairDirections(base, verdict) {
+		pmYes, kxYes, _ := directionOutcomes(dir)
+		if q := quoteDirection(base, cfg.targetEdge(), cfg.MaxQuantity, dir, pmSnap, pmYes, kxSnap, kxYes, now); q != nil {
+			out = append(out, q)
+		}
+		if q := quoteDirection(base, cfg.targetEdge(), cfg.MaxQuantity, dir, kxSnap, kxYes, pmSnap, pmYes, now); q != nil {
+			out = append(out, q)
+		}
+	}
+	if len(out) == 0 {
+		return nil, Reject{Code: RejectNoProfit, Detail: "no quote locks in the target edge"}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].AtTop() != out[j].AtTop() {
+			return out[i].AtTop()
+		}
+		return out[i].EdgeUSD > out[j].EdgeUSD
+	})
+	return out, Reject{}
+}
+
+// quoteDirection prices one maker venue for one direction. The hedge ladder
+// is tried at each slice end of its walk, largest first, so the quote is as
+// big as the target edge and budget allow.
+func quoteDirection(cfg Config, edge money.Micros, maxQty float64, dir matches.Direction, maker *models.MarketSnapshot, makerYes bool, hedge *models.MarketSnapshot, hedgeYes bool, now time.Time) *matches.Quote {
+	makerBook := venueBook(maker.Venue, &maker.Market, makerYes)
+	hedgeBook := venueBook(hedge.Venue, &hedge.Market, hedgeYes)
+	if len(hedgeBook.Asks) == 0 {
+		return nil
+	}
+	schedule := cfg.feeSchedule()
+	makerFees := schedule.ModelFor(maker)
+	hedgeLadder := [][]collectors.OrderbookLevel{hedgeBook.Asks}
+	hedgeFees := []FeeModel{schedule.ModelFor(hedge)}
+	rule := CombineLots(cfg.lotRule(maker.Venue), cfg.lotRule(hedge.Venue))
+	bid, ask := topOfBook(makerBook)
+	tick := tickSize(maker.Market.TickSize)
+
+	w := walkLadders(cfg.BudgetUSD, maxQty, hedgeLadder, hedgeFees)
+	for i := len(w.curve) - 1; i >= 0; i-- {
+		qty := rule.Floor(w.curve[i].Quantity)
+		// Shrink until the maker leg and hedge fit the budget together.
+		for attempt := 0; attempt < 4 && qty > epsilon && qty+epsilon >= rule.MinQty; attempt++ {
+			h := walkLadders(cfg.BudgetUSD, qty, hedgeLadder, hedgeFees).last
+			if h.qty+epsilon < qty {
+				break
+			}
+			room := (money.Dollar - edge).Mul(qty) - h.totalCost()
+			price, fee := makerPrice(room, qty, tick, bid, ask, makerFees)
+			if price <= 0 {
+				break
+			}
+			total := price.Mul(qty) + fee + h.totalCost()
+			if total.Float() > cfg.BudgetUSD+epsilon {
+				qty = rule.Floor(qty * cfg.BudgetUSD / total.Float())
+				continue
+			}
+			return newQuote(dir, maker, makerYes, hedge, hedgeYes, qty, price, fee, h, bid, ask, now)
+		}
+	}
+	return nil
+}
+
+// makerPrice is the highest tick-aligned bid whose cost plus maker fee for
+// qty fits in room. It stays a tick under the maker's best ask so the order
+// rests instead of crossing, and no more than a tick over the best bid:
+// paying more than that buys no queue priority and only gives up edge.
+func makerPrice(room money.Micros, qty float64, tick, bestBid, bestAsk money.Micros, fees FeeModel) (money.Micros, money.Micros) {
+	if room <= 0 || qty <= epsilon {
+		return 0, 0
+	}
+	price := room.Div(qty).FloorTo(tick)
+	if bestBid > 0 && price > bestBid+tick {
+		price = bestBid + tick
+	}
+	if bestAsk > 0 && price >= bestAsk {
+		price = bestAsk - tick
+	}
+	for ; price > 0; price -= tick {
+		fee := money.FromFloat(fees.Round(fees.MakerFee(qty, price.Float())))
+		if price.Mul(qty)+fee <= room {
+			return price, fee
+		}
+	}
+	return 0, 0
+}
+
+func newQuote(dir matches.Direction, maker *models.MarketSnapshot, makerYes bool, hedge *models.MarketSnapshot, hedgeYes bool, qty float64, price, fee money.Micros, h fill, bid, ask money.Micros, now time.Time) *matches.Quote {
+	hedgeLeg := matches.Leg{
+		Venue:    string(hedge.Venue),
+		MarketID: hedge.Market.MarketID,
+		Side:     "buy",
+		Outcome:  outcomeName(hedgeYes),
+	}
+	hedgeOrder := h.legs[0].order(hedgeLeg, hedge.Market.TickSize)
+	total := price.Mul(qty) + fee + h.totalCost()
+	return &matches.Quote{
+		Status:     matches.QuotePost,
+		Direction:  dir,
+		MakerVenue: string(maker.Venue),
+		Quantity:   qty,
+		Maker: &matches.Order{
+			Venue:      string(maker.Venue),
+			MarketID:   maker.Market.MarketID,
+			Side:       "buy",
+			Outcome:    outcomeName(makerYes),
+			Type:       "post_only",
+			LimitPrice: price,
+			Quantity:   qty,
+			FeeUSD:     fee,
+		},
+		Hedge:        &hedgeOrder,
+		HedgeCostUSD: h.totalCost(),
+		TotalCostUSD: total,
+		EdgeUSD:      money.Dollar.Mul(qty) - total,
+		MakerBestBid: bid,
+		MakerBestAsk: ask,
+		GeneratedAt:  now,
+	}
+}
+
+// topOfBook returns the best bid and ask with resting size; zero when a
+// side is empty.
+func topOfBook(book collectors.Orderbook) (bid, ask money.Micros) {
+	for _, lvl := range book.Bids {
+		if lvl.Quantity > epsilon && lvl.Price > bid {
+			bid = lvl.Price
+		}
+	}
+	for _, lvl := range book.Asks {
+		if lvl.Quantity > epsilon && (ask == 0 || lvl.Price < ask) {
+			ask = lvl.Price
+		}
+	}
+	return bid, ask
+}
+
+func tickSize(tick float64) money.Micros {
+	if tick <= 0 {
+		tick = defaultTick
+	}
+	return money.FromFloat(tick)
+}
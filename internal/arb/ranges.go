@@ -0,0 +1,189 @@
+package arb
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+)
+
+// RangeConfig controls the range-bucket synthesizer.
+type RangeConfig struct {
+	BudgetUSD float64
+	// Step is the resolution granularity of the underlying quantity (e.g. 1
+	// for whole degrees, 0.1 for CPI). Zero treats it as continuous; see
+	// parseBucket.
+	Step float64
+	// Fees selects per-venue fee models; nil uses DefaultFeeSchedule.
+	Fees *FeeSchedule
+	// Lots overrides per-venue lot sizes; missing venues use DefaultLotRules.
+	Lots map[collectors.Venue]LotRule
+	// RankBy picks Result.Best; defaults to profit.
+	RankBy matches.RankMetric
+	// Scoring weighs Opportunity.Score; nil uses the default weights.
+	Scoring *matches.ScoreWeights
+}
+
+// EvaluateRanges looks for synthetic equivalents between two range-bucket
+// families on the same underlying quantity (e.g. Kalshi temperature bands vs
+// Polymarket bands with different edges). For every bucket on one venue that
+// is tiled exactly by adjacent buckets on the other, it prices buying YES on
+// the tiling buckets plus NO on the covered bucket; exactly one leg pays $1
+// in every outcome. All profitable combinations are returned in
+// Result.Alternatives, best first, each with a coverage proof.
+func EvaluateRanges(pmEvent, kxEvent *collectors.Event, cfg RangeConfig) Result {
+	if cfg.BudgetUSD <= 0 {
+		cfg.BudgetUSD = 100
+	}
+	res := Result{Opportunities: make(map[matches.Direction]*matches.Opportunity)}
+	if pmEvent == nil || kxEvent == nil || len(pmEvent.Markets) == 0 || len(kxEvent.Markets) == 0 {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectMissingData, Detail: "missing events"}
+		return res
+	}
+	if pmEvent.Venue != collectors.VenuePolymarket || kxEvent.Venue != collectors.VenueKalshi {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectUnknownVenue, Detail: "unexpected event venues"}
+		return res
+	}
+
+	pmBuckets := parseBuckets(pmEvent, cfg.Step)
+	kxBuckets := parseBuckets(kxEvent, cfg.Step)
+	if len(pmBuckets) == 0 || len(kxBuckets) == 0 {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectOutcomeMapping, Detail: "no parseable range buckets"}
+		return res
+	}
+
+	base := Config{BudgetUSD: cfg.BudgetUSD, Fees: cfg.Fees, Lots: cfg.Lots, Scoring: cfg.Scoring}
+	var found []*matches.Opportunity
+	for _, side := range []struct {
+		yesEvent, noEvent     *collectors.Event
+		yesBuckets, noBuckets []bucket
+	}{
+		{kxEvent, pmEvent, kxBuckets, pmBuckets},
+		{pmEvent, kxEvent, pmBuckets, kxBuckets},
+	} {
+		for _, target := range side.noBuckets {
+			pieces, ok := tile(target, side.yesBuckets)
+			if !ok {
+				continue
+			}
+			if op := simulateRange(base, side.yesEvent, side.noEvent, pieces, target); op != nil {
+				found = append(found, op)
+			}
+		}
+	}
+	if len(found) == 0 {
+		res.Untradable = true
+		res.Reason = Reject{Code: RejectNoProfit}
+		return res
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return cfg.RankBy.Score(found[i]) > cfg.RankBy.Score(found[j])
+	})
+	res.Best = found[0]
+	res.Opportunities[res.Best.Direction] = res.Best
+	res.Alternatives = found
+	return res
+}
+
+// parseBuckets keeps the markets of an event whose range could be parsed.
+func parseBuckets(ev *collectors.Event, step float64) []bucket {
+	out := make([]bucket, 0, len(ev.Markets))
+	for i := range ev.Markets {
+		if b, ok := parseBucket(&ev.Markets[i], step); ok {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// tile returns the buckets lying inside target when they cover it exactly:
+// sorted, each one starting where the previous ends, together spanning
+// target's bounds. A shared edge must belong to exactly one of the two
+// buckets meeting there, and the outer edges must include or exclude their
+// value the way target does. Gaps or overlaps, even at a single boundary
+// value, fail the proof.
+func tile(target bucket, candidates []bucket) ([]bucket, bool) {
+	var pieces []bucket
+	for _, b := range candidates {
+		if target.contains(b) {
+			pieces = append(pieces, b)
+		}
+	}
+	if len(pieces) == 0 {
+		return nil, false
+	}
+	sort.Slice(pieces, func(i, j int) bool { return pieces[i].lo < pieces[j].lo })
+	first, last := pieces[0], pieces[len(pieces)-1]
+	if !sameBound(first.lo, target.lo) || first.loOpen != target.loOpen {
+		return nil, false
+	}
+	if !sameBound(last.hi, target.hi) || last.hiClosed != target.hiClosed {
+		return nil, false
+	}
+	for i := 1; i < len(pieces); i++ {
+		prev, cur := pieces[i-1], pieces[i]
+		// [a, b) meets [b, c) and [a, b] meets (b, c); anything else
+		// leaves b uncovered or covered twice.
+		if !sameBound(prev.hi, cur.lo) || prev.hiClosed != cur.loOpen {
+			return nil, false
+		}
+	}
+	return pieces, true
+}
+
+func sameBound(a, b float64) bool {
+	if math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return a == b
+	}
+	return math.Abs(a-b) <= 1e-6
+}
+
+func simulateRange(cfg Config, yesEvent, noEvent *collectors.Event, pieces []bucket, target bucket) *matches.Opportunity {
+	schedule := cfg.feeSchedule()
+	ladders := make([][]collectors.OrderbookLevel, 0, len(pieces)+1)
+	feeModels := make([]FeeModel, 0, len(pieces)+1)
+	closes := make([]time.Time, 0, len(pieces)+1)
+	for _, p := range pieces {
+		book := venueBook(yesEvent.Venue, p.market, true)
+		if len(book.Asks) == 0 {
+			return nil
+		}
+		ladders = append(ladders, book.Asks)
+		feeModels = append(feeModels, schedule.modelFor(yesEvent.Venue, yesEvent, p.market))
+		closes = append(closes, marketCloseTime(yesEvent, p.market))
+	}
+	noBook := venueBook(noEvent.Venue, target.market, false)
+	if len(noBook.Asks) == 0 {
+		return nil
+	}
+	ladders = append(ladders, noBook.Asks)
+	feeModels = append(feeModels, schedule.modelFor(noEvent.Venue, noEvent, target.market))
+	closes = append(closes, marketCloseTime(noEvent, target.market))
+	rule := CombineLots(cfg.lotRule(yesEvent.Venue), cfg.lotRule(noEvent.Venue))
+
+	w := walkLadders(cfg.BudgetUSD, 0, ladders, feeModels)
+	if w.best.qty <= epsilon {
+		return nil
+	}
+	exec, ok := executableFill(w.best, cfg.BudgetUSD, ladders, feeModels, rule)
+	if !ok {
+		return nil
+	}
+
+	op := newOpportunity(matches.DirectionRangeSynthetic, cfg.BudgetUSD, w, exec)
+	intervals := make([]matches.Interval, len(pieces))
+	for i, p := range pieces {
+		addLeg(op, yesEvent.Venue, p.market.MarketID, "yes", exec.legs[i], p.market.TickSize)
+		intervals[i] = p.interval()
+	}
+	addLeg(op, noEvent.Venue, target.market.MarketID, "no", exec.legs[len(pieces)], target.market.TickSize)
+	op.Coverage = matches.NewCoverageProof(target.interval(), intervals)
+	applyYield(op, time.Now().UTC(), closes...)
+	applyScore(cfg, op, ladders)
+	return op
+}
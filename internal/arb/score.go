@@ -0,0 +1,75 @@
+package arb
+
+import (
+	"math"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+)
+
+// matchContext carries the matcher similarity and validator confidence of
+// a cross-venue pair into scoring. Evaluators without a matcher or LLM step
+// (boxes, implications, ranges, baskets) leave it unset and score those
+// components as certain.
+type matchContext struct {
+	set        bool
+	similarity float64
+	confidence float64
+}
+
+func newMatchContext(match *matches.Payload) matchContext {
+	ctx := matchContext{set: true, similarity: match.Similarity}
+	if match.ResolutionVerdict != nil {
+		ctx.confidence = match.ResolutionVerdict.Confidence
+	}
+	return ctx
+}
+
+func (c Config) scoreWeights() matches.ScoreWeights {
+	if c.Scoring == nil {
+		return matches.DefaultScoreWeights()
+	}
+	return *c.Scoring
+}
+
+// applyScore sets op.Score from the opportunity, the ladders it was walked
+// on and the snapshots they came from (nil for event-level evaluators).
+func applyScore(cfg Config, op *matches.Opportunity, ladders [][]collectors.OrderbookLevel, snaps ...*models.MarketSnapshot) {
+	if op == nil {
+		return
+	}
+	now := time.Now().UTC()
+	in := matches.ScoreInputs{Similarity: 1, Confidence: 1, Now: now}
+	if cfg.match.set {
+		in.Similarity = cfg.match.similarity
+		in.Confidence = cfg.match.confidence
+	}
+	for _, snap := range snaps {
+		if snap == nil || snap.CapturedAt.IsZero() {
+			continue
+		}
+		if age := now.Sub(snap.CapturedAt); age > in.SnapshotAge {
+			in.SnapshotAge = age
+		}
+	}
+	in.DepthContracts = minDepth(ladders)
+	op.Score = cfg.scoreWeights().Score(op, in)
+}
+
+// minDepth is the smallest total visible quantity across the ladders.
+func minDepth(ladders [][]collectors.OrderbookLevel) float64 {
+	if len(ladders) == 0 {
+		return 0
+	}
+	out := math.Inf(1)
+	for _, levels := range ladders {
+		total := 0.0
+		for _, lvl := range levels {
+			total += lvl.Quantity
+		}
+		out = math.Min(out, total)
+	}
+	return out
+}
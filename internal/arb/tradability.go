@@ -0,0 +1,163 @@
+package arb
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+)
+
+// TradabilityRule holds the top-of-book thresholds used to discard quotes
+// that are not realistically executable. Prices are in [0,1]; a zero
+// threshold disables its check.
+type TradabilityRule struct {
+	// MaxSpread rejects a side whose ask-bid spread exceeds it.
+	MaxSpread float64 `json:"max_spread"`
+	// DustBid and DustAsk reject penny bids paired with an ask at or above
+	// DustAsk.
+	DustBid float64 `json:"dust_bid"`
+	DustAsk float64 `json:"dust_ask"`
+	// LowAsk and LowSpread reject asks at or below LowAsk whose spread is at
+	// least LowSpread.
+	LowAsk    float64 `json:"low_ask"`
+	LowSpread float64 `json:"low_spread"`
+}
+
+// TradabilityOverride changes some thresholds of a TradabilityRule. A nil
+// field keeps the value it overrides; an explicit 0 disables the check.
+type TradabilityOverride struct {
+	MaxSpread *float64 `json:"max_spread,omitempty"`
+	DustBid   *float64 `json:"dust_bid,omitempty"`
+	DustAsk   *float64 `json:"dust_ask,omitempty"`
+	LowAsk    *float64 `json:"low_ask,omitempty"`
+	LowSpread *float64 `json:"low_spread,omitempty"`
+}
+
+// apply returns r with the thresholds set in o replaced.
+func (o TradabilityOverride) apply(r TradabilityRule) TradabilityRule {
+	for _, f := range []struct {
+		dst *float64
+		src *float64
+	}{
+		{&r.MaxSpread, o.MaxSpread},
+		{&r.DustBid, o.DustBid},
+		{&r.DustAsk, o.DustAsk},
+		{&r.LowAsk, o.LowAsk},
+		{&r.LowSpread, o.LowSpread},
+	} {
+		if f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+	return r
+}
+
+// TradabilityPolicy selects a TradabilityRule per snapshot. The venue
+// override is merged onto the default and the event category override onto
+// that, field by field, so an override only changes the thresholds it sets.
+type TradabilityPolicy struct {
+	Default    TradabilityRule                          `json:"default"`
+	Venues     map[collectors.Venue]TradabilityOverride `json:"venues,omitempty"`
+	Categories map[string]TradabilityOverride           `json:"categories,omitempty"`
+}
+
+// DefaultTradabilityPolicy keeps the original conservative thresholds: pass
+// unless the book is basically useless.
+func DefaultTradabilityPolicy() TradabilityPolicy {
+	return TradabilityPolicy{
+		Default: TradabilityRule{
+			MaxSpread: 0.05,
+			DustBid:   0.01,
+			DustAsk:   0.03,
+			LowAsk:    0.05,
+			LowSpread: 0.02,
+		},
+	}
+}
+
+// LoadTradabilityPolicy reads a JSON policy from path. An empty path returns
+// the default policy.
+func LoadTradabilityPolicy(path string) (TradabilityPolicy, error) {
+	if path == "" {
+		return DefaultTradabilityPolicy(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return TradabilityPolicy{}, fmt.Errorf("read tradability policy: %w", err)
+	}
+	policy := DefaultTradabilityPolicy()
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return TradabilityPolicy{}, fmt.Errorf("parse tradability policy: %w", err)
+	}
+	return policy, nil
+}
+
+// RuleFor returns the rule that applies to the snapshot.
+func (p TradabilityPolicy) RuleFor(snap *models.MarketSnapshot) TradabilityRule {
+	rule := p.Default
+	if snap == nil {
+		return rule
+	}
+	if o, ok := p.Venues[snap.Venue]; ok {
+		rule = o.apply(rule)
+	}
+	if o, ok := p.Categories[snap.Event.Category]; ok && snap.Event.Category != "" {
+		rule = o.apply(rule)
+	}
+	return rule
+}
+
+// checkSide returns the code of the first rule that makes one side (YES or
+// NO) untradable, or "" when the side passes.
+func (r TradabilityRule) checkSide(bid, ask float64) RejectCode {
+	// Missing / non-executable quotes.
+	if ask <= epsilon || bid < 0.0 || ask < 0.0 {
+		return RejectNoAsk
+	}
+	// A missing bid usually signals an empty book.
+	if bid <= epsilon {
+		return RejectNoBid
+	}
+	spread := ask - bid
+	if spread < 0 {
+		// Crossed/locked book: data is suspect; treat as bad for safety.
+		return RejectCrossedBook
+	}
+	if r.MaxSpread > 0 && spread > r.MaxSpread {
+		return RejectWideSpread
+	}
+	if r.DustBid > 0 && bid <= r.DustBid && ask >= r.DustAsk {
+		return RejectDustBid
+	}
+	if r.LowAsk > 0 && ask <= r.LowAsk && spread >= r.LowSpread {
+		return RejectLowAsk
+	}
+	return ""
+}
+
+// checkTradability fails a venue only when BOTH of its sides are bad,
+// meaning neither YES nor NO can sensibly be traded there.
+func checkTradability(policy TradabilityPolicy, pm, kx *models.MarketSnapshot) (Reject, bool) {
+	for _, snap := range []*models.MarketSnapshot{pm, kx} {
+		price := snap.Market.Price
+		if price.YesAsk <= epsilon && price.NoAsk <= epsilon {
+			return Reject{Code: RejectZeroLiquidity, Venue: snap.Venue}, true
+		}
+	}
+	for _, snap := range []*models.MarketSnapshot{pm, kx} {
+		rule := policy.RuleFor(snap)
+		price := snap.Market.Price
+		yes := rule.checkSide(price.YesBid, price.YesAsk)
+		no := rule.checkSide(price.NoBid, price.NoAsk)
+		if yes != "" && no != "" {
+			return Reject{
+				Code:   yes,
+				Venue:  snap.Venue,
+				Detail: fmt.Sprintf("yes=%s no=%s", yes, no),
+			}, true
+		}
+	}
+	return Reject{}, false
+}
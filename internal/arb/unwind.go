@@ -0,0 +1,278 @@
+package arb
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+// UnwindConfig controls the early-exit evaluator.
+type UnwindConfig struct {
+	// DiscountRate is the annual rate used to discount the settlement payout
+	// (default 0.05). Money locked until settlement is worth less today.
+	DiscountRate float64
+	// MinEdgeUSD is how much the exit must beat holding by before a signal
+	// is raised.
+	MinEdgeUSD float64
+	// Fees selects per-venue fee models; nil uses DefaultFeeSchedule.
+	Fees *FeeSchedule
+	// Lots overrides per-venue lot sizes; missing venues use DefaultLotRules.
+	Lots map[collectors.Venue]LotRule
+	// MaxSnapshotAge and MaxSnapshotSkew bound how old each leg's snapshot
+	// may be and how far apart the legs were captured. Zero disables.
+	MaxSnapshotAge  time.Duration
+	MaxSnapshotSkew time.Duration
+}
+
+func (c UnwindConfig) base() Config {
+	return Config{Fees: c.Fees, Lots: c.Lots, MaxSnapshotAge: c.MaxSnapshotAge, MaxSnapshotSkew: c.MaxSnapshotSkew}
+}
+
+// EvaluateUnwind prices selling a held hedged position into the bid ladders.
+// snaps holds one fresh snapshot per position leg, in leg order. Complete
+// sets are sold one price slice at a time while the marginal proceeds net
+// of fees beat the discounted settlement payout; the most valuable prefix is
+// rounded to lot size and returned as an exit signal. A nil signal comes
+// with the reason the position should be held.
+func EvaluateUnwind(pos matches.Position, snaps []*models.MarketSnapshot, cfg UnwindConfig, now time.Time) (*matches.ExitSignal, Reject) {
+	if len(pos.Legs) < 2 || len(snaps) != len(pos.Legs) {
+		return nil, Reject{Code: RejectMissingData, Detail: "snapshot per leg required"}
+	}
+	for i, snap := range snaps {
+		if snap == nil {
+			return nil, Reject{Code: RejectMissingData, Venue: pos.Legs[i].Venue}
+		}
+	}
+	base := cfg.base()
+	if reason, stale := checkFreshness(base, now, snaps...); stale {
+		return nil, reason
+	}
+
+	held := pos.HedgedQuantity()
+	if held <= epsilon {
+		return nil, Reject{Code: RejectZeroLiquidity, Detail: "no hedged quantity held"}
+	}
+	schedule := base.feeSchedule()
+	ladders := make([][]collectors.OrderbookLevel, len(snaps))
+	feeModels := make([]FeeModel, len(snaps))
+	rules := make([]LotRule, len(snaps))
+	closes := make([]time.Time, len(snaps))
+	for i, snap := range snaps {
+		leg := pos.Legs[i]
+		book, ok := outcomeBook(snap, leg.Outcome == "yes")
+		if !ok {
+			return nil, Reject{Code: RejectUnknownVenue, Venue: snap.Venue}
+		}
+		if len(book.Bids) == 0 {
+			return nil, Reject{Code: RejectNoBid, Venue: snap.Venue, Detail: leg.MarketID + " " + leg.Outcome}
+		}
+		ladders[i] = book.Bids
+		feeModels[i] = schedule.ModelFor(snap)
+		rules[i] = base.lotRule(snap.Venue)
+		closes[i] = closeTime(snap)
+	}
+
+	settles := latest(closes)
+	payout := pos.PayoutPerUnit
+	if payout <= 0 {
+		payout = 1
+	}
+	holdValue := payout * discountFactor(cfg.discountRate(), now, settles)
+
+	best := walkBids(held, holdValue, ladders, feeModels)
+	if best.qty <= epsilon {
+		return nil, Reject{Code: RejectNoProfit, Detail: fmt.Sprintf("bids below hold value %.4f", holdValue)}
+	}
+	qty := CombineLots(rules...).Floor(best.qty)
+	if qty <= epsilon || qty+epsilon < CombineLots(rules...).MinQty {
+		return nil, Reject{Code: RejectNoProfit, Detail: "exit below minimum order size"}
+	}
+	exit := walkBids(qty, math.Inf(-1), ladders, feeModels)
+	hold := money.FromFloat(holdValue * exit.qty)
+	edge := exit.proceeds() - hold
+	if exit.qty+epsilon < qty || edge.Float() <= cfg.MinEdgeUSD {
+		return nil, Reject{Code: RejectNoProfit, Detail: fmt.Sprintf("exit edge %.4f", edge)}
+	}
+
+	sig := &matches.ExitSignal{
+		PairID:       pos.PairID,
+		Quantity:     exit.qty,
+		ProceedsUSD:  exit.proceeds(),
+		FeesUSD:      exit.fees(),
+		HoldValueUSD: hold,
+		EdgeUSD:      edge,
+		SettlesAt:    settles,
+		GeneratedAt:  now,
+	}
+	if pos.EntryCostUSD > 0 {
+		sig.RealizedProfitUSD = sig.ProceedsUSD - pos.EntryCostUSD.Mul(exit.qty/held)
+	}
+	for i, snap := range snaps {
+		leg := pos.Legs[i]
+		lf := exit.legs[i]
+		sold := matches.Leg{
+			Venue:    string(leg.Venue),
+			MarketID: leg.MarketID,
+			Side:     "sell",
+			Outcome:  leg.Outcome,
+			Quantity: lf.qty,
+			CostUSD:  lf.proceeds,
+		}
+		if lf.qty > 0 {
+			sold.AvgPrice = lf.proceeds.Float() / lf.qty
+		}
+		sig.Legs = append(sig.Legs, sold)
+		sig.Orders = append(sig.Orders, matches.Order{
+			Venue:      sold.Venue,
+			MarketID:   sold.MarketID,
+			Side:       sold.Side,
+			Outcome:    sold.Outcome,
+			Type:       "limit",
+			LimitPrice: roundDownToTick(lf.worst, snap.Market.TickSize),
+			Quantity:   lf.qty,
+			FeeUSD:     lf.fee(),
+		})
+	}
+	return sig, Reject{}
+}
+
+func (c UnwindConfig) discountRate() float64 {
+	if c.DiscountRate <= 0 {
+		return 0.05
+	}
+	return c.DiscountRate
+}
+
+// discountFactor is the simple-interest present value of $1 paid at
+// settles. Unknown or past settlement times are not discounted.
+func discountFactor(rate float64, now, settles time.Time) float64 {
+	if settles.IsZero() || !settles.After(now) {
+		return 1
+	}
+	years := settles.Sub(now).Hours() / (24 * 365)
+	return 1 / (1 + rate*years)
+}
+
+func latest(times []time.Time) time.Time {
+	var out time.Time
+	for _, t := range times {
+		if t.After(out) {
+			out = t
+		}
+	}
+	return out
+}
+
+// exitFill is the running total of selling every leg in lockstep.
+type exitFill struct {
+	qty  float64
+	legs []exitLeg
+}
+
+// exitLeg accumulates one sell order: proceeds, the unrounded fee and the
+// lowest bid hit.
+type exitLeg struct {
+	fees     FeeModel
+	qty      float64
+	proceeds money.Micros
+	rawFee   float64
+	worst    money.Micros
+}
+
+func (l exitLeg) fee() money.Micros {
+	if l.fees == nil {
+		return 0
+	}
+	return money.FromFloat(l.fees.Round(l.rawFee))
+}
+
+func (f exitFill) fees() money.Micros {
+	var total money.Micros
+	for _, l := range f.legs {
+		total += l.fee()
+	}
+	return total
+}
+
+// proceeds is the cash returned by the sells after fees.
+func (f exitFill) proceeds() money.Micros {
+	var total money.Micros
+	for _, l := range f.legs {
+		total += l.proceeds - l.fee()
+	}
+	return total
+}
+
+func (f exitFill) clone() exitFill {
+	out := exitFill{qty: f.qty, legs: make([]exitLeg, len(f.legs))}
+	copy(out.legs, f.legs)
+	return out
+}
+
+// walkBids sells the same quantity of every leg into its bids, best price
+// first, up to maxQty. It returns the prefix maximising proceeds minus
+// holdValue per contract; with holdValue -Inf that is everything walked.
+func walkBids(maxQty, holdValue float64, ladders [][]collectors.OrderbookLevel, fees []FeeModel) exitFill {
+	iters := make([]*askIterator, len(ladders))
+	for i, levels := range ladders {
+		iters[i] = newBidIterator(levels)
+	}
+	cur := exitFill{legs: make([]exitLeg, len(ladders))}
+	for i := range cur.legs {
+		cur.legs[i].fees = fees[i]
+		cur.legs[i].worst = money.Dollar
+	}
+	best := cur.clone()
+	value := func(f exitFill) float64 {
+		if math.IsInf(holdValue, -1) {
+			return f.qty
+		}
+		return f.proceeds().Float() - holdValue*f.qty
+	}
+
+	for cur.qty < maxQty-epsilon {
+		delta := maxQty - cur.qty
+		for _, it := range iters {
+			delta = math.Min(delta, it.peekQty())
+		}
+		if delta <= epsilon {
+			break
+		}
+		for i, it := range iters {
+			price := it.peekPrice()
+			proceeds, took := it.take(delta)
+			if !took {
+				return best
+			}
+			l := &cur.legs[i]
+			l.qty += delta
+			l.proceeds += proceeds
+			l.rawFee += l.fees.TakerFee(delta, price.Float())
+			if price < l.worst {
+				l.worst = price
+			}
+		}
+		cur.qty += delta
+		if value(cur) > value(best)+epsilon {
+			best = cur.clone()
+		}
+	}
+	return best
+}
+
+// newBidIterator walks a bid ladder from the highest price down. The
+// iterator itself only consumes levels in the order it is given them.
+func newBidIterator(levels []collectors.OrderbookLevel) *askIterator {
+	copied := make([]collectors.OrderbookLevel, len(levels))
+	copy(copied, levels)
+	sort.Slice(copied, func(i, j int) bool {
+		return copied[i].Price > copied[j].Price
+	})
+	return &askIterator{levels: copied}
+}
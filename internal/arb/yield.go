@@ -0,0 +1,59 @@
+package arb
+
+import (
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+)
+
+// minLockup floors the holding period so same-day settlements do not
+// annualize into absurd yields.
+const minLockup = 24 * time.Hour
+
+const year = 365 * 24 * time.Hour
+
+// applyYield fills the return-on-capital fields. Capital is locked until the
+// last leg settles, so the latest of the close times is used. The annualized
+// figure is simple (non-compounded) and left at zero when no close time is
+// known.
+func applyYield(op *matches.Opportunity, now time.Time, closes ...time.Time) {
+	if op == nil || op.TotalCostUSD <= 0 {
+		return
+	}
+	op.ReturnOnCapital = op.ProfitUSD.Float() / op.TotalCostUSD.Float()
+
+	var settles time.Time
+	for _, t := range closes {
+		if t.After(settles) {
+			settles = t
+		}
+	}
+	if settles.IsZero() {
+		return
+	}
+	op.SettlesAt = settles
+	lockup := settles.Sub(now)
+	if lockup < minLockup {
+		lockup = minLockup
+	}
+	op.AnnualizedYield = op.ReturnOnCapital * float64(year) / float64(lockup)
+}
+
+func closeTime(snap *models.MarketSnapshot) time.Time {
+	if snap == nil {
+		return time.Time{}
+	}
+	return marketCloseTime(&snap.Event, &snap.Market)
+}
+
+func marketCloseTime(ev *collectors.Event, m *collectors.Market) time.Time {
+	if m != nil && !m.CloseTime.IsZero() {
+		return m.CloseTime
+	}
+	if ev != nil {
+		return ev.CloseTime
+	}
+	return time.Time{}
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultKillSwitchKey is the Redis key that halts all automated trading
+// while it exists; its value is the reason.
+const DefaultKillSwitchKey = "risk:kill_switch"
+
+// KillSwitch is a global trading halt flipped at runtime with
+// `redis-cli SET risk:kill_switch "<reason>"` and `redis-cli DEL risk:kill_switch`
+// (see the kill-switch-* Make targets).
+type KillSwitch interface {
+	Engaged(ctx context.Context) (bool, string, error)
+	Close() error
+}
+
+type redisKillSwitch struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisKillSwitch builds a kill switch stored under key.
+func NewRedisKillSwitch(addr, password string, db int, key string) (KillSwitch, error) {
+	if addr == "" {
+		return nil, fmt.Errorf("redis addr is required")
+	}
+	if key == "" {
+		key = DefaultKillSwitchKey
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+	return &redisKillSwitch{client: client, key: key}, nil
+}
+
+func (k *redisKillSwitch) Engaged(ctx context.Context) (bool, string, error) {
+	reason, err := k.client.Get(ctx, k.key).Result()
+	if err == redis.Nil {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	return true, reason, nil
+}
+
+func (k *redisKillSwitch) Close() error {
+	if k == nil || k.client == nil {
+		return nil
+	}
+	return k.client.Close()
+}
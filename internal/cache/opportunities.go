@@ -7,14 +7,18 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"github.com/hetulpatel/Arbitrage/internal/money"
 )
 
 // OpportunityRecord captures the best profitable result for a pair.
 type OpportunityRecord struct {
-	ProfitUSD float64   `json:"profit_usd"`
-	Direction string    `json:"direction"`
-	Quantity  float64   `json:"quantity"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ProfitUSD       money.Micros `json:"profit_usd"`
+	AnnualizedYield float64      `json:"annualized_yield"`
+	Score           float64      `json:"score,omitempty"`
+	Direction       string       `json:"direction"`
+	Quantity        float64      `json:"quantity"`
+	UpdatedAt       time.Time    `json:"updated_at"`
 }
 
 // OpportunityCache stores the best opportunity per pair so we can suppress duplicates.
@@ -3,6 +3,8 @@ package cache
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -10,11 +12,28 @@ import (
 
 // VerdictCache stores SAFE/UNSAFE decisions by pair + resolution hash.
 type VerdictCache interface {
-	Get(ctx context.Context, key string) (bool, bool, error)
-	Set(ctx context.Context, key string, verdict bool) error
+	Get(ctx context.Context, key string) (Verdict, bool, error)
+	Set(ctx context.Context, key string, verdict Verdict) error
 	Close() error
 }
 
+// Verdict is a cached validator decision. Inverted marks SAFE pairs whose
+// YES outcomes are each other's negation; Confidence is the validator's
+// confidence (zero when unknown).
+type Verdict struct {
+	Valid      bool
+	Inverted   bool
+	Confidence float64
+}
+
+// Stored values: "0" UNSAFE, "1" SAFE, "i" SAFE with inverted outcomes. SAFE
+// values may carry the confidence as a ":0.85" suffix.
+const (
+	verdictUnsafe   = "0"
+	verdictSafe     = "1"
+	verdictInverted = "i"
+)
+
 type redisVerdictCache struct {
 	client *redis.Client
 	ttl    time.Duration
@@ -43,27 +62,42 @@ func (c *redisVerdictCache) key(k string) string {
 	return fmt.Sprintf("%s:%s", c.prefix, k)
 }
 
-func (c *redisVerdictCache) Get(ctx context.Context, key string) (bool, bool, error) {
+func (c *redisVerdictCache) Get(ctx context.Context, key string) (Verdict, bool, error) {
 	if c == nil || c.client == nil {
-		return false, false, nil
+		return Verdict{}, false, nil
 	}
 	val, err := c.client.Get(ctx, c.key(key)).Result()
 	if err == redis.Nil {
-		return false, false, nil
+		return Verdict{}, false, nil
 	}
 	if err != nil {
-		return false, false, err
+		return Verdict{}, false, err
+	}
+	val, conf, _ := strings.Cut(val, ":")
+	confidence, _ := strconv.ParseFloat(conf, 64)
+	switch val {
+	case verdictSafe:
+		return Verdict{Valid: true, Confidence: confidence}, true, nil
+	case verdictInverted:
+		return Verdict{Valid: true, Inverted: true, Confidence: confidence}, true, nil
+	default:
+		return Verdict{}, true, nil
 	}
-	return val == "1", true, nil
 }
 
-func (c *redisVerdictCache) Set(ctx context.Context, key string, verdict bool) error {
+func (c *redisVerdictCache) Set(ctx context.Context, key string, verdict Verdict) error {
 	if c == nil || c.client == nil {
 		return nil
 	}
-	value := "0"
-	if verdict {
-		value = "1"
+	value := verdictUnsafe
+	if verdict.Valid {
+		value = verdictSafe
+		if verdict.Inverted {
+			value = verdictInverted
+		}
+		if verdict.Confidence > 0 {
+			value += ":" + strconv.FormatFloat(verdict.Confidence, 'f', 3, 64)
+		}
 	}
 	return c.client.Set(ctx, c.key(key), value, c.ttl).Err()
 }
@@ -3,6 +3,8 @@ package collectors
 import (
 	"context"
 	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/money"
 )
 
 // Venue identifies the platform a market/event belongs to.
@@ -41,6 +43,15 @@ type Event struct {
 	CloseTime         time.Time
 	Markets           []Market
 	Raw               map[string]any
+
+	// MutuallyExclusive is set when the venue guarantees at most one market
+	// of the event resolves YES (Kalshi mutually_exclusive, Polymarket
+	// negRisk).
+	MutuallyExclusive bool
+	// Unlisted counts outcomes missing from Markets that may still resolve
+	// YES: markets the collector skipped as inactive or placeholders, and the
+	// unnamed "other" outcome of augmented neg-risk events.
+	Unlisted int
 }
 
 // Market is a normalized market belonging to an event.
@@ -56,6 +67,7 @@ type Market struct {
 	Price        PriceSnapshot
 	Orderbooks   map[string]Orderbook // keyed by outcome/token label (e.g., YES, NO)
 	ClobTokenIDs []string             // Polymarket-specific
+	NegRisk      bool                 // Polymarket-specific: orders settle through the neg-risk exchange
 	ReferenceURL string               // optional human-facing URL
 }
 
@@ -73,9 +85,10 @@ type Orderbook struct {
 	Asks []OrderbookLevel
 }
 
-// OrderbookLevel is a single price/quantity pair.
+// OrderbookLevel is a single price/quantity pair. Price is exact; quantities
+// are whole Kalshi contracts or Polymarket shares in hundredths.
 type OrderbookLevel struct {
-	Price     float64
+	Price     money.Micros
 	Quantity  float64
 	RawPrice  float64 // some venues report ints/cents; RawPrice preserves the original value
 	RawAmount float64 // same for size/quantity
@@ -86,3 +99,37 @@ type ResolutionSource struct {
 	Name string
 	URL  string
 }
+
+// Resolution is how a market settled, as reported by its venue.
+type Resolution struct {
+	Venue    Venue
+	MarketID string
+	Status   string // venue-specific market status
+	// Resolved is set once the outcome is final.
+	Resolved bool
+	// Result is "yes", "no", or "split" when YES pays something in between
+	// (voided or 50-50 markets).
+	Result string
+	// YesPayout is what one YES contract pays; NO pays 1 - YesPayout.
+	YesPayout float64
+}
+
+// Payout is what one contract of outcome ("yes" or "no") pays.
+func (r Resolution) Payout(outcome string) float64 {
+	if outcome == "yes" {
+		return r.YesPayout
+	}
+	return 1 - r.YesPayout
+}
+
+// ResultFor names the outcome a YES payout implies.
+func ResultFor(yesPayout float64) string {
+	switch yesPayout {
+	case 1:
+		return "yes"
+	case 0:
+		return "no"
+	default:
+		return "split"
+	}
+}
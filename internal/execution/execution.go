@@ -0,0 +1,485 @@
+// Package execution trades a final cross-venue opportunity: both legs are
+// sent as immediate orders (fill-or-kill, then immediate-or-cancel), and when
+// one leg fills and the other does not, the pair is repaired by chasing the
+// missing leg or selling the unhedged excess, within a loss limit. Every
+// state transition is handed to a Recorder.
+package execution
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/arb"
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+const epsilon = 1e-9
+
+// Status is the state of an execution.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusFirstLeg  Status = "first_leg"
+	StatusSecondLeg Status = "second_leg"
+	StatusHedging   Status = "hedging"
+	StatusUnwinding Status = "unwinding"
+	// Terminal states.
+	StatusFilled  Status = "filled"  // both legs filled as planned
+	StatusHedged  Status = "hedged"  // the missing leg was bought at a worse price
+	StatusUnwound Status = "unwound" // the unhedged excess was sold back
+	StatusAborted Status = "aborted" // the first leg did not fill; nothing is held
+	StatusBroken  Status = "broken"  // unhedged quantity is left; needs a human
+)
+
+// Terminal reports whether the execution will send no more orders.
+func (s Status) Terminal() bool {
+	switch s {
+	case StatusFilled, StatusHedged, StatusUnwound, StatusAborted, StatusBroken:
+		return true
+	}
+	return false
+}
+
+// Action is whether an order buys or sells contracts of its outcome.
+type Action string
+
+const (
+	Buy  Action = "buy"
+	Sell Action = "sell"
+)
+
+// Request is one immediate order.
+type Request struct {
+	// Snapshot is the fresh snapshot of the market traded; venues read
+	// token ids and tick sizes from it.
+	Snapshot   *models.MarketSnapshot
+	Outcome    string // "yes" or "no"
+	Action     Action
+	LimitPrice money.Micros
+	Quantity   float64
+	// FillOrKill rejects the order unless it fills completely; otherwise
+	// whatever fills immediately is kept and the rest is cancelled.
+	FillOrKill bool
+	// ClientID is unique per order so venues that support it reject retries.
+	ClientID string
+}
+
+// Fill is what an immediate order traded. CostUSD is the gross amount paid
+// (buys) or received (sells) before fees.
+type Fill struct {
+	OrderID  string
+	Quantity float64
+	CostUSD  money.Micros
+	FeeUSD   money.Micros
+}
+
+// Venue sends immediate orders to one exchange. A fill-or-kill order that
+// cannot fill returns a zero Fill, not an error.
+type Venue interface {
+	Take(ctx context.Context, req Request) (Fill, error)
+}
+
+// Recorder persists an execution together with the transition that just
+// happened to it.
+type Recorder interface {
+	RecordExecution(ctx context.Context, e *Execution, ev Event) error
+}
+
+// Leg is one side of the pair and everything traded on it.
+type Leg struct {
+	Venue      string       `json:"venue"`
+	EventID    string       `json:"event_id,omitempty"`
+	MarketID   string       `json:"market_id"`
+	Category   string       `json:"category,omitempty"`
+	Outcome    string       `json:"outcome"`
+	LimitPrice money.Micros `json:"limit_price"`
+	Ordered    float64      `json:"ordered"`
+	Filled     float64      `json:"filled"`
+	CostUSD    money.Micros `json:"cost_usd"`
+	FeeUSD     money.Micros `json:"fee_usd"`
+	// Sold, ProceedsUSD and SellFeeUSD record the excess sold back when
+	// unwinding.
+	Sold        float64      `json:"sold,omitempty"`
+	ProceedsUSD money.Micros `json:"proceeds_usd,omitempty"`
+	SellFeeUSD  money.Micros `json:"sell_fee_usd,omitempty"`
+	OrderIDs    []string     `json:"order_ids,omitempty"`
+}
+
+// Held is the quantity still owned.
+func (l *Leg) Held() float64 {
+	return l.Filled - l.Sold
+}
+
+// UnitCost is the average price paid per contract, fees included.
+func (l *Leg) UnitCost() money.Micros {
+	if l.Filled <= epsilon {
+		return 0
+	}
+	return (l.CostUSD + l.FeeUSD).Div(l.Filled)
+}
+
+func (l *Leg) buy(f Fill) {
+	l.Filled += f.Quantity
+	l.CostUSD += f.CostUSD
+	l.FeeUSD += f.FeeUSD
+	if f.OrderID != "" {
+		l.OrderIDs = append(l.OrderIDs, f.OrderID)
+	}
+}
+
+func (l *Leg) sell(f Fill) {
+	l.Sold += f.Quantity
+	l.ProceedsUSD += f.CostUSD
+	l.SellFeeUSD += f.FeeUSD
+	if f.OrderID != "" {
+		l.OrderIDs = append(l.OrderIDs, f.OrderID)
+	}
+}
+
+// Execution is one attempt to trade an opportunity.
+type Execution struct {
+	ID        int64             `json:"id,omitempty"`
+	PairID    string            `json:"pair_id"`
+	Direction matches.Direction `json:"direction"`
+	DryRun    bool              `json:"dry_run"`
+	Status    Status            `json:"status"`
+	Legs      []Leg             `json:"legs"`
+	// PayoutPerUnit is what one complete set pays at settlement.
+	PayoutPerUnit float64      `json:"payout_per_unit"`
+	PlannedProfit money.Micros `json:"planned_profit_usd"`
+	MaxLossUSD    money.Micros `json:"max_loss_usd"`
+	// LossUSD is what repairing the pair cost: hedge prices above the
+	// payout plus the loss on contracts sold back.
+	LossUSD   money.Micros `json:"loss_usd"`
+	Error     string       `json:"error,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	// SettlesAt is when the later of the two markets closes.
+	SettlesAt time.Time `json:"settles_at,omitempty"`
+
+	token string
+	seq   int
+}
+
+// HedgedQuantity is the number of complete sets held.
+func (e *Execution) HedgedQuantity() float64 {
+	qty := 0.0
+	for i := range e.Legs {
+		if held := e.Legs[i].Held(); i == 0 || held < qty {
+			qty = held
+		}
+	}
+	return qty
+}
+
+// UnhedgedQuantity is what one leg holds beyond the other.
+func (e *Execution) UnhedgedQuantity() float64 {
+	most := 0.0
+	for i := range e.Legs {
+		most = math.Max(most, e.Legs[i].Held())
+	}
+	return most - e.HedgedQuantity()
+}
+
+// Event is one recorded state transition.
+type Event struct {
+	Status Status    `json:"status"`
+	Detail string    `json:"detail,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// Config tunes the executor.
+type Config struct {
+	// MaxLossUSD caps what hedging and unwinding a broken pair may cost.
+	MaxLossUSD money.Micros
+	// FirstVenue's leg is sent first (default Kalshi, whose fill-or-kill
+	// orders cost nothing when they miss).
+	FirstVenue collectors.Venue
+	// DryRun fills every order against the fresh snapshots instead of
+	// sending it, so the executor can shadow the final stage.
+	DryRun bool
+	// Fees price simulated fills and the hedge and unwind limits.
+	Fees arb.FeeSchedule
+}
+
+// Executor runs executions against a set of venues.
+type Executor struct {
+	cfg    Config
+	venues map[collectors.Venue]Venue
+	rec    Recorder
+}
+
+// New builds an executor. venues may be nil in dry-run mode; rec may be nil
+// to skip recording.
+func New(cfg Config, venues map[collectors.Venue]Venue, rec Recorder) *Executor {
+	if cfg.FirstVenue == "" {
+		cfg.FirstVenue = collectors.VenueKalshi
+	}
+	return &Executor{cfg: cfg, venues: venues, rec: rec}
+}
+
+// SendOrder returns op with its orders, and the legs built in step with
+// them, in the order Execute sends them: first's orders ahead of the rest.
+// Leg-risk checks must see the opportunity in this order so they price the
+// sequence that actually trades. op itself is not modified.
+func SendOrder(op *matches.Opportunity, first collectors.Venue) *matches.Opportunity {
+	if op == nil || len(op.Orders) < 2 {
+		return op
+	}
+	idx := make([]int, 0, len(op.Orders))
+	for i, o := range op.Orders {
+		if collectors.Venue(o.Venue) == first {
+			idx = append(idx, i)
+		}
+	}
+	for i, o := range op.Orders {
+		if collectors.Venue(o.Venue) != first {
+			idx = append(idx, i)
+		}
+	}
+	out := *op
+	out.Orders = make([]matches.Order, len(op.Orders))
+	for to, from := range idx {
+		out.Orders[to] = op.Orders[from]
+	}
+	if len(op.Legs) == len(op.Orders) {
+		out.Legs = make([]matches.Leg, len(op.Legs))
+		for to, from := range idx {
+			out.Legs[to] = op.Legs[from]
+		}
+	}
+	return &out
+}
+
+// DryRun reports whether the executor simulates its fills.
+func (x *Executor) DryRun() bool {
+	return x.cfg.DryRun
+}
+
+// Execute trades a two-leg opportunity priced against fresh. It returns an
+// error without trading when the opportunity cannot be executed; otherwise
+// the outcome is in the execution's terminal Status, and the error reports
+// only recording failures.
+func (x *Executor) Execute(ctx context.Context, pairID string, op *matches.Opportunity, fresh *matches.FreshSnapshots) (*Execution, error) {
+	if op == nil || len(op.Orders) != 2 {
+		return nil, fmt.Errorf("execution needs an opportunity with exactly two orders")
+	}
+	op = SendOrder(op, x.cfg.FirstVenue)
+	venues := x.venues
+	if x.cfg.DryRun {
+		sim := NewSimulator(x.cfg.Fees)
+		venues = map[collectors.Venue]Venue{collectors.VenueKalshi: sim, collectors.VenuePolymarket: sim}
+	}
+	type plannedLeg struct {
+		order matches.Order
+		snap  *models.MarketSnapshot
+		venue Venue
+	}
+	var plan []plannedLeg
+	qty := math.Inf(1)
+	for _, order := range op.Orders {
+		if order.Side != string(Buy) {
+			return nil, fmt.Errorf("order on %s %s is not a buy", order.Venue, order.MarketID)
+		}
+		snap := freshSnapshot(fresh, order)
+		if snap == nil {
+			return nil, fmt.Errorf("no fresh snapshot for %s %s", order.Venue, order.MarketID)
+		}
+		venue := venues[collectors.Venue(order.Venue)]
+		if venue == nil {
+			return nil, fmt.Errorf("no trading venue for %s", order.Venue)
+		}
+		plan = append(plan, plannedLeg{order: order, snap: snap, venue: venue})
+		qty = math.Min(qty, order.Quantity)
+	}
+	if qty <= epsilon {
+		return nil, fmt.Errorf("nothing to trade")
+	}
+
+	now := time.Now().UTC()
+	e := &Execution{
+		PairID:        pairID,
+		Direction:     op.Direction,
+		DryRun:        x.cfg.DryRun,
+		Status:        StatusPending,
+		PayoutPerUnit: op.PayoutPerUnit(),
+		PlannedProfit: op.ProfitUSD,
+		MaxLossUSD:    x.cfg.MaxLossUSD,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		SettlesAt:     op.SettlesAt,
+		token:         newToken(),
+	}
+	for _, p := range plan {
+		e.Legs = append(e.Legs, Leg{
+			Venue:      p.order.Venue,
+			EventID:    p.snap.Event.EventID,
+			MarketID:   p.order.MarketID,
+			Category:   p.snap.Event.Category,
+			Outcome:    p.order.Outcome,
+			LimitPrice: p.order.LimitPrice,
+			Ordered:    qty,
+		})
+		if closes := arb.CloseTime(p.snap); closes.After(e.SettlesAt) {
+			e.SettlesAt = closes
+		}
+	}
+	r := &run{x: x, e: e}
+	r.transition(ctx, StatusPending, "qty=%.2f planned_profit=%.4f", qty, op.ProfitUSD)
+
+	// Orders already sent must be repaired even if the caller gives up.
+	ctx = context.WithoutCancel(ctx)
+	first, second := &e.Legs[0], &e.Legs[1]
+
+	r.transition(ctx, StatusFirstLeg, "%s %s buy %s %.2f @ %s fok", first.Venue, first.MarketID, first.Outcome, qty, first.LimitPrice)
+	fill, err := plan[0].venue.Take(ctx, r.request(plan[0].snap, first, Buy, first.LimitPrice, qty, true))
+	if err != nil || fill.Quantity <= epsilon {
+		if err != nil {
+			e.Error = err.Error()
+		}
+		r.transition(ctx, StatusAborted, "first leg not filled")
+		return e, r.err
+	}
+	first.buy(fill)
+
+	r.transition(ctx, StatusSecondLeg, "first filled %.2f cost=%.4f; %s %s buy %s %.2f @ %s ioc",
+		fill.Quantity, fill.CostUSD+fill.FeeUSD, second.Venue, second.MarketID, second.Outcome, first.Held(), second.LimitPrice)
+	fill, err = plan[1].venue.Take(ctx, r.request(plan[1].snap, second, Buy, second.LimitPrice, first.Held(), false))
+	if err != nil {
+		e.Error = err.Error()
+	}
+	second.buy(fill)
+	if e.UnhedgedQuantity() <= epsilon {
+		r.transition(ctx, StatusFilled, "hedged=%.2f", e.HedgedQuantity())
+		return e, r.err
+	}
+
+	// Chase the missing leg: pay up to the price where completing the set
+	// loses no more than what is left of the loss budget.
+	missing := first.Held() - second.Held()
+	model := x.cfg.Fees.ModelFor(plan[1].snap)
+	limit := hedgeLimit(e.PayoutPerUnit, first.UnitCost(), x.cfg.MaxLossUSD-e.LossUSD, missing, model)
+	r.transition(ctx, StatusHedging, "second filled %.2f of %.2f; buy %.2f @ %s ioc", second.Filled, first.Held(), missing, limit)
+	if limit >= money.Cent {
+		fill, err = plan[1].venue.Take(ctx, r.request(plan[1].snap, second, Buy, limit, missing, false))
+		if err != nil {
+			e.Error = err.Error()
+		}
+		second.buy(fill)
+		if fill.Quantity > epsilon {
+			setCost := first.UnitCost().Mul(fill.Quantity) + fill.CostUSD + fill.FeeUSD
+			if loss := setCost - money.FromFloat(e.PayoutPerUnit*fill.Quantity); loss > 0 {
+				e.LossUSD += loss
+			}
+		}
+	}
+	if e.UnhedgedQuantity() <= epsilon {
+		r.transition(ctx, StatusHedged, "hedged=%.2f loss=%.4f", e.HedgedQuantity(), e.LossUSD)
+		return e, r.err
+	}
+
+	// Sell the excess back no cheaper than the remaining budget allows.
+	excess := e.UnhedgedQuantity()
+	model = x.cfg.Fees.ModelFor(plan[0].snap)
+	floor := unwindLimit(first.UnitCost(), x.cfg.MaxLossUSD-e.LossUSD, excess, model)
+	r.transition(ctx, StatusUnwinding, "unhedged %.2f; sell %.2f @ %s ioc", excess, excess, floor)
+	fill, err = plan[0].venue.Take(ctx, r.request(plan[0].snap, first, Sell, floor, excess, false))
+	if err != nil {
+		e.Error = err.Error()
+	}
+	if fill.Quantity > epsilon {
+		paid := first.UnitCost().Mul(fill.Quantity)
+		e.LossUSD += paid - (fill.CostUSD - fill.FeeUSD)
+	}
+	first.sell(fill)
+	if e.UnhedgedQuantity() <= epsilon {
+		r.transition(ctx, StatusUnwound, "hedged=%.2f sold=%.2f loss=%.4f", e.HedgedQuantity(), first.Sold, e.LossUSD)
+		return e, r.err
+	}
+	r.transition(ctx, StatusBroken, "unhedged %.2f on %s %s left after loss=%.4f", e.UnhedgedQuantity(), first.Venue, first.MarketID, e.LossUSD)
+	return e, r.err
+}
+
+// hedgeLimit is the highest price per contract for the missing leg at which
+// completing missing sets loses at most budget, net of the hedge's own taker
+// fee, rounded down to the cent and capped at 99¢.
+func hedgeLimit(payout float64, filledUnitCost, budget money.Micros, missing float64, model arb.FeeModel) money.Micros {
+	if budget < 0 {
+		budget = 0
+	}
+	limit := money.FromFloat(payout) - filledUnitCost + budget.Div(missing)
+	limit -= money.FromFloat(model.TakerFee(1, math.Min(limit.Float(), 1)))
+	return min(limit.FloorTo(money.Cent), money.Dollar-money.Cent)
+}
+
+// unwindLimit is the lowest price per contract at which selling excess loses
+// at most budget after the sale's taker fee, rounded up to the cent and at
+// least 1¢.
+func unwindLimit(filledUnitCost, budget money.Micros, excess float64, model arb.FeeModel) money.Micros {
+	if budget < 0 {
+		budget = 0
+	}
+	floor := filledUnitCost - budget.Div(excess)
+	floor += money.FromFloat(model.TakerFee(1, math.Max(floor.Float(), 0)))
+	return max(floor.CeilTo(money.Cent), money.Cent)
+}
+
+// run carries the per-execution recording state.
+type run struct {
+	x   *Executor
+	e   *Execution
+	err error
+}
+
+func (r *run) transition(ctx context.Context, status Status, format string, args ...any) {
+	now := time.Now().UTC()
+	r.e.Status = status
+	r.e.UpdatedAt = now
+	if r.x.rec == nil {
+		return
+	}
+	ev := Event{Status: status, Detail: fmt.Sprintf(format, args...), At: now}
+	if err := r.x.rec.RecordExecution(ctx, r.e, ev); err != nil {
+		r.err = errors.Join(r.err, fmt.Errorf("record %s: %w", status, err))
+	}
+}
+
+func (r *run) request(snap *models.MarketSnapshot, leg *Leg, action Action, limit money.Micros, qty float64, fok bool) Request {
+	r.e.seq++
+	return Request{
+		Snapshot:   snap,
+		Outcome:    leg.Outcome,
+		Action:     action,
+		LimitPrice: limit,
+		Quantity:   qty,
+		FillOrKill: fok,
+		ClientID:   fmt.Sprintf("arb-%s-%d", r.e.token, r.e.seq),
+	}
+}
+
+func freshSnapshot(fresh *matches.FreshSnapshots, order matches.Order) *models.MarketSnapshot {
+	if fresh == nil {
+		return nil
+	}
+	for _, snap := range []*models.MarketSnapshot{fresh.Polymarket, fresh.Kalshi} {
+		if snap != nil && string(snap.Venue) == order.Venue && snap.Market.MarketID == order.MarketID {
+			return snap
+		}
+	}
+	return nil
+}
+
+func newToken() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
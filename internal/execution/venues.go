@@ -0,0 +1,172 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hetulpatel/Arbitrage/internal/arb"
+	"github.com/hetulpatel/Arbitrage/internal/kalshi"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+	"github.com/hetulpatel/Arbitrage/internal/polymarket"
+)
+
+// Simulator fills orders against the request's snapshot instead of sending
+// them. Liquidity it has taken stays taken, so a hedge after a partial fill
+// walks further up the same book.
+type Simulator struct {
+	fees arb.FeeSchedule
+
+	mu    sync.Mutex
+	taken map[string]float64
+	seq   int
+}
+
+// NewSimulator builds a dry-run venue.
+func NewSimulator(fees arb.FeeSchedule) *Simulator {
+	return &Simulator{fees: fees, taken: make(map[string]float64)}
+}
+
+// Take fills the marginal quantity the snapshot offers at or better than
+// the limit beyond what earlier orders took.
+func (s *Simulator) Take(_ context.Context, req Request) (Fill, error) {
+	if req.Snapshot == nil {
+		return Fill{}, fmt.Errorf("simulator: no snapshot")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := strings.Join([]string{string(req.Snapshot.Venue), req.Snapshot.Market.MarketID, req.Outcome, string(req.Action)}, ":")
+	taken := s.taken[key]
+	simulate := arb.SimulateBuy
+	if req.Action == Sell {
+		simulate = arb.SimulateSell
+	}
+	before := simulate(matches.Order{Outcome: req.Outcome, Quantity: taken}, req.Snapshot, s.fees)
+	after := simulate(matches.Order{Outcome: req.Outcome, LimitPrice: req.LimitPrice, Quantity: taken + req.Quantity}, req.Snapshot, s.fees)
+	qty := after.Quantity - before.Quantity
+	if qty <= epsilon || (req.FillOrKill && qty < req.Quantity-epsilon) {
+		return Fill{}, nil
+	}
+	s.taken[key] = after.Quantity
+	s.seq++
+	return Fill{
+		OrderID:  "dry-" + strconv.Itoa(s.seq),
+		Quantity: qty,
+		CostUSD:  after.CostUSD - before.CostUSD,
+		FeeUSD:   after.FeeUSD - before.FeeUSD,
+	}, nil
+}
+
+// KalshiVenue sends orders through the Kalshi Trade API. Quantities are
+// floored to whole contracts.
+type KalshiVenue struct {
+	Client *kalshi.TradingClient
+}
+
+// Take places a fill-or-kill or immediate-or-cancel limit order and prices
+// what filled from its fills.
+func (v KalshiVenue) Take(ctx context.Context, req Request) (Fill, error) {
+	if req.Snapshot == nil {
+		return Fill{}, fmt.Errorf("kalshi: no snapshot")
+	}
+	count := int(math.Floor(req.Quantity + epsilon))
+	if count <= 0 {
+		return Fill{}, nil
+	}
+	side := kalshi.SideNo
+	if req.Outcome == "yes" {
+		side = kalshi.SideYes
+	}
+	action, price := kalshi.ActionBuy, req.LimitPrice.FloorTo(money.Cent)
+	if req.Action == Sell {
+		action, price = kalshi.ActionSell, req.LimitPrice.CeilTo(money.Cent)
+	}
+	tif := kalshi.ImmediateOrCancel
+	if req.FillOrKill {
+		tif = kalshi.FillOrKill
+	}
+	order, err := v.Client.CreateOrder(ctx, kalshi.OrderRequest{
+		Ticker:        req.Snapshot.Market.MarketID,
+		ClientOrderID: req.ClientID,
+		Side:          side,
+		Action:        action,
+		Count:         count,
+		Price:         price,
+		TimeInForce:   tif,
+	})
+	var apiErr *kalshi.APIError
+	if errors.As(err, &apiErr) && apiErr.Code == "fill_or_kill_insufficient_resting_volume" {
+		return Fill{}, nil
+	}
+	if err != nil {
+		return Fill{}, err
+	}
+	fill := Fill{OrderID: order.ID, Quantity: float64(order.FillCount), FeeUSD: order.TakerFees + order.MakerFees}
+	if order.FillCount == 0 {
+		return fill, nil
+	}
+	fills, err := v.Client.Fills(ctx, kalshi.FillsQuery{Ticker: order.Ticker, OrderID: order.ID})
+	if err != nil || len(fills) == 0 {
+		// The order filled; price it at the limit rather than lose it.
+		fill.CostUSD = price.Mul(fill.Quantity)
+		return fill, nil
+	}
+	for _, f := range fills {
+		fill.CostUSD += f.Price.Mul(float64(f.Count))
+	}
+	return fill, nil
+}
+
+// PolymarketVenue sends orders through the Polymarket CLOB. The token id,
+// tick size and exchange contract come from the request's snapshot.
+type PolymarketVenue struct {
+	Client *polymarket.TradingClient
+}
+
+// Take posts a FOK or FAK order.
+func (v PolymarketVenue) Take(ctx context.Context, req Request) (Fill, error) {
+	if req.Snapshot == nil {
+		return Fill{}, fmt.Errorf("polymarket: no snapshot")
+	}
+	m := &req.Snapshot.Market
+	idx := 1
+	if req.Outcome == "yes" {
+		idx = 0
+	}
+	if idx >= len(m.ClobTokenIDs) || m.ClobTokenIDs[idx] == "" {
+		return Fill{}, fmt.Errorf("polymarket: market %s has no %s token", m.MarketID, req.Outcome)
+	}
+	tick := money.Cent
+	if m.TickSize > 0 {
+		tick = money.FromFloat(m.TickSize)
+	}
+	side, price := polymarket.Buy, req.LimitPrice.FloorTo(tick)
+	if req.Action == Sell {
+		side, price = polymarket.Sell, req.LimitPrice.CeilTo(tick)
+	}
+	orderType := polymarket.FillAndKill
+	if req.FillOrKill {
+		orderType = polymarket.FillOrKill
+	}
+	res, err := v.Client.PlaceOrder(ctx, polymarket.OrderArgs{
+		TokenID:  m.ClobTokenIDs[idx],
+		Side:     side,
+		Price:    price,
+		Size:     req.Quantity,
+		TickSize: tick,
+		NegRisk:  m.NegRisk,
+	}, orderType)
+	var apiErr *polymarket.APIError
+	if req.FillOrKill && errors.As(err, &apiErr) && strings.Contains(apiErr.Message, "fully filled or killed") {
+		return Fill{}, nil
+	}
+	if err != nil {
+		return Fill{}, err
+	}
+	return Fill{OrderID: res.ID, Quantity: res.FilledSize(), CostUSD: res.FilledUSD()}, nil
+}
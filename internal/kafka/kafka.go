@@ -12,10 +12,14 @@ import (
 )
 
 const (
-	DefaultBroker      = "kafka-broker:9092"
-	DefaultPolyTopic   = "polymarket.snapshots"
-	DefaultKalshiTopic = "kalshi.snapshots"
-	DefaultMatchTopic  = "matches.live"
+	DefaultBroker           = "kafka-broker:9092"
+	DefaultPolyTopic        = "polymarket.snapshots"
+	DefaultKalshiTopic      = "kalshi.snapshots"
+	DefaultMatchTopic       = "matches.live"
+	DefaultOpportunityTopic = "opportunities.live"
+	DefaultAllocationTopic  = "allocations.live"
+	DefaultExitTopic        = "exits.live"
+	DefaultQuoteTopic       = "quotes.live"
 )
 
 func Brokers() []string {
@@ -15,6 +15,7 @@ import (
 	"github.com/hetulpatel/Arbitrage/internal/collectors"
 	"github.com/hetulpatel/Arbitrage/internal/logging"
 	"github.com/hetulpatel/Arbitrage/internal/models"
+	"github.com/hetulpatel/Arbitrage/internal/money"
 )
 
 const (
@@ -167,6 +168,24 @@ func (c *Client) fetchSeries(ctx context.Context, ticker string) (*seriesRespons
 	return &out, nil
 }
 
+// Event fetches and normalizes a whole Kalshi event with the orderbooks of
+// every active market, for evaluators that price the family as a basket.
+func (c *Client) Event(ctx context.Context, eventTicker string) (*collectors.Event, error) {
+	if eventTicker == "" {
+		return nil, fmt.Errorf("kalshi: event ticker required")
+	}
+	detail, err := c.fetchEvent(ctx, eventTicker)
+	if err != nil {
+		return nil, fmt.Errorf("kalshi fetch event %s: %w", eventTicker, err)
+	}
+	series, err := c.fetchSeries(ctx, detail.Event.SeriesTicker)
+	if err != nil {
+		return nil, fmt.Errorf("kalshi fetch series: %w", err)
+	}
+	ev := c.normalizeEvent(ctx, detail, series)
+	return &ev, nil
+}
+
 // MarketSnapshot fetches and normalizes a single Kalshi market for fresh orderbooks.
 func (c *Client) MarketSnapshot(ctx context.Context, eventTicker, marketTicker, seriesTicker string) (*models.MarketSnapshot, error) {
 	if eventTicker == "" || marketTicker == "" {
@@ -195,6 +214,51 @@ func (c *Client) MarketSnapshot(ctx context.Context, eventTicker, marketTicker,
 	return nil, fmt.Errorf("kalshi: market %s not found in event %s", marketTicker, eventTicker)
 }
 
+// MarketResolution reports whether a market has settled and how. Closed
+// markets drop out of MarketSnapshot, so this reads the market directly.
+func (c *Client) MarketResolution(ctx context.Context, ticker string) (collectors.Resolution, error) {
+	if ticker == "" {
+		return collectors.Resolution{}, fmt.Errorf("kalshi: market ticker required")
+	}
+	u := fmt.Sprintf("%s/%s", strings.TrimRight(c.bookURL, "/"), ticker)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return collectors.Resolution{}, err
+	}
+	var out struct {
+		Market market `json:"market"`
+	}
+	if err := c.do(req, &out); err != nil {
+		return collectors.Resolution{}, fmt.Errorf("kalshi fetch market %s: %w", ticker, err)
+	}
+	return marketResolution(&out.Market), nil
+}
+
+// marketResolution reads the result once Kalshi has determined it. Markets
+// without a yes/no result (voided, scalar) pay their settlement value.
+func marketResolution(m *market) collectors.Resolution {
+	res := collectors.Resolution{Venue: collectors.VenueKalshi, MarketID: m.Ticker, Status: m.Status}
+	switch m.Status {
+	case "determined", "settled", "finalized":
+	default:
+		return res
+	}
+	switch strings.ToLower(m.Result) {
+	case "yes":
+		res.YesPayout = 1
+	case "no":
+		res.YesPayout = 0
+	default:
+		if m.SettlementValue == nil {
+			return res
+		}
+		res.YesPayout = centsToFloat(*m.SettlementValue)
+	}
+	res.Resolved = true
+	res.Result = collectors.ResultFor(res.YesPayout)
+	return res
+}
+
 func (c *Client) fetchOrderbooks(ctx context.Context, ticker string) (map[string]collectors.Orderbook, error) {
 	u := fmt.Sprintf("%s/%s/orderbook?depth=5", strings.TrimRight(c.bookURL, "/"), ticker)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
@@ -286,6 +350,7 @@ func (c *Client) normalizeEvent(ctx context.Context, detail *eventDetail, series
 		CloseTime:         closeTime,
 		ContractTermsURL:  series.Series.ContractTermsURL,
 		Raw:               map[string]any{"raw_event": detail},
+		MutuallyExclusive: ev.MutuallyExclusive,
 	}
 
 	markets := detail.Markets
@@ -294,6 +359,10 @@ func (c *Client) normalizeEvent(ctx context.Context, detail *eventDetail, series
 	}
 	for _, m := range markets {
 		if m.Status != "active" {
+			// A market already settled NO cannot be the winning outcome.
+			if res := marketResolution(&m); !res.Resolved || res.YesPayout > 0 {
+				norm.Unlisted++
+			}
 			continue
 		}
 		norm.Markets = append(norm.Markets, c.normalizeMarket(ctx, ev, &m, series))
@@ -354,7 +423,7 @@ func convertLevels(levels [][]int64) []collectors.OrderbookLevel {
 		if len(lvl) < 2 {
 			continue
 		}
-		price := centsToFloat(lvl[0])
+		price := money.FromCents(lvl[0])
 		qty := float64(lvl[1])
 		out = append(out, collectors.OrderbookLevel{
 			Price:     price,
@@ -375,12 +444,12 @@ func deriveAsksFromOpposite(oppositeBids []collectors.OrderbookLevel) []collecto
 	}
 	asks := make([]collectors.OrderbookLevel, 0, len(oppositeBids))
 	for _, lvl := range oppositeBids {
-		price := 1 - lvl.Price
+		price := money.Dollar - lvl.Price
 		if price < 0 {
 			price = 0
 		}
-		if price > 1 {
-			price = 1
+		if price > money.Dollar {
+			price = money.Dollar
 		}
 		asks = append(asks, collectors.OrderbookLevel{
 			Price:     price,
@@ -409,6 +478,7 @@ type event struct {
 	ResolutionSources []string `json:"settlement_sources"`
 	RulesPrimary      string   `json:"rules_primary"`
 	RulesSecondary    string   `json:"rules_secondary"`
+	MutuallyExclusive bool     `json:"mutually_exclusive"`
 	Markets           []market `json:"markets"`
 }
 
@@ -435,6 +505,9 @@ type market struct {
 	CloseTime      string `json:"close_time"`
 	TickSize       int64  `json:"tick_size"`
 	ContractURL    string `json:"contract_url"`
+
+	// SettlementValue is what YES paid, in cents, once determined.
+	SettlementValue *int64 `json:"settlement_value"`
 }
 
 type orderbookResponse struct {
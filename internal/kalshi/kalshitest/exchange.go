@@ -0,0 +1,654 @@
+// Package kalshitest provides an in-process mock of the Kalshi Trade API
+// portfolio endpoints. Orders are matched against a scripted book, so order
+// flow can be exercised end to end with kalshi.TradingClient without
+// reaching Kalshi.
+package kalshitest
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/kalshi"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+// BasePath is the API prefix the mock serves, matching the live Trade API.
+const BasePath = "/trade-api/v2"
+
+// Level is one scripted resting bid in whole cents.
+type Level struct {
+	Price int64
+	Count int
+}
+
+// bid is a resting bid on one side of a market. orderID is set for our own
+// resting orders, which incoming orders never match (no self-trades).
+type bid struct {
+	price   int64
+	count   int
+	orderID string
+}
+
+// book holds the YES and NO bids of one market; Kalshi quotes asks as the
+// opposite side's bids.
+type book struct {
+	yes, no []*bid
+}
+
+func (b *book) side(s kalshi.Side) *[]*bid {
+	if s == kalshi.SideYes {
+		return &b.yes
+	}
+	return &b.no
+}
+
+type order struct {
+	wire      wireOrder
+	restSide  kalshi.Side // book side the remainder rests on
+	restPrice int64
+}
+
+type position struct {
+	position int
+	exposure int64
+	feesPaid int64
+	realized int64
+}
+
+// Exchange is a mock Kalshi exchange backed by an httptest.Server. Requests
+// must be signed with the private key matching the public key it was
+// created with.
+type Exchange struct {
+	server *httptest.Server
+	keyID  string
+	pub    *rsa.PublicKey
+
+	mu        sync.Mutex
+	books     map[string]*book
+	orders    map[string]*order
+	clientIDs map[string]string
+	fills     []wireFill
+	positions map[string]*position
+	balance   int64
+	seq       int
+	// TakerFeeRate is the Kalshi taker fee coefficient (default 0.07);
+	// fees are rate × count × P × (1 − P), rounded up to the cent. Maker
+	// fills are free.
+	TakerFeeRate float64
+}
+
+// NewExchange starts a mock exchange that accepts requests signed by keyID.
+// The balance starts at $1,000.
+func NewExchange(keyID string, pub *rsa.PublicKey) *Exchange {
+	e := &Exchange{
+		keyID:        keyID,
+		pub:          pub,
+		books:        make(map[string]*book),
+		orders:       make(map[string]*order),
+		clientIDs:    make(map[string]string),
+		positions:    make(map[string]*position),
+		balance:      100_000,
+		TakerFeeRate: 0.07,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+BasePath+"/portfolio/orders", e.handleCreate)
+	mux.HandleFunc("GET "+BasePath+"/portfolio/orders/{id}", e.handleGet)
+	mux.HandleFunc("DELETE "+BasePath+"/portfolio/orders/{id}", e.handleCancel)
+	mux.HandleFunc("POST "+BasePath+"/portfolio/orders/{id}/amend", e.handleAmend)
+	mux.HandleFunc("GET "+BasePath+"/portfolio/fills", e.handleFills)
+	mux.HandleFunc("GET "+BasePath+"/portfolio/positions", e.handlePositions)
+	mux.HandleFunc("GET "+BasePath+"/portfolio/balance", e.handleBalance)
+	e.server = httptest.NewServer(e.authenticate(mux))
+	return e
+}
+
+// URL is the Trade API base URL to give kalshi.TradingConfig.
+func (e *Exchange) URL() string {
+	return e.server.URL + BasePath
+}
+
+// Close shuts the server down.
+func (e *Exchange) Close() {
+	e.server.Close()
+}
+
+// SetBook replaces a market's external liquidity with the given YES and NO
+// bids. Our own resting orders are kept.
+func (e *Exchange) SetBook(ticker string, yesBids, noBids []Level) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	b := e.book(ticker)
+	b.yes = scripted(b.yes, yesBids)
+	b.no = scripted(b.no, noBids)
+}
+
+func scripted(current []*bid, levels []Level) []*bid {
+	var out []*bid
+	for _, b := range current {
+		if b.orderID != "" {
+			out = append(out, b)
+		}
+	}
+	for _, l := range levels {
+		out = append(out, &bid{price: l.Price, count: l.Count})
+	}
+	sortBids(out)
+	return out
+}
+
+// SetBalance sets the available cash balance.
+func (e *Exchange) SetBalance(balance money.Micros) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	cents, _ := balance.Cents()
+	e.balance = cents
+}
+
+// Balance returns the available cash balance.
+func (e *Exchange) Balance() money.Micros {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return money.FromCents(e.balance)
+}
+
+// Position returns the net contracts held in a market (YES positive).
+func (e *Exchange) Position(ticker string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if p := e.positions[ticker]; p != nil {
+		return p.position
+	}
+	return 0
+}
+
+// FillResting simulates another participant trading count contracts
+// against one of our resting orders at its limit price (a maker fill).
+func (e *Exchange) FillResting(orderID string, count int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o := e.orders[orderID]
+	if o == nil || o.wire.Status != kalshi.OrderResting {
+		return fmt.Errorf("order %s is not resting", orderID)
+	}
+	if count > o.wire.RemainingCount {
+		count = o.wire.RemainingCount
+	}
+	e.execute(o, count, o.priceOfSide(), false)
+	levels := e.book(o.wire.Ticker).side(o.restSide)
+	for i, b := range *levels {
+		if b.orderID == orderID {
+			b.count -= count
+			if b.count <= 0 {
+				*levels = append((*levels)[:i], (*levels)[i+1:]...)
+			}
+			break
+		}
+	}
+	if o.wire.RemainingCount == 0 {
+		o.wire.Status = kalshi.OrderExecuted
+	}
+	return nil
+}
+
+func (e *Exchange) book(ticker string) *book {
+	b := e.books[ticker]
+	if b == nil {
+		b = &book{}
+		e.books[ticker] = b
+	}
+	return b
+}
+
+// authenticate rejects requests whose key id or RSA-PSS signature does not
+// verify.
+func (e *Exchange) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(kalshi.HeaderAccessKey) != e.keyID {
+			writeError(w, http.StatusUnauthorized, "unauthorized", "unknown access key")
+			return
+		}
+		ts := r.Header.Get(kalshi.HeaderAccessTimestamp)
+		if err := kalshi.VerifySignature(e.pub, ts, r.Method, r.URL.Path, r.Header.Get(kalshi.HeaderAccessSignature)); err != nil {
+			writeError(w, http.StatusUnauthorized, "unauthorized", "bad signature")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (e *Exchange) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req wireOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_parameters", err.Error())
+		return
+	}
+	price := req.YesPrice
+	if req.Side == kalshi.SideNo {
+		price = req.NoPrice
+	}
+	if req.Ticker == "" || req.Count <= 0 || price < 1 || price > 99 ||
+		(req.Side != kalshi.SideYes && req.Side != kalshi.SideNo) ||
+		(req.Action != kalshi.ActionBuy && req.Action != kalshi.ActionSell) {
+		writeError(w, http.StatusBadRequest, "invalid_parameters", "ticker, side, action, count and a 1-99 cent price are required")
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if req.ClientOrderID != "" {
+		if _, dup := e.clientIDs[req.ClientOrderID]; dup {
+			writeError(w, http.StatusConflict, "order_already_exists", "duplicate client_order_id")
+			return
+		}
+	}
+	if req.Action == kalshi.ActionBuy && e.balance < worstCost(req.Count, price, e.TakerFeeRate) {
+		writeError(w, http.StatusBadRequest, "insufficient_balance", "insufficient balance")
+		return
+	}
+	available := e.crossable(req.Ticker, req.Side, req.Action, price)
+	if req.PostOnly && available > 0 {
+		writeError(w, http.StatusBadRequest, "post_only_cross", "post-only order would cross the book")
+		return
+	}
+	if req.TimeInForce == kalshi.FillOrKill && available < req.Count {
+		writeError(w, http.StatusBadRequest, "fill_or_kill_insufficient_resting_volume", "not enough volume to fill")
+		return
+	}
+
+	e.seq++
+	o := &order{wire: wireOrder{
+		OrderID:        "ord-" + strconv.Itoa(e.seq),
+		ClientOrderID:  req.ClientOrderID,
+		Ticker:         req.Ticker,
+		Side:           req.Side,
+		Action:         req.Action,
+		Type:           "limit",
+		Status:         kalshi.OrderResting,
+		InitialCount:   req.Count,
+		RemainingCount: req.Count,
+		CreatedTime:    time.Now().UTC().Format(time.RFC3339),
+	}}
+	o.setPrice(price)
+	e.orders[o.wire.OrderID] = o
+	if req.ClientOrderID != "" {
+		e.clientIDs[req.ClientOrderID] = o.wire.OrderID
+	}
+	e.match(o)
+	e.finish(o, req.TimeInForce)
+	writeJSON(w, map[string]any{"order": o.wire})
+}
+
+func (e *Exchange) handleGet(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o := e.orders[r.PathValue("id")]
+	if o == nil {
+		writeError(w, http.StatusNotFound, "not_found", "order not found")
+		return
+	}
+	writeJSON(w, map[string]any{"order": o.wire})
+}
+
+func (e *Exchange) handleCancel(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o := e.orders[r.PathValue("id")]
+	if o == nil {
+		writeError(w, http.StatusNotFound, "not_found", "order not found")
+		return
+	}
+	if o.wire.Status != kalshi.OrderResting {
+		writeError(w, http.StatusBadRequest, "order_not_resting", "order is not resting")
+		return
+	}
+	reduced := o.wire.RemainingCount
+	e.unrest(o)
+	o.wire.RemainingCount = 0
+	o.wire.Status = kalshi.OrderCanceled
+	writeJSON(w, map[string]any{"order": o.wire, "reduced_by": reduced})
+}
+
+func (e *Exchange) handleAmend(w http.ResponseWriter, r *http.Request) {
+	var req wireAmendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_parameters", err.Error())
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o := e.orders[r.PathValue("id")]
+	if o == nil {
+		writeError(w, http.StatusNotFound, "not_found", "order not found")
+		return
+	}
+	if o.wire.Status != kalshi.OrderResting {
+		writeError(w, http.StatusBadRequest, "order_not_resting", "order is not resting")
+		return
+	}
+	price := req.YesPrice
+	if o.wire.Side == kalshi.SideNo {
+		price = req.NoPrice
+	}
+	if price < 1 || price > 99 || req.Count < o.wire.FillCount+1 {
+		writeError(w, http.StatusBadRequest, "invalid_parameters", "amend needs a 1-99 cent price and a count above the filled quantity")
+		return
+	}
+	old := o.wire
+	e.unrest(o)
+	o.setPrice(price)
+	o.wire.InitialCount = req.Count
+	o.wire.RemainingCount = req.Count - o.wire.FillCount
+	if req.UpdatedClientOrderID != "" {
+		delete(e.clientIDs, o.wire.ClientOrderID)
+		o.wire.ClientOrderID = req.UpdatedClientOrderID
+		e.clientIDs[req.UpdatedClientOrderID] = o.wire.OrderID
+	}
+	e.match(o)
+	e.finish(o, kalshi.GoodTillCanceled)
+	writeJSON(w, map[string]any{"old_order": old, "order": o.wire})
+}
+
+func (e *Exchange) handleFills(w http.ResponseWriter, r *http.Request) {
+	ticker := r.URL.Query().Get("ticker")
+	orderID := r.URL.Query().Get("order_id")
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := []wireFill{}
+	for _, f := range e.fills {
+		if (ticker == "" || f.Ticker == ticker) && (orderID == "" || f.OrderID == orderID) {
+			out = append(out, f)
+		}
+	}
+	writeJSON(w, map[string]any{"fills": out, "cursor": ""})
+}
+
+func (e *Exchange) handlePositions(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	tickers := make([]string, 0, len(e.positions))
+	for t := range e.positions {
+		tickers = append(tickers, t)
+	}
+	sort.Strings(tickers)
+	out := []wirePosition{}
+	for _, t := range tickers {
+		p := e.positions[t]
+		resting := 0
+		for _, o := range e.orders {
+			if o.wire.Ticker == t && o.wire.Status == kalshi.OrderResting {
+				resting++
+			}
+		}
+		out = append(out, wirePosition{
+			Ticker:             t,
+			Position:           p.position,
+			MarketExposure:     p.exposure,
+			RealizedPnL:        p.realized,
+			FeesPaid:           p.feesPaid,
+			RestingOrdersCount: resting,
+		})
+	}
+	writeJSON(w, map[string]any{"market_positions": out, "event_positions": []any{}, "cursor": ""})
+}
+
+func (e *Exchange) handleBalance(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	writeJSON(w, map[string]any{"balance": e.balance})
+}
+
+// counterparty returns the book side and the price test an incoming order
+// matches against. Buying a side lifts the opposite side's bids (a NO bid at
+// q is a YES ask at 100-q); selling a side hits that side's bids.
+func counterparty(s kalshi.Side, a kalshi.Action) (kalshi.Side, func(bidPrice, limit int64) bool, func(bidPrice int64) int64) {
+	if a == kalshi.ActionBuy {
+		return opposite(s),
+			func(bidPrice, limit int64) bool { return 100-bidPrice <= limit },
+			func(bidPrice int64) int64 { return 100 - bidPrice }
+	}
+	return s,
+		func(bidPrice, limit int64) bool { return bidPrice >= limit },
+		func(bidPrice int64) int64 { return bidPrice }
+}
+
+func opposite(s kalshi.Side) kalshi.Side {
+	if s == kalshi.SideYes {
+		return kalshi.SideNo
+	}
+	return kalshi.SideYes
+}
+
+// crossable counts the external contracts an order could take right now.
+func (e *Exchange) crossable(ticker string, s kalshi.Side, a kalshi.Action, limit int64) int {
+	side, crosses, _ := counterparty(s, a)
+	total := 0
+	for _, b := range *e.book(ticker).side(side) {
+		if b.orderID == "" && crosses(b.price, limit) {
+			total += b.count
+		}
+	}
+	return total
+}
+
+// match takes external liquidity for o, best price first.
+func (e *Exchange) match(o *order) {
+	side, crosses, fillPrice := counterparty(o.wire.Side, o.wire.Action)
+	levels := e.book(o.wire.Ticker).side(side)
+	limit := o.priceOfSide()
+	var kept []*bid
+	var takerCost float64
+	for _, b := range *levels {
+		if o.wire.RemainingCount > 0 && b.orderID == "" && crosses(b.price, limit) {
+			n := min(b.count, o.wire.RemainingCount)
+			p := fillPrice(b.price)
+			e.execute(o, n, p, true)
+			takerCost += float64(n) * float64(p) * float64(100-p) / 100
+			b.count -= n
+		}
+		if b.count > 0 {
+			kept = append(kept, b)
+		}
+	}
+	*levels = kept
+	if takerCost > 0 {
+		fee := int64(math.Ceil(e.TakerFeeRate*takerCost - 1e-9))
+		o.wire.TakerFees += fee
+		e.balance -= fee
+		e.positionFor(o.wire.Ticker).feesPaid += fee
+	}
+}
+
+// execute books n contracts of o at price (cents of o's side).
+func (e *Exchange) execute(o *order, n int, price int64, taker bool) {
+	o.wire.FillCount += n
+	o.wire.RemainingCount -= n
+	p := e.positionFor(o.wire.Ticker)
+	cost := int64(n) * price
+	signed := n
+	if o.wire.Side == kalshi.SideNo {
+		signed = -n
+	}
+	if o.wire.Action == kalshi.ActionBuy {
+		e.balance -= cost
+		p.exposure += cost
+		p.position += signed
+	} else {
+		e.balance += cost
+		p.exposure -= cost
+		if p.exposure < 0 {
+			p.realized -= p.exposure
+			p.exposure = 0
+		}
+		p.position -= signed
+	}
+	e.seq++
+	f := wireFill{
+		TradeID:     "trd-" + strconv.Itoa(e.seq),
+		OrderID:     o.wire.OrderID,
+		Ticker:      o.wire.Ticker,
+		Side:        o.wire.Side,
+		Action:      o.wire.Action,
+		Count:       n,
+		IsTaker:     taker,
+		CreatedTime: time.Now().UTC().Format(time.RFC3339),
+	}
+	if o.wire.Side == kalshi.SideYes {
+		f.YesPrice, f.NoPrice = price, 100-price
+	} else {
+		f.YesPrice, f.NoPrice = 100-price, price
+	}
+	e.fills = append(e.fills, f)
+}
+
+// finish rests or cancels what is left of o after matching.
+func (e *Exchange) finish(o *order, tif kalshi.TimeInForce) {
+	switch {
+	case o.wire.RemainingCount == 0:
+		o.wire.Status = kalshi.OrderExecuted
+	case tif == kalshi.ImmediateOrCancel || tif == kalshi.FillOrKill:
+		o.wire.RemainingCount = 0
+		o.wire.Status = kalshi.OrderCanceled
+	default:
+		// A resting buy of a side is a bid on that side; a resting sell is a
+		// bid on the opposite side at the complementary price.
+		o.restSide, o.restPrice = o.wire.Side, o.priceOfSide()
+		if o.wire.Action == kalshi.ActionSell {
+			o.restSide, o.restPrice = opposite(o.wire.Side), 100-o.priceOfSide()
+		}
+		levels := e.book(o.wire.Ticker).side(o.restSide)
+		*levels = append(*levels, &bid{price: o.restPrice, count: o.wire.RemainingCount, orderID: o.wire.OrderID})
+		sortBids(*levels)
+		o.wire.Status = kalshi.OrderResting
+	}
+}
+
+// unrest removes o's resting bid from the book.
+func (e *Exchange) unrest(o *order) {
+	levels := e.book(o.wire.Ticker).side(o.restSide)
+	for i, b := range *levels {
+		if b.orderID == o.wire.OrderID {
+			*levels = append((*levels)[:i], (*levels)[i+1:]...)
+			return
+		}
+	}
+}
+
+func (e *Exchange) positionFor(ticker string) *position {
+	p := e.positions[ticker]
+	if p == nil {
+		p = &position{}
+		e.positions[ticker] = p
+	}
+	return p
+}
+
+func (o *order) priceOfSide() int64 {
+	if o.wire.Side == kalshi.SideYes {
+		return o.wire.YesPrice
+	}
+	return o.wire.NoPrice
+}
+
+func (o *order) setPrice(price int64) {
+	if o.wire.Side == kalshi.SideYes {
+		o.wire.YesPrice, o.wire.NoPrice = price, 100-price
+	} else {
+		o.wire.YesPrice, o.wire.NoPrice = 100-price, price
+	}
+}
+
+// worstCost is the most a buy can cost: every contract at the limit plus
+// the taker fee on all of it.
+func worstCost(count int, price int64, rate float64) int64 {
+	fee := int64(math.Ceil(rate * float64(count) * float64(price) * float64(100-price) / 100))
+	return int64(count)*price + fee
+}
+
+func sortBids(levels []*bid) {
+	sort.SliceStable(levels, func(i, j int) bool { return levels[i].price > levels[j].price })
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"error": map[string]string{"code": code, "message": message}})
+}
+
+// Wire formats of the Trade API, as the live exchange sends them.
+
+type wireOrderRequest struct {
+	Ticker        string             `json:"ticker"`
+	ClientOrderID string             `json:"client_order_id"`
+	Side          kalshi.Side        `json:"side"`
+	Action        kalshi.Action      `json:"action"`
+	Count         int                `json:"count"`
+	Type          string             `json:"type"`
+	YesPrice      int64              `json:"yes_price"`
+	NoPrice       int64              `json:"no_price"`
+	TimeInForce   kalshi.TimeInForce `json:"time_in_force"`
+	PostOnly      bool               `json:"post_only"`
+}
+
+type wireAmendRequest struct {
+	Ticker               string        `json:"ticker"`
+	Side                 kalshi.Side   `json:"side"`
+	Action               kalshi.Action `json:"action"`
+	ClientOrderID        string        `json:"client_order_id"`
+	UpdatedClientOrderID string        `json:"updated_client_order_id"`
+	YesPrice             int64         `json:"yes_price"`
+	NoPrice              int64         `json:"no_price"`
+	Count                int           `json:"count"`
+}
+
+type wireOrder struct {
+	OrderID        string             `json:"order_id"`
+	ClientOrderID  string             `json:"client_order_id"`
+	Ticker         string             `json:"ticker"`
+	Side           kalshi.Side        `json:"side"`
+	Action         kalshi.Action      `json:"action"`
+	Type           string             `json:"type"`
+	Status         kalshi.OrderStatus `json:"status"`
+	YesPrice       int64              `json:"yes_price"`
+	NoPrice        int64              `json:"no_price"`
+	InitialCount   int                `json:"initial_count"`
+	FillCount      int                `json:"fill_count"`
+	RemainingCount int                `json:"remaining_count"`
+	TakerFees      int64              `json:"taker_fees"`
+	MakerFees      int64              `json:"maker_fees"`
+	CreatedTime    string             `json:"created_time"`
+}
+
+type wireFill struct {
+	TradeID     string        `json:"trade_id"`
+	OrderID     string        `json:"order_id"`
+	Ticker      string        `json:"ticker"`
+	Side        kalshi.Side   `json:"side"`
+	Action      kalshi.Action `json:"action"`
+	Count       int           `json:"count"`
+	YesPrice    int64         `json:"yes_price"`
+	NoPrice     int64         `json:"no_price"`
+	IsTaker     bool          `json:"is_taker"`
+	CreatedTime string        `json:"created_time"`
+}
+
+type wirePosition struct {
+	Ticker             string `json:"ticker"`
+	Position           int    `json:"position"`
+	MarketExposure     int64  `json:"market_exposure"`
+	RealizedPnL        int64  `json:"realized_pnl"`
+	FeesPaid           int64  `json:"fees_paid"`
+	RestingOrdersCount int    `json:"resting_orders_count"`
+}
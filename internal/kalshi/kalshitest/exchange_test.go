@@ -0,0 +1,285 @@
+package kalshitest_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/hetulpatel/Arbitrage/internal/kalshi"
+	"github.com/hetulpatel/Arbitrage/internal/kalshi/kalshitest"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+const (
+	keyID  = "test-key"
+	ticker = "KXTEST-26-T70"
+)
+
+var (
+	keyOnce sync.Once
+	testKey *rsa.PrivateKey
+)
+
+// key returns one RSA key shared by the tests; generating it is slow.
+func key(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	keyOnce.Do(func() {
+		k, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("generate key: %v", err)
+		}
+		testKey = k
+	})
+	return testKey
+}
+
+// setup starts an exchange whose NO bids at 62¢ (10) and 60¢ (5) are YES
+// asks at 38¢ and 40¢, and a client signing for it.
+func setup(t *testing.T) (*kalshitest.Exchange, *kalshi.TradingClient) {
+	t.Helper()
+	priv := key(t)
+	ex := kalshitest.NewExchange(keyID, &priv.PublicKey)
+	t.Cleanup(ex.Close)
+	ex.SetBook(ticker, []kalshitest.Level{{Price: 35, Count: 20}}, []kalshitest.Level{{Price: 62, Count: 10}, {Price: 60, Count: 5}})
+	client, err := kalshi.NewTradingClient(kalshi.TradingConfig{BaseURL: ex.URL(), KeyID: keyID, PrivateKey: priv})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	return ex, client
+}
+
+func cents(c int64) money.Micros {
+	return money.FromCents(c)
+}
+
+func apiCode(t *testing.T, err error) (int, string) {
+	t.Helper()
+	var apiErr *kalshi.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("want *kalshi.APIError, got %v", err)
+	}
+	return apiErr.StatusCode, apiErr.Code
+}
+
+func TestSignatureVerifies(t *testing.T) {
+	priv := key(t)
+	sig, err := kalshi.Sign(priv, "1700000000000", http.MethodPost, "/trade-api/v2/portfolio/orders")
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if err := kalshi.VerifySignature(&priv.PublicKey, "1700000000000", http.MethodPost, "/trade-api/v2/portfolio/orders", sig); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	for _, tc := range []struct{ ts, method, path string }{
+		{"1700000000001", http.MethodPost, "/trade-api/v2/portfolio/orders"},
+		{"1700000000000", http.MethodGet, "/trade-api/v2/portfolio/orders"},
+		{"1700000000000", http.MethodPost, "/trade-api/v2/portfolio/fills"},
+	} {
+		if err := kalshi.VerifySignature(&priv.PublicKey, tc.ts, tc.method, tc.path, sig); err == nil {
+			t.Errorf("signature verified for %s %s at %s", tc.method, tc.path, tc.ts)
+		}
+	}
+}
+
+func TestExchangeRejectsUnsignedRequests(t *testing.T) {
+	ex, _ := setup(t)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	ctx := context.Background()
+	for name, cfg := range map[string]kalshi.TradingConfig{
+		"wrong key":    {BaseURL: ex.URL(), KeyID: keyID, PrivateKey: other},
+		"wrong key id": {BaseURL: ex.URL(), KeyID: "someone-else", PrivateKey: key(t)},
+	} {
+		client, err := kalshi.NewTradingClient(cfg)
+		if err != nil {
+			t.Fatalf("%s: new client: %v", name, err)
+		}
+		_, err = client.CreateOrder(ctx, kalshi.OrderRequest{Ticker: ticker, Side: kalshi.SideYes, Action: kalshi.ActionBuy, Count: 1, Price: cents(40)})
+		if status, _ := apiCode(t, err); status != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want 401", name, status)
+		}
+	}
+	if pos := ex.Position(ticker); pos != 0 {
+		t.Errorf("position = %d after rejected orders, want 0", pos)
+	}
+}
+
+func TestCreateOrderTakesThenRests(t *testing.T) {
+	ex, client := setup(t)
+	ctx := context.Background()
+	order, err := client.CreateOrder(ctx, kalshi.OrderRequest{
+		Ticker: ticker, ClientOrderID: "c-1", Side: kalshi.SideYes, Action: kalshi.ActionBuy, Count: 20, Price: cents(39),
+	})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	// Only the 38¢ asks cross a 39¢ limit; the rest rests.
+	if order.Status != kalshi.OrderResting || order.FillCount != 10 || order.RemainingCount != 10 {
+		t.Fatalf("order = %s fill=%d remaining=%d, want resting 10/10", order.Status, order.FillCount, order.RemainingCount)
+	}
+	if order.Price != cents(39) {
+		t.Errorf("price = %v, want 0.39", order.Price)
+	}
+	// fee = ceil(0.07 × 10 × 38 × 62 / 100) = ceil(16.492) = 17¢.
+	if order.TakerFees != cents(17) {
+		t.Errorf("taker fees = %v, want 0.17", order.TakerFees)
+	}
+
+	fills, err := client.Fills(ctx, kalshi.FillsQuery{OrderID: order.ID})
+	if err != nil {
+		t.Fatalf("fills: %v", err)
+	}
+	if len(fills) != 1 || fills[0].Count != 10 || fills[0].Price != cents(38) || !fills[0].IsTaker {
+		t.Fatalf("fills = %+v, want one taker fill of 10 at 0.38", fills)
+	}
+	balance, err := client.Balance(ctx)
+	if err != nil {
+		t.Fatalf("balance: %v", err)
+	}
+	if want := cents(100_000 - 380 - 17); balance != want {
+		t.Errorf("balance = %v, want %v", balance, want)
+	}
+	positions, err := client.Positions(ctx)
+	if err != nil {
+		t.Fatalf("positions: %v", err)
+	}
+	if len(positions) != 1 || positions[0].Position != 10 || positions[0].RestingOrders != 1 {
+		t.Fatalf("positions = %+v, want 10 YES with one resting order", positions)
+	}
+
+	// A maker fill against the resting remainder is free.
+	if err := ex.FillResting(order.ID, 4); err != nil {
+		t.Fatalf("fill resting: %v", err)
+	}
+	got, err := client.GetOrder(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("get order: %v", err)
+	}
+	if got.FillCount != 14 || got.RemainingCount != 6 || got.TakerFees != cents(17) {
+		t.Errorf("after maker fill: fill=%d remaining=%d fees=%v, want 14/6/0.17", got.FillCount, got.RemainingCount, got.TakerFees)
+	}
+	if pos := ex.Position(ticker); pos != 14 {
+		t.Errorf("position = %d, want 14", pos)
+	}
+
+	_, err = client.CreateOrder(ctx, kalshi.OrderRequest{
+		Ticker: ticker, ClientOrderID: "c-1", Side: kalshi.SideYes, Action: kalshi.ActionBuy, Count: 1, Price: cents(39),
+	})
+	if status, code := apiCode(t, err); status != http.StatusConflict || code != "order_already_exists" {
+		t.Errorf("duplicate client id: %d %s, want 409 order_already_exists", status, code)
+	}
+}
+
+func TestPostOnlyRejectsCrossingOrder(t *testing.T) {
+	ex, client := setup(t)
+	_, err := client.CreateOrder(context.Background(), kalshi.OrderRequest{
+		Ticker: ticker, Side: kalshi.SideYes, Action: kalshi.ActionBuy, Count: 1, Price: cents(38), PostOnly: true,
+	})
+	if _, code := apiCode(t, err); code != "post_only_cross" {
+		t.Errorf("code = %s, want post_only_cross", code)
+	}
+	if pos := ex.Position(ticker); pos != 0 {
+		t.Errorf("position = %d, want 0", pos)
+	}
+}
+
+func TestImmediateOrCancelCancelsRemainder(t *testing.T) {
+	ex, client := setup(t)
+	ctx := context.Background()
+	order, err := client.CreateOrder(ctx, kalshi.OrderRequest{
+		Ticker: ticker, Side: kalshi.SideYes, Action: kalshi.ActionBuy, Count: 20, Price: cents(40), TimeInForce: kalshi.ImmediateOrCancel,
+	})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	// 10 at 38¢ and 5 at 40¢ cross; the other 5 are canceled, not rested.
+	if order.Status != kalshi.OrderCanceled || order.FillCount != 15 || order.RemainingCount != 0 {
+		t.Fatalf("order = %s fill=%d remaining=%d, want canceled 15/0", order.Status, order.FillCount, order.RemainingCount)
+	}
+	if pos := ex.Position(ticker); pos != 15 {
+		t.Errorf("position = %d, want 15", pos)
+	}
+	positions, err := client.Positions(ctx)
+	if err != nil {
+		t.Fatalf("positions: %v", err)
+	}
+	if len(positions) != 1 || positions[0].RestingOrders != 0 {
+		t.Errorf("positions = %+v, want no resting orders", positions)
+	}
+}
+
+func TestFillOrKill(t *testing.T) {
+	ex, client := setup(t)
+	ctx := context.Background()
+	before := ex.Balance()
+	_, err := client.CreateOrder(ctx, kalshi.OrderRequest{
+		Ticker: ticker, Side: kalshi.SideYes, Action: kalshi.ActionBuy, Count: 16, Price: cents(40), TimeInForce: kalshi.FillOrKill,
+	})
+	if _, code := apiCode(t, err); code != "fill_or_kill_insufficient_resting_volume" {
+		t.Fatalf("code = %s, want fill_or_kill_insufficient_resting_volume", code)
+	}
+	if pos := ex.Position(ticker); pos != 0 || ex.Balance() != before {
+		t.Fatalf("killed order moved the book: position=%d balance=%v", pos, ex.Balance())
+	}
+
+	order, err := client.CreateOrder(ctx, kalshi.OrderRequest{
+		Ticker: ticker, Side: kalshi.SideYes, Action: kalshi.ActionBuy, Count: 15, Price: cents(40), TimeInForce: kalshi.FillOrKill,
+	})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if order.Status != kalshi.OrderExecuted || order.FillCount != 15 {
+		t.Fatalf("order = %s fill=%d, want executed 15", order.Status, order.FillCount)
+	}
+	// Selling YES hits the 35¢ YES bids.
+	sell, err := client.CreateOrder(ctx, kalshi.OrderRequest{
+		Ticker: ticker, Side: kalshi.SideYes, Action: kalshi.ActionSell, Count: 15, Price: cents(35), TimeInForce: kalshi.FillOrKill,
+	})
+	if err != nil {
+		t.Fatalf("sell: %v", err)
+	}
+	if sell.Status != kalshi.OrderExecuted || ex.Position(ticker) != 0 {
+		t.Errorf("sell = %s, position = %d, want executed and flat", sell.Status, ex.Position(ticker))
+	}
+}
+
+func TestCancelOrder(t *testing.T) {
+	ex, client := setup(t)
+	ctx := context.Background()
+	order, err := client.CreateOrder(ctx, kalshi.OrderRequest{
+		Ticker: ticker, Side: kalshi.SideNo, Action: kalshi.ActionBuy, Count: 8, Price: cents(50),
+	})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	// A 50¢ NO bid does not cross the 35¢ YES bid (a 65¢ NO ask).
+	if order.Status != kalshi.OrderResting || order.FillCount != 0 {
+		t.Fatalf("order = %s fill=%d, want resting unfilled", order.Status, order.FillCount)
+	}
+	canceled, err := client.CancelOrder(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if canceled.Status != kalshi.OrderCanceled || canceled.RemainingCount != 0 {
+		t.Fatalf("canceled = %s remaining=%d, want canceled 0", canceled.Status, canceled.RemainingCount)
+	}
+	if _, err := client.CancelOrder(ctx, order.ID); err == nil {
+		t.Error("second cancel succeeded")
+	} else if _, code := apiCode(t, err); code != "order_not_resting" {
+		t.Errorf("second cancel code = %s, want order_not_resting", code)
+	}
+	if err := ex.FillResting(order.ID, 1); err == nil {
+		t.Error("maker fill against a canceled order succeeded")
+	}
+	if _, err := client.CancelOrder(ctx, "ord-missing"); err == nil {
+		t.Error("cancel of an unknown order succeeded")
+	} else if status, _ := apiCode(t, err); status != http.StatusNotFound {
+		t.Errorf("unknown order status = %d, want 404", status)
+	}
+}
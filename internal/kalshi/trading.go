@@ -0,0 +1,610 @@
+package kalshi
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+const defaultTradeURL = "https://api.elections.kalshi.com/trade-api/v2"
+
+// Authentication headers of the Trade API.
+const (
+	HeaderAccessKey       = "KALSHI-ACCESS-KEY"
+	HeaderAccessTimestamp = "KALSHI-ACCESS-TIMESTAMP"
+	HeaderAccessSignature = "KALSHI-ACCESS-SIGNATURE"
+)
+
+// TradingConfig configures an authenticated TradingClient.
+type TradingConfig struct {
+	// BaseURL is the Trade API root, ending in /trade-api/v2.
+	BaseURL string
+	// KeyID is the API key id shown next to the key in the Kalshi UI.
+	KeyID string
+	// PrivateKey is the RSA key the API key was created with.
+	PrivateKey *rsa.PrivateKey
+	Timeout    time.Duration
+}
+
+// TradingClient places and manages orders on the Kalshi Trade API. Every
+// request is signed with RSA-PSS over timestamp + method + path.
+type TradingClient struct {
+	baseURL    string
+	keyID      string
+	key        *rsa.PrivateKey
+	httpClient *http.Client
+}
+
+// NewTradingClient builds an authenticated client.
+func NewTradingClient(cfg TradingConfig) (*TradingClient, error) {
+	if cfg.KeyID == "" || cfg.PrivateKey == nil {
+		return nil, fmt.Errorf("kalshi: key id and private key required")
+	}
+	base := cfg.BaseURL
+	if base == "" {
+		base = defaultTradeURL
+	}
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	return &TradingClient{
+		baseURL: strings.TrimRight(base, "/"),
+		keyID:   cfg.KeyID,
+		key:     cfg.PrivateKey,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}, nil
+}
+
+// LoadPrivateKey reads a PEM-encoded RSA private key (PKCS#1 or PKCS#8).
+func LoadPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read kalshi key: %w", err)
+	}
+	return ParsePrivateKey(data)
+}
+
+// ParsePrivateKey decodes a PEM-encoded RSA private key (PKCS#1 or PKCS#8).
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("kalshi key: no PEM block")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("kalshi key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("kalshi key: not an RSA key")
+	}
+	return key, nil
+}
+
+// Sign returns the base64 RSA-PSS (SHA-256, salt length equal to the hash)
+// signature Kalshi expects for a request. path excludes the query string.
+func Sign(key *rsa.PrivateKey, timestamp, method, path string) (string, error) {
+	digest := sha256.Sum256([]byte(timestamp + method + path))
+	sig, err := rsa.SignPSS(rand.Reader, key, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	if err != nil {
+		return "", fmt.Errorf("kalshi sign: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// VerifySignature checks a signature produced by Sign.
+func VerifySignature(pub *rsa.PublicKey, timestamp, method, path, signature string) error {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("kalshi signature: %w", err)
+	}
+	digest := sha256.Sum256([]byte(timestamp + method + path))
+	return rsa.VerifyPSS(pub, crypto.SHA256, digest[:], sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+}
+
+// Side is the contract side an order trades.
+type Side string
+
+const (
+	SideYes Side = "yes"
+	SideNo  Side = "no"
+)
+
+// Action is whether an order buys or sells contracts of its side.
+type Action string
+
+const (
+	ActionBuy  Action = "buy"
+	ActionSell Action = "sell"
+)
+
+// TimeInForce controls what happens to the unfilled part of an order.
+type TimeInForce string
+
+const (
+	// GoodTillCanceled rests the remainder (the API default).
+	GoodTillCanceled  TimeInForce = ""
+	FillOrKill        TimeInForce = "fill_or_kill"
+	ImmediateOrCancel TimeInForce = "immediate_or_cancel"
+)
+
+// OrderStatus is the lifecycle state of an order.
+type OrderStatus string
+
+const (
+	OrderResting  OrderStatus = "resting"
+	OrderCanceled OrderStatus = "canceled"
+	OrderExecuted OrderStatus = "executed"
+	OrderPending  OrderStatus = "pending"
+)
+
+// OrderRequest is a new limit order.
+type OrderRequest struct {
+	Ticker string
+	// ClientOrderID makes retries safe: Kalshi rejects a second order with
+	// the same id.
+	ClientOrderID string
+	Side          Side
+	Action        Action
+	Count         int
+	// Price is the limit price of Side; it must be a whole number of cents.
+	Price       money.Micros
+	TimeInForce TimeInForce
+	// PostOnly rejects the order instead of letting it take liquidity.
+	PostOnly bool
+}
+
+// AmendRequest changes the price and/or total count of a resting order.
+type AmendRequest struct {
+	Ticker               string
+	Side                 Side
+	Action               Action
+	ClientOrderID        string
+	UpdatedClientOrderID string
+	// Price is the new limit price of Side in whole cents.
+	Price money.Micros
+	// Count is the new total (filled plus remaining) contract count.
+	Count int
+}
+
+// Order is an order as the exchange reports it.
+type Order struct {
+	ID             string
+	ClientOrderID  string
+	Ticker         string
+	Side           Side
+	Action         Action
+	Type           string
+	Status         OrderStatus
+	Price          money.Micros // limit price of Side
+	InitialCount   int
+	FillCount      int
+	RemainingCount int
+	TakerFees      money.Micros
+	MakerFees      money.Micros
+	CreatedAt      time.Time
+}
+
+// Fill is one execution against one of our orders.
+type Fill struct {
+	TradeID   string
+	OrderID   string
+	Ticker    string
+	Side      Side
+	Action    Action
+	Count     int
+	Price     money.Micros // price of Side
+	IsTaker   bool
+	CreatedAt time.Time
+}
+
+// MarketPosition is the net holding in one market: positive counts are YES
+// contracts, negative counts NO contracts.
+type MarketPosition struct {
+	Ticker        string
+	Position      int
+	ExposureUSD   money.Micros
+	RealizedPnL   money.Micros
+	FeesPaidUSD   money.Micros
+	RestingOrders int
+}
+
+// FillsQuery filters Fills; empty fields match everything.
+type FillsQuery struct {
+	Ticker  string
+	OrderID string
+	MinTime time.Time
+}
+
+// CreateOrder submits a limit order.
+func (c *TradingClient) CreateOrder(ctx context.Context, req OrderRequest) (*Order, error) {
+	if req.Ticker == "" || req.Count <= 0 {
+		return nil, fmt.Errorf("kalshi order: ticker and positive count required")
+	}
+	body := wireOrderRequest{
+		Ticker:        req.Ticker,
+		ClientOrderID: req.ClientOrderID,
+		Side:          req.Side,
+		Action:        req.Action,
+		Count:         req.Count,
+		Type:          "limit",
+		TimeInForce:   req.TimeInForce,
+		PostOnly:      req.PostOnly,
+	}
+	if err := setPrice(req.Side, req.Price, &body.YesPrice, &body.NoPrice); err != nil {
+		return nil, err
+	}
+	var out struct {
+		Order wireOrder `json:"order"`
+	}
+	if err := c.call(ctx, http.MethodPost, "/portfolio/orders", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return out.Order.normalize(), nil
+}
+
+// CancelOrder cancels the unfilled part of an order and returns it.
+func (c *TradingClient) CancelOrder(ctx context.Context, orderID string) (*Order, error) {
+	var out struct {
+		Order wireOrder `json:"order"`
+	}
+	if err := c.call(ctx, http.MethodDelete, "/portfolio/orders/"+url.PathEscape(orderID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Order.normalize(), nil
+}
+
+// AmendOrder re-prices and/or resizes a resting order; the amended order
+// is returned.
+func (c *TradingClient) AmendOrder(ctx context.Context, orderID string, req AmendRequest) (*Order, error) {
+	body := wireAmendRequest{
+		Ticker:               req.Ticker,
+		Side:                 req.Side,
+		Action:               req.Action,
+		ClientOrderID:        req.ClientOrderID,
+		UpdatedClientOrderID: req.UpdatedClientOrderID,
+		Count:                req.Count,
+	}
+	if err := setPrice(req.Side, req.Price, &body.YesPrice, &body.NoPrice); err != nil {
+		return nil, err
+	}
+	var out struct {
+		Order wireOrder `json:"order"`
+	}
+	if err := c.call(ctx, http.MethodPost, "/portfolio/orders/"+url.PathEscape(orderID)+"/amend", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return out.Order.normalize(), nil
+}
+
+// GetOrder fetches one order.
+func (c *TradingClient) GetOrder(ctx context.Context, orderID string) (*Order, error) {
+	var out struct {
+		Order wireOrder `json:"order"`
+	}
+	if err := c.call(ctx, http.MethodGet, "/portfolio/orders/"+url.PathEscape(orderID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Order.normalize(), nil
+}
+
+// Fills lists our executions, following the cursor through every page.
+func (c *TradingClient) Fills(ctx context.Context, q FillsQuery) ([]Fill, error) {
+	params := url.Values{}
+	params.Set("limit", "200")
+	if q.Ticker != "" {
+		params.Set("ticker", q.Ticker)
+	}
+	if q.OrderID != "" {
+		params.Set("order_id", q.OrderID)
+	}
+	if !q.MinTime.IsZero() {
+		params.Set("min_ts", strconv.FormatInt(q.MinTime.Unix(), 10))
+	}
+	var fills []Fill
+	for {
+		var out struct {
+			Fills  []wireFill `json:"fills"`
+			Cursor string     `json:"cursor"`
+		}
+		if err := c.call(ctx, http.MethodGet, "/portfolio/fills", params, nil, &out); err != nil {
+			return nil, err
+		}
+		for _, f := range out.Fills {
+			fills = append(fills, f.normalize())
+		}
+		if out.Cursor == "" || len(out.Fills) == 0 {
+			return fills, nil
+		}
+		params.Set("cursor", out.Cursor)
+	}
+}
+
+// Positions lists non-zero market positions, following the cursor.
+func (c *TradingClient) Positions(ctx context.Context) ([]MarketPosition, error) {
+	params := url.Values{}
+	params.Set("limit", "200")
+	params.Set("count_filter", "position")
+	var positions []MarketPosition
+	for {
+		var out struct {
+			MarketPositions []wirePosition `json:"market_positions"`
+			Cursor          string         `json:"cursor"`
+		}
+		if err := c.call(ctx, http.MethodGet, "/portfolio/positions", params, nil, &out); err != nil {
+			return nil, err
+		}
+		for _, p := range out.MarketPositions {
+			positions = append(positions, MarketPosition{
+				Ticker:        p.Ticker,
+				Position:      p.Position,
+				ExposureUSD:   money.FromCents(p.MarketExposure),
+				RealizedPnL:   money.FromCents(p.RealizedPnL),
+				FeesPaidUSD:   money.FromCents(p.FeesPaid),
+				RestingOrders: p.RestingOrdersCount,
+			})
+		}
+		if out.Cursor == "" || len(out.MarketPositions) == 0 {
+			return positions, nil
+		}
+		params.Set("cursor", out.Cursor)
+	}
+}
+
+// Balance returns the available cash balance.
+func (c *TradingClient) Balance(ctx context.Context) (money.Micros, error) {
+	var out struct {
+		Balance int64 `json:"balance"`
+	}
+	if err := c.call(ctx, http.MethodGet, "/portfolio/balance", nil, nil, &out); err != nil {
+		return 0, err
+	}
+	return money.FromCents(out.Balance), nil
+}
+
+// APIError is a non-2xx response from the Trade API.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("kalshi API %d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("kalshi API %d: %s", e.StatusCode, e.Message)
+}
+
+// call signs and sends one request. Only GETs are retried: order writes are
+// not idempotent without a client order id, so the caller decides.
+func (c *TradingClient) call(ctx context.Context, method, path string, params url.Values, body, dst any) error {
+	u, err := url.Parse(c.baseURL + path)
+	if err != nil {
+		return err
+	}
+	if len(params) > 0 {
+		u.RawQuery = params.Encode()
+	}
+	var payload []byte
+	if body != nil {
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+	}
+
+	var attempt int
+	for {
+		attempt++
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		// The timestamp is part of the signature, so every attempt re-signs.
+		ts := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		sig, err := Sign(c.key, ts, method, u.Path)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(HeaderAccessKey, c.keyID)
+		req.Header.Set(HeaderAccessTimestamp, ts)
+		req.Header.Set(HeaderAccessSignature, sig)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			if method == http.MethodGet && shouldRetry(attempt, 0) {
+				sleep(attempt)
+				continue
+			}
+			return err
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			if dst == nil {
+				return nil
+			}
+			return json.NewDecoder(resp.Body).Decode(dst)
+		}
+
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		resp.Body.Close()
+		if method == http.MethodGet && shouldRetry(attempt, resp.StatusCode) {
+			sleep(attempt)
+			continue
+		}
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(raw))}
+		var wrapped struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(raw, &wrapped) == nil && wrapped.Error.Code != "" {
+			apiErr.Code = wrapped.Error.Code
+			apiErr.Message = wrapped.Error.Message
+		}
+		return apiErr
+	}
+}
+
+// setPrice writes a whole-cent price into the yes_price or no_price field.
+func setPrice(side Side, price money.Micros, yes, no *int64) error {
+	if price <= 0 {
+		return nil
+	}
+	cents, ok := price.Cents()
+	if !ok || cents < 1 || cents > 99 {
+		return fmt.Errorf("kalshi: price %s is not a whole cent between 1 and 99", price)
+	}
+	switch side {
+	case SideYes:
+		*yes = cents
+	case SideNo:
+		*no = cents
+	default:
+		return fmt.Errorf("kalshi: unknown side %q", side)
+	}
+	return nil
+}
+
+type wireOrderRequest struct {
+	Ticker        string      `json:"ticker"`
+	ClientOrderID string      `json:"client_order_id,omitempty"`
+	Side          Side        `json:"side"`
+	Action        Action      `json:"action"`
+	Count         int         `json:"count"`
+	Type          string      `json:"type"`
+	YesPrice      int64       `json:"yes_price,omitempty"`
+	NoPrice       int64       `json:"no_price,omitempty"`
+	TimeInForce   TimeInForce `json:"time_in_force,omitempty"`
+	PostOnly      bool        `json:"post_only,omitempty"`
+}
+
+type wireAmendRequest struct {
+	Ticker               string `json:"ticker"`
+	Side                 Side   `json:"side"`
+	Action               Action `json:"action"`
+	ClientOrderID        string `json:"client_order_id,omitempty"`
+	UpdatedClientOrderID string `json:"updated_client_order_id,omitempty"`
+	YesPrice             int64  `json:"yes_price,omitempty"`
+	NoPrice              int64  `json:"no_price,omitempty"`
+	Count                int    `json:"count,omitempty"`
+}
+
+type wireOrder struct {
+	OrderID        string      `json:"order_id"`
+	ClientOrderID  string      `json:"client_order_id"`
+	Ticker         string      `json:"ticker"`
+	Side           Side        `json:"side"`
+	Action         Action      `json:"action"`
+	Type           string      `json:"type"`
+	Status         OrderStatus `json:"status"`
+	YesPrice       int64       `json:"yes_price"`
+	NoPrice        int64       `json:"no_price"`
+	InitialCount   int         `json:"initial_count"`
+	FillCount      int         `json:"fill_count"`
+	RemainingCount int         `json:"remaining_count"`
+	TakerFees      int64       `json:"taker_fees"`
+	MakerFees      int64       `json:"maker_fees"`
+	CreatedTime    string      `json:"created_time"`
+}
+
+func (o wireOrder) normalize() *Order {
+	price := o.YesPrice
+	if o.Side == SideNo {
+		price = o.NoPrice
+	}
+	return &Order{
+		ID:             o.OrderID,
+		ClientOrderID:  o.ClientOrderID,
+		Ticker:         o.Ticker,
+		Side:           o.Side,
+		Action:         o.Action,
+		Type:           o.Type,
+		Status:         o.Status,
+		Price:          money.FromCents(price),
+		InitialCount:   o.InitialCount,
+		FillCount:      o.FillCount,
+		RemainingCount: o.RemainingCount,
+		TakerFees:      money.FromCents(o.TakerFees),
+		MakerFees:      money.FromCents(o.MakerFees),
+		CreatedAt:      parseAPITime(o.CreatedTime),
+	}
+}
+
+type wireFill struct {
+	TradeID     string `json:"trade_id"`
+	OrderID     string `json:"order_id"`
+	Ticker      string `json:"ticker"`
+	Side        Side   `json:"side"`
+	Action      Action `json:"action"`
+	Count       int    `json:"count"`
+	YesPrice    int64  `json:"yes_price"`
+	NoPrice     int64  `json:"no_price"`
+	IsTaker     bool   `json:"is_taker"`
+	CreatedTime string `json:"created_time"`
+}
+
+func (f wireFill) normalize() Fill {
+	price := f.YesPrice
+	if f.Side == SideNo {
+		price = f.NoPrice
+	}
+	return Fill{
+		TradeID:   f.TradeID,
+		OrderID:   f.OrderID,
+		Ticker:    f.Ticker,
+		Side:      f.Side,
+		Action:    f.Action,
+		Count:     f.Count,
+		Price:     money.FromCents(price),
+		IsTaker:   f.IsTaker,
+		CreatedAt: parseAPITime(f.CreatedTime),
+	}
+}
+
+type wirePosition struct {
+	Ticker             string `json:"ticker"`
+	Position           int    `json:"position"`
+	MarketExposure     int64  `json:"market_exposure"`
+	RealizedPnL        int64  `json:"realized_pnl"`
+	FeesPaid           int64  `json:"fees_paid"`
+	RestingOrdersCount int    `json:"resting_orders_count"`
+}
+
+func parseAPITime(raw string) time.Time {
+	if raw == "" {
+		return time.Time{}
+	}
+	ts, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}
+	}
+	return ts
+}
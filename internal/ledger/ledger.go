@@ -0,0 +1,274 @@
+// Package ledger keeps one position per traded pair — from live and dry-run
+// executions and from paper trades — and settles it on the markets' actual
+// resolutions once they are final. Realized P&L is the payout plus any sale
+// proceeds minus what was paid. A pair whose complete sets do not pay what
+// the strategy guarantees is flagged divergent: for cross-venue pairs that
+// means the two venues resolved the same question differently.
+package ledger
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/execution"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+	"github.com/hetulpatel/Arbitrage/internal/paper"
+)
+
+const epsilon = 1e-9
+
+// Source is the trading mode a position came from.
+type Source string
+
+const (
+	SourceLive   Source = "live"
+	SourceDryRun Source = "dry_run"
+	SourcePaper  Source = "paper"
+)
+
+// Status is the lifecycle state of a ledger position.
+type Status string
+
+const (
+	StatusOpen    Status = "open"
+	StatusSettled Status = "settled"
+)
+
+// Leg is one market held by a position.
+type Leg struct {
+	Venue    collectors.Venue `json:"venue"`
+	EventID  string           `json:"event_id,omitempty"`
+	MarketID string           `json:"market_id"`
+	Outcome  string           `json:"outcome"`
+	// Quantity is what is still held and will be paid at resolution.
+	Quantity float64 `json:"quantity"`
+	// CostUSD is everything paid for the leg, fees included; ProceedsUSD is
+	// what selling part of it back returned, net of fees.
+	CostUSD     money.Micros `json:"cost_usd"`
+	ProceedsUSD money.Micros `json:"proceeds_usd,omitempty"`
+	// Result, YesPayout and PayoutUSD are filled in at settlement.
+	Result    string       `json:"result,omitempty"`
+	YesPayout float64      `json:"yes_payout,omitempty"`
+	PayoutUSD money.Micros `json:"payout_usd,omitempty"`
+}
+
+// Position is what one execution or paper trade of a pair holds.
+type Position struct {
+	ID     int64  `json:"id,omitempty"`
+	Source Source `json:"source"`
+	// RefID is the execution or paper position the entry was built from.
+	RefID     int64             `json:"ref_id"`
+	PairID    string            `json:"pair_id"`
+	Strategy  string            `json:"strategy"`
+	Direction matches.Direction `json:"direction"`
+	Status    Status            `json:"status"`
+	Legs      []Leg             `json:"legs"`
+	// PayoutPerUnit is what one complete set is guaranteed to pay.
+	PayoutPerUnit float64      `json:"payout_per_unit"`
+	CostUSD       money.Micros `json:"cost_usd"`
+	ProceedsUSD   money.Micros `json:"proceeds_usd"`
+	PayoutUSD     money.Micros `json:"payout_usd"`
+	// Divergent marks a settled pair whose complete sets paid SetPayout
+	// rather than PayoutPerUnit.
+	Divergent bool      `json:"divergent"`
+	SetPayout float64   `json:"set_payout"`
+	OpenedAt  time.Time `json:"opened_at"`
+	SettlesAt time.Time `json:"settles_at,omitempty"`
+	SettledAt time.Time `json:"settled_at,omitempty"`
+}
+
+// RealizedUSD is payout plus proceeds minus cost once settled.
+func (p *Position) RealizedUSD() money.Micros {
+	if p.Status != StatusSettled {
+		return 0
+	}
+	return p.PayoutUSD + p.ProceedsUSD - p.CostUSD
+}
+
+// FromExecution builds the ledger entry of a finished execution. It returns
+// false for executions that never bought anything.
+func FromExecution(e *execution.Execution) (Position, bool) {
+	pos := Position{
+		Source:        SourceLive,
+		RefID:         e.ID,
+		PairID:        e.PairID,
+		Strategy:      e.Direction.Strategy(),
+		Direction:     e.Direction,
+		Status:        StatusOpen,
+		PayoutPerUnit: e.PayoutPerUnit,
+		OpenedAt:      e.CreatedAt,
+		SettlesAt:     e.SettlesAt,
+	}
+	if e.DryRun {
+		pos.Source = SourceDryRun
+	}
+	bought := false
+	for i := range e.Legs {
+		leg := &e.Legs[i]
+		bought = bought || leg.Filled > epsilon
+		pos.addLeg(Leg{
+			Venue:       collectors.Venue(leg.Venue),
+			EventID:     leg.EventID,
+			MarketID:    leg.MarketID,
+			Outcome:     leg.Outcome,
+			Quantity:    leg.Held(),
+			CostUSD:     leg.CostUSD + leg.FeeUSD,
+			ProceedsUSD: leg.ProceedsUSD - leg.SellFeeUSD,
+		})
+	}
+	return pos, bought
+}
+
+// FromPaper builds the ledger entry of a paper position.
+func FromPaper(p *paper.Position) Position {
+	pos := Position{
+		Source:        SourcePaper,
+		RefID:         p.ID,
+		PairID:        p.PairID,
+		Strategy:      p.Strategy,
+		Direction:     p.Direction,
+		Status:        StatusOpen,
+		PayoutPerUnit: p.PayoutPerUnit,
+		OpenedAt:      p.OpenedAt,
+		SettlesAt:     p.SettlesAt,
+	}
+	for _, leg := range p.Legs {
+		pos.addLeg(Leg{
+			Venue:    leg.Venue,
+			EventID:  leg.EventID,
+			MarketID: leg.MarketID,
+			Outcome:  leg.Outcome,
+			Quantity: leg.Quantity,
+			CostUSD:  leg.CostUSD + leg.FeeUSD,
+		})
+	}
+	return pos
+}
+
+func (p *Position) addLeg(leg Leg) {
+	p.Legs = append(p.Legs, leg)
+	p.CostUSD += leg.CostUSD
+	p.ProceedsUSD += leg.ProceedsUSD
+}
+
+// Holding is what an open position still holds, in the shape the unwind
+// scanner prices. The entry cost is net of any proceeds already taken, and a
+// leg's average price is its cost over what is left. It returns false when
+// fewer than two legs are still held.
+func (p *Position) Holding() (matches.Position, bool) {
+	out := matches.Position{
+		PairID:        p.PairID,
+		PayoutPerUnit: p.PayoutPerUnit,
+		EntryCostUSD:  p.CostUSD - p.ProceedsUSD,
+	}
+	for _, leg := range p.Legs {
+		if leg.Quantity <= epsilon {
+			continue
+		}
+		out.Legs = append(out.Legs, matches.PositionLeg{
+			MarketRef: matches.MarketRef{Venue: leg.Venue, EventID: leg.EventID, MarketID: leg.MarketID},
+			Outcome:   leg.Outcome,
+			Quantity:  leg.Quantity,
+			AvgPrice:  (leg.CostUSD - leg.ProceedsUSD).Float() / leg.Quantity,
+		})
+	}
+	return out, p.Status == StatusOpen && len(out.Legs) >= 2
+}
+
+// Due reports whether the position's markets have closed and it is worth
+// polling for resolutions.
+func (p *Position) Due(now time.Time) bool {
+	return p.Status == StatusOpen && !now.Before(p.SettlesAt)
+}
+
+// MarketKey identifies a market across venues.
+func MarketKey(venue collectors.Venue, marketID string) string {
+	return string(venue) + ":" + marketID
+}
+
+// Settle pays every leg at its market's resolution, keyed by MarketKey. It
+// does nothing and returns false until all of them have resolved.
+func (p *Position) Settle(resolutions map[string]collectors.Resolution, now time.Time) bool {
+	for _, leg := range p.Legs {
+		if res, ok := resolutions[MarketKey(leg.Venue, leg.MarketID)]; !ok || !res.Resolved {
+			return false
+		}
+	}
+	p.PayoutUSD = 0
+	p.SetPayout = 0
+	for i := range p.Legs {
+		leg := &p.Legs[i]
+		res := resolutions[MarketKey(leg.Venue, leg.MarketID)]
+		unit := res.Payout(leg.Outcome)
+		leg.Result = res.Result
+		leg.YesPayout = res.YesPayout
+		leg.PayoutUSD = money.FromFloat(unit * leg.Quantity)
+		p.PayoutUSD += leg.PayoutUSD
+		p.SetPayout += unit
+	}
+	// Cross-venue legs are complements of the same question, so exactly
+	// the guaranteed payout is the only consistent result; the other
+	// strategies guarantee a floor.
+	if p.Strategy == matches.StrategyCrossVenue {
+		p.Divergent = math.Abs(p.SetPayout-p.PayoutPerUnit) > epsilon
+	} else {
+		p.Divergent = p.SetPayout < p.PayoutPerUnit-epsilon
+	}
+	p.Status = StatusSettled
+	p.SettledAt = now
+	return true
+}
+
+// SourcePnL aggregates ledger positions of one source and strategy.
+type SourcePnL struct {
+	Source      Source       `json:"source"`
+	Strategy    string       `json:"strategy"`
+	Open        int          `json:"open"`
+	Settled     int          `json:"settled"`
+	Divergent   int          `json:"divergent"`
+	CostUSD     money.Micros `json:"cost_usd"`
+	RealizedUSD money.Micros `json:"realized_usd"`
+}
+
+// Summarize groups positions by source and strategy, sorted by both.
+func Summarize(positions []Position) []SourcePnL {
+	type key struct {
+		source   Source
+		strategy string
+	}
+	groups := make(map[key]*SourcePnL)
+	for i := range positions {
+		p := &positions[i]
+		k := key{p.Source, p.Strategy}
+		s := groups[k]
+		if s == nil {
+			s = &SourcePnL{Source: p.Source, Strategy: p.Strategy}
+			groups[k] = s
+		}
+		switch p.Status {
+		case StatusOpen:
+			s.Open++
+		case StatusSettled:
+			s.Settled++
+		}
+		if p.Divergent {
+			s.Divergent++
+		}
+		s.CostUSD += p.CostUSD
+		s.RealizedUSD += p.RealizedUSD()
+	}
+	out := make([]SourcePnL, 0, len(groups))
+	for _, s := range groups {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Source != out[j].Source {
+			return out[i].Source < out[j].Source
+		}
+		return out[i].Strategy < out[j].Strategy
+	})
+	return out
+}
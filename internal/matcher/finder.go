@@ -39,6 +39,10 @@ type Result struct {
 	Similarity    float64
 	Distance      float64
 	CachedVerdict bool
+	// CachedMapping is the outcome mapping of a cached SAFE verdict.
+	CachedMapping matches.OutcomeMapping
+	// CachedConfidence is the validator confidence stored with it.
+	CachedConfidence float64
 }
 
 func NewFinder(cfg Config) (*Finder, error) {
@@ -205,13 +209,19 @@ func (f *Finder) checkVerdictCache(ctx context.Context, source, target *models.M
 		return nil, false
 	}
 
-	if verdict {
-		logging.Infof("[verdict-cache] hit SAFE key=%s similarity=%.4f", key, similarity)
+	if verdict.Valid {
+		mapping := matches.OutcomeSame
+		if verdict.Inverted {
+			mapping = matches.OutcomeInverted
+		}
+		logging.Infof("[verdict-cache] hit SAFE key=%s similarity=%.4f mapping=%s", key, similarity, mapping)
 		return &Result{
-			Target:        target,
-			Similarity:    similarity,
-			Distance:      distance,
-			CachedVerdict: true,
+			Target:           target,
+			Similarity:       similarity,
+			Distance:         distance,
+			CachedVerdict:    true,
+			CachedMapping:    mapping,
+			CachedConfidence: verdict.Confidence,
 		}, true
 	}
 
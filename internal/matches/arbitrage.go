@@ -1,29 +1,222 @@
 package matches
 
+import (
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
 type Direction string
 
 const (
 	DirectionNone                Direction = ""
 	DirectionBuyYesPMBuyNoKalshi Direction = "BUY_YES_PM_BUY_NO_KALSHI"
 	DirectionBuyNoPMBuyYesKalshi Direction = "BUY_NO_PM_BUY_YES_KALSHI"
+	// Inverted pairs (one market is the other's negation) hedge with the
+	// same outcome on both venues.
+	DirectionBuyYesPMBuyYesKalshi Direction = "BUY_YES_PM_BUY_YES_KALSHI"
+	DirectionBuyNoPMBuyNoKalshi   Direction = "BUY_NO_PM_BUY_NO_KALSHI"
+	// DirectionBuyYesBasket buys YES on every outcome of a mutually exclusive
+	// event, each leg on whichever venue is cheaper.
+	DirectionBuyYesBasket Direction = "BUY_YES_BASKET"
+	// Single-venue boxes: buy YES and NO on the same market.
+	DirectionBoxPolymarket Direction = "BUY_YES_NO_POLYMARKET"
+	DirectionBoxKalshi     Direction = "BUY_YES_NO_KALSHI"
+	// DirectionImplication buys YES on the looser market and NO on the
+	// stricter one of an "A implies B" pair.
+	DirectionImplication Direction = "BUY_YES_LOOSER_BUY_NO_STRICTER"
+	// DirectionRangeSynthetic buys YES on adjacent range buckets of one venue
+	// and NO on the bucket of the other venue they exactly cover.
+	DirectionRangeSynthetic Direction = "BUY_YES_BUCKETS_BUY_NO_RANGE"
+)
+
+// Strategy families used to group results (paper P&L, exposure) across
+// directions.
+const (
+	StrategyCrossVenue  = "cross_venue"
+	StrategyBox         = "box"
+	StrategyImplication = "implication"
+	StrategyBasket      = "basket"
+	StrategyRange       = "range"
 )
 
+// Strategy returns the strategy family the direction belongs to.
+func (d Direction) Strategy() string {
+	switch d {
+	case DirectionBoxPolymarket, DirectionBoxKalshi:
+		return StrategyBox
+	case DirectionImplication:
+		return StrategyImplication
+	case DirectionBuyYesBasket:
+		return StrategyBasket
+	case DirectionRangeSynthetic:
+		return StrategyRange
+	default:
+		return StrategyCrossVenue
+	}
+}
+
 type Leg struct {
-	Venue    string  `json:"venue"`
-	Side     string  `json:"side"`
-	Outcome  string  `json:"outcome"`
-	AvgPrice float64 `json:"avg_price"`
-	Quantity float64 `json:"quantity"`
-	CostUSD  float64 `json:"cost_usd"`
+	Venue    string       `json:"venue"`
+	MarketID string       `json:"market_id,omitempty"`
+	Side     string       `json:"side"`
+	Outcome  string       `json:"outcome"`
+	AvgPrice float64      `json:"avg_price"`
+	Quantity float64      `json:"quantity"`
+	CostUSD  money.Micros `json:"cost_usd"`
 }
 
+// Opportunity amounts that come from walking the books (cost, profit, fees)
+// are exact money.Micros; ratios and the analytics curve stay float64.
 type Opportunity struct {
-	Direction         Direction `json:"direction"`
-	Quantity          float64   `json:"quantity"`
-	ProfitUSD         float64   `json:"profit_usd"`
-	TotalCostUSD      float64   `json:"total_cost_usd"`
-	BudgetUSD         float64   `json:"budget_usd"`
-	KalshiFeesUSD     float64   `json:"kalshi_fees_usd"`
-	PolymarketFeesUSD float64   `json:"polymarket_fees_usd"`
-	Legs              []Leg     `json:"legs"`
+	Direction         Direction    `json:"direction"`
+	Quantity          float64      `json:"quantity"`
+	ProfitUSD         money.Micros `json:"profit_usd"`
+	TotalCostUSD      money.Micros `json:"total_cost_usd"`
+	BudgetUSD         float64      `json:"budget_usd"`
+	KalshiFeesUSD     money.Micros `json:"kalshi_fees_usd"`
+	PolymarketFeesUSD money.Micros `json:"polymarket_fees_usd"`
+	Legs              []Leg        `json:"legs"`
+	// TheoreticalQuantity/ProfitUSD describe the unrounded optimum; Quantity
+	// and ProfitUSD are what remains after lot and tick rounding.
+	TheoreticalQuantity  float64      `json:"theoretical_quantity,omitempty"`
+	TheoreticalProfitUSD money.Micros `json:"theoretical_profit_usd,omitempty"`
+	// ReturnOnCapital is ProfitUSD / TotalCostUSD. AnnualizedYield scales it
+	// by how long the capital is locked until SettlesAt, the later close time
+	// of the legs.
+	ReturnOnCapital float64   `json:"return_on_capital"`
+	AnnualizedYield float64   `json:"annualized_yield"`
+	SettlesAt       time.Time `json:"settles_at,omitempty"`
+	// Score is the composite ranking score (see ScoreWeights) in [0, 1].
+	Score float64 `json:"score,omitempty"`
+	// PayoutFloorUSD and PayoutMaxUSD bound the settlement value when it
+	// depends on the outcome (implication arbs pay $1 or $2 per contract).
+	// ProfitUSD is always computed from the floor.
+	PayoutFloorUSD float64 `json:"payout_floor_usd,omitempty"`
+	PayoutMaxUSD   float64 `json:"payout_max_usd,omitempty"`
+	// PayoutPerUnitUSD is what one complete set is guaranteed to pay at
+	// settlement, per contract; zero means the usual $1. Use PayoutPerUnit.
+	PayoutPerUnitUSD float64 `json:"payout_per_unit_usd,omitempty"`
+	// Coverage proves that synthetic range legs pay exactly $1.
+	Coverage *CoverageProof `json:"coverage,omitempty"`
+	// LegRisk summarises a Monte Carlo of sequential leg execution.
+	LegRisk *LegRisk `json:"leg_risk,omitempty"`
+	// Curve is the cumulative profit at every ladder slice walked within the
+	// budget. Quantity is the point on this curve with the highest profit.
+	Curve []CurvePoint `json:"curve,omitempty"`
+	// Orders is the execution plan: one limit order per leg at the worst
+	// price crossed, with fees computed on the whole order.
+	Orders []Order `json:"orders,omitempty"`
+}
+
+// LegRisk is the outcome distribution of executing the order plan one leg
+// at a time, with books moving and fills coming up short in between.
+type LegRisk struct {
+	Trials            int     `json:"trials"`
+	LegDelaySeconds   float64 `json:"leg_delay_seconds"`
+	ExpectedProfitUSD float64 `json:"expected_profit_usd"`
+	WorstCaseLossUSD  float64 `json:"worst_case_loss_usd"`
+	LossProbability   float64 `json:"loss_probability"`
+}
+
+// Order is a single limit order of an opportunity's execution plan.
+type Order struct {
+	Venue      string       `json:"venue"`
+	MarketID   string       `json:"market_id"`
+	Side       string       `json:"side"`
+	Outcome    string       `json:"outcome"`
+	Type       string       `json:"type"`
+	LimitPrice money.Micros `json:"limit_price"`
+	Quantity   float64      `json:"quantity"`
+	FeeUSD     money.Micros `json:"fee_usd"`
+}
+
+// CurvePoint is the cumulative fill after one slice of the ladder walk.
+type CurvePoint struct {
+	Quantity     float64 `json:"quantity"`
+	TotalCostUSD float64 `json:"total_cost_usd"`
+	ProfitUSD    float64 `json:"profit_usd"`
+	MarginalCost float64 `json:"marginal_cost"` // per-contract cost (incl. fees) of this slice
+}
+
+// Edge returns profit as a fraction of capital spent.
+func (p CurvePoint) Edge() float64 {
+	if p.TotalCostUSD <= 0 {
+		return 0
+	}
+	return p.ProfitUSD / p.TotalCostUSD
+}
+
+// PayoutPerUnit is what one complete set is guaranteed to pay at settlement.
+func (o *Opportunity) PayoutPerUnit() float64 {
+	if o == nil || o.PayoutPerUnitUSD <= 0 {
+		return 1
+	}
+	return o.PayoutPerUnitUSD
+}
+
+// MaxProfitPoint returns the curve point with the highest profit.
+func (o *Opportunity) MaxProfitPoint() (CurvePoint, bool) {
+	if o == nil || len(o.Curve) == 0 {
+		return CurvePoint{}, false
+	}
+	best := o.Curve[0]
+	for _, p := range o.Curve[1:] {
+		if p.ProfitUSD > best.ProfitUSD {
+			best = p
+		}
+	}
+	return best, true
+}
+
+// MaxSizeAtEdge returns the largest curve point whose cumulative edge is at
+// least minEdge (e.g. 0.02 for 2%).
+func (o *Opportunity) MaxSizeAtEdge(minEdge float64) (CurvePoint, bool) {
+	if o == nil {
+		return CurvePoint{}, false
+	}
+	var out CurvePoint
+	found := false
+	for _, p := range o.Curve {
+		if p.ProfitUSD > 0 && p.Edge() >= minEdge && p.Quantity > out.Quantity {
+			out = p
+			found = true
+		}
+	}
+	return out, found
+}
+
+// RankMetric selects how opportunities are compared for ranking and dedup.
+type RankMetric string
+
+const (
+	RankByProfit RankMetric = "profit"
+	RankByYield  RankMetric = "yield"
+	// RankByScore uses the composite Opportunity.Score.
+	RankByScore RankMetric = "score"
+)
+
+// ParseRankMetric maps a config string to a metric, defaulting to profit.
+func ParseRankMetric(raw string) RankMetric {
+	switch RankMetric(raw) {
+	case RankByYield, RankByScore:
+		return RankMetric(raw)
+	default:
+		return RankByProfit
+	}
+}
+
+// Score returns the value of o under the metric; higher is better.
+func (m RankMetric) Score(o *Opportunity) float64 {
+	if o == nil {
+		return 0
+	}
+	switch m {
+	case RankByYield:
+		return o.AnnualizedYield
+	case RankByScore:
+		return o.Score
+	default:
+		return o.ProfitUSD.Float()
+	}
 }
@@ -0,0 +1,71 @@
+package matches
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Interval is the range a range market resolves YES on: half-open [Lo, Hi)
+// unless LoOpen excludes Lo or HiClosed includes Hi. A nil bound is
+// unbounded.
+type Interval struct {
+	Lo *float64 `json:"lo,omitempty"`
+	Hi *float64 `json:"hi,omitempty"`
+
+	LoOpen   bool `json:"lo_open,omitempty"`
+	HiClosed bool `json:"hi_closed,omitempty"`
+}
+
+// NewInterval builds an Interval, mapping infinite bounds to nil.
+func NewInterval(lo, hi float64) Interval {
+	var out Interval
+	if !math.IsInf(lo, 0) {
+		out.Lo = &lo
+	}
+	if !math.IsInf(hi, 0) {
+		out.Hi = &hi
+	}
+	return out
+}
+
+func (i Interval) String() string {
+	lo, hi := "-inf", "+inf"
+	if i.Lo != nil {
+		lo = strconv.FormatFloat(*i.Lo, 'f', -1, 64)
+	}
+	if i.Hi != nil {
+		hi = strconv.FormatFloat(*i.Hi, 'f', -1, 64)
+	}
+	open, close := "[", ")"
+	if i.LoOpen {
+		open = "("
+	}
+	if i.HiClosed {
+		close = "]"
+	}
+	return fmt.Sprintf("%s%s, %s%s", open, lo, hi, close)
+}
+
+// CoverageProof records why a synthetic range position pays exactly $1:
+// the YES pieces are adjacent, disjoint and tile Target, which is bought as
+// NO on the other venue. Exactly one leg pays in every outcome.
+type CoverageProof struct {
+	Target    Interval   `json:"target"`
+	Pieces    []Interval `json:"pieces"`
+	Statement string     `json:"statement"`
+}
+
+// NewCoverageProof renders the proof statement for pieces tiling target.
+func NewCoverageProof(target Interval, pieces []Interval) *CoverageProof {
+	parts := make([]string, len(pieces))
+	for i, p := range pieces {
+		parts[i] = p.String()
+	}
+	return &CoverageProof{
+		Target:    target,
+		Pieces:    pieces,
+		Statement: fmt.Sprintf("%s = %s; YES pays iff inside, NO pays iff outside", strings.Join(parts, " ∪ "), target),
+	}
+}
@@ -0,0 +1,48 @@
+package matches
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+)
+
+// MarketRef identifies a market on a venue.
+type MarketRef struct {
+	Venue    collectors.Venue `json:"venue"`
+	EventID  string           `json:"event_id"`
+	MarketID string           `json:"market_id"`
+}
+
+// Implication is a validated "Stricter implies Looser" relation between two
+// threshold markets: whenever Stricter resolves YES, Looser must too (e.g.
+// "BTC above $110k by Dec 31" implies "BTC above $100k by Dec 31").
+type Implication struct {
+	Stricter MarketRef `json:"stricter"`
+	Looser   MarketRef `json:"looser"`
+	Note     string    `json:"note,omitempty"`
+}
+
+// LoadImplications reads a JSON array of implications from path. Only the
+// file's shape is checked here; arb.ValidateImplication checks each relation
+// against the live markets.
+func LoadImplications(path string) ([]Implication, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read implications: %w", err)
+	}
+	var out []Implication
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("parse implications: %w", err)
+	}
+	for i, imp := range out {
+		if imp.Stricter.MarketID == "" || imp.Looser.MarketID == "" {
+			return nil, fmt.Errorf("implication %d: market ids required", i)
+		}
+		if imp.Stricter == imp.Looser {
+			return nil, fmt.Errorf("implication %d: stricter and looser are the same market", i)
+		}
+	}
+	return out, nil
+}
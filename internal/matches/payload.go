@@ -5,6 +5,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
 	"github.com/hetulpatel/Arbitrage/internal/hashutil"
 	"github.com/hetulpatel/Arbitrage/internal/models"
 )
@@ -22,6 +23,7 @@ type Payload struct {
 	ResolutionVerdict *ResolutionVerdict    `json:"resolution_verdict,omitempty"`
 	Fresh             *FreshSnapshots       `json:"fresh,omitempty"`
 	FinalOpportunity  *Opportunity          `json:"final_opportunity,omitempty"`
+	BudgetSweep       []*Opportunity        `json:"budget_sweep,omitempty"`
 	CachedVerdict     bool                  `json:"cached_verdict,omitempty"`
 }
 
@@ -40,6 +42,41 @@ func NewPayload(source, target models.MarketSnapshot, similarity, distance float
 	}
 }
 
+// NewBoxPayload wraps a single snapshot so single-venue box results can share
+// the match persistence path. Source and Target are the same market.
+func NewBoxPayload(snap models.MarketSnapshot) Payload {
+	return Payload{
+		Version:    payloadVersion,
+		PairID:     buildPairID(&snap, &snap),
+		Similarity: 1,
+		MatchedAt:  time.Now().UTC(),
+		Source:     snap,
+		Target:     snap,
+	}
+}
+
+// NewEventPayload wraps two whole events so basket results share the match
+// persistence path. Source and Target carry the events without a market and
+// the pair ID is built from the event IDs.
+func NewEventPayload(pm, kx collectors.Event) Payload {
+	now := time.Now().UTC()
+	source := models.NewSnapshot(pm.Venue, pm, collectors.Market{}, now)
+	target := models.NewSnapshot(kx.Venue, kx, collectors.Market{}, now)
+	parts := []string{
+		fmt.Sprintf("%s:event:%s", pm.Venue, pm.EventID),
+		fmt.Sprintf("%s:event:%s", kx.Venue, kx.EventID),
+	}
+	sort.Strings(parts)
+	return Payload{
+		Version:    payloadVersion,
+		PairID:     hashutil.HashStrings(parts...),
+		Similarity: 1,
+		MatchedAt:  now,
+		Source:     source,
+		Target:     target,
+	}
+}
+
 func buildPairID(a, b *models.MarketSnapshot) string {
 	left := fmt.Sprintf("%s:%s", a.Venue, a.Market.MarketID)
 	right := fmt.Sprintf("%s:%s", b.Venue, b.Market.MarketID)
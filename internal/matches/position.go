@@ -0,0 +1,85 @@
+package matches
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+// PositionLeg is one held leg of a hedged position.
+type PositionLeg struct {
+	MarketRef
+	Outcome  string  `json:"outcome"`
+	Quantity float64 `json:"quantity"`
+	AvgPrice float64 `json:"avg_price"`
+}
+
+// Position is a hedged set of legs that together pay PayoutPerUnit per
+// contract at settlement (1 for a plain cross-venue pair).
+type Position struct {
+	PairID        string        `json:"pair_id"`
+	Legs          []PositionLeg `json:"legs"`
+	PayoutPerUnit float64       `json:"payout_per_unit,omitempty"`
+	EntryCostUSD  money.Micros  `json:"entry_cost_usd,omitempty"`
+}
+
+// HedgedQuantity is the number of complete sets held: the smallest leg.
+func (p Position) HedgedQuantity() float64 {
+	qty := 0.0
+	for i, leg := range p.Legs {
+		if i == 0 || leg.Quantity < qty {
+			qty = leg.Quantity
+		}
+	}
+	return qty
+}
+
+// LoadPositions reads a JSON array of held positions from path.
+func LoadPositions(path string) ([]Position, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read positions: %w", err)
+	}
+	var out []Position
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("parse positions: %w", err)
+	}
+	for i, pos := range out {
+		if pos.PairID == "" {
+			return nil, fmt.Errorf("position %d: pair_id required", i)
+		}
+		if len(pos.Legs) < 2 {
+			return nil, fmt.Errorf("position %s: at least two legs required", pos.PairID)
+		}
+		for _, leg := range pos.Legs {
+			if leg.MarketID == "" || leg.Quantity <= 0 {
+				return nil, fmt.Errorf("position %s: legs need a market id and positive quantity", pos.PairID)
+			}
+		}
+	}
+	return out, nil
+}
+
+// ExitSignal recommends selling (part of) a hedged position into the bids
+// because the proceeds beat holding to settlement.
+type ExitSignal struct {
+	PairID   string  `json:"pair_id"`
+	Quantity float64 `json:"quantity"`
+	// ProceedsUSD is what selling every leg returns, net of fees.
+	ProceedsUSD money.Micros `json:"proceeds_usd"`
+	FeesUSD     money.Micros `json:"fees_usd"`
+	// HoldValueUSD is the settlement payout for Quantity discounted to now.
+	HoldValueUSD money.Micros `json:"hold_value_usd"`
+	// EdgeUSD is ProceedsUSD minus HoldValueUSD.
+	EdgeUSD money.Micros `json:"edge_usd"`
+	// RealizedProfitUSD is ProceedsUSD minus the entry cost of Quantity,
+	// when the entry cost is known.
+	RealizedProfitUSD money.Micros `json:"realized_profit_usd,omitempty"`
+	SettlesAt         time.Time    `json:"settles_at,omitempty"`
+	Legs              []Leg        `json:"legs"`
+	Orders            []Order      `json:"orders"`
+	GeneratedAt       time.Time    `json:"generated_at"`
+}
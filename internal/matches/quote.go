@@ -0,0 +1,48 @@
+package matches
+
+import (
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+// QuoteStatus tells the consumer whether to (re)post or pull a quote.
+type QuoteStatus string
+
+const (
+	// QuotePost replaces any resting quote for the pair with this one.
+	QuotePost QuoteStatus = "post"
+	// QuoteCancel pulls the pair's resting quote; the edge is gone.
+	QuoteCancel QuoteStatus = "cancel"
+)
+
+// Quote is a maker-taker recommendation: rest Maker on one venue and, as it
+// fills, take Hedge on the other. Prices lock in EdgeUSD when the whole
+// quote fills and the hedge ladder is unchanged.
+type Quote struct {
+	PairID     string      `json:"pair_id"`
+	Status     QuoteStatus `json:"status"`
+	Direction  Direction   `json:"direction,omitempty"`
+	MakerVenue string      `json:"maker_venue,omitempty"`
+	Quantity   float64     `json:"quantity,omitempty"`
+	// Maker is the resting post-only order; its fee is the maker fee.
+	Maker *Order `json:"maker,omitempty"`
+	// Hedge is the taker order on the other venue, priced at the worst
+	// level its ladder walk crossed.
+	Hedge *Order `json:"hedge,omitempty"`
+	// HedgeCostUSD includes the hedge's taker fee; TotalCostUSD adds the
+	// maker leg and its fee.
+	HedgeCostUSD money.Micros `json:"hedge_cost_usd,omitempty"`
+	TotalCostUSD money.Micros `json:"total_cost_usd,omitempty"`
+	EdgeUSD      money.Micros `json:"edge_usd,omitempty"`
+	// MakerBestBid/Ask are the maker book when the quote was computed; a
+	// price above the best bid goes to the front of the queue.
+	MakerBestBid money.Micros `json:"maker_best_bid,omitempty"`
+	MakerBestAsk money.Micros `json:"maker_best_ask,omitempty"`
+	GeneratedAt  time.Time    `json:"generated_at"`
+}
+
+// AtTop reports whether the maker price improves the current best bid.
+func (q *Quote) AtTop() bool {
+	return q != nil && q.Maker != nil && q.Maker.LimitPrice > q.MakerBestBid
+}
@@ -0,0 +1,144 @@
+package matches
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScoreWeights weighs the components of the composite opportunity score.
+// Each component is normalised to [0, 1] and the score is their weighted
+// mean, so weights only need to be relative to each other.
+type ScoreWeights struct {
+	Profit     float64 `json:"profit"`
+	Yield      float64 `json:"yield"`
+	Depth      float64 `json:"depth"`
+	Similarity float64 `json:"similarity"`
+	Confidence float64 `json:"confidence"`
+	Freshness  float64 `json:"freshness"`
+	Close      float64 `json:"close"`
+}
+
+// Scales at which a component reaches 0.5.
+const (
+	scoreProfitScaleUSD = 10.0
+	scoreYieldScale     = 1.0 // 100% annualized
+	scoreDepthScale     = 500.0
+	scoreStaleScale     = 30 * time.Second
+	scoreCloseScale     = 30 * 24 * time.Hour
+	// unknownConfidence is used when no validator confidence is available.
+	unknownConfidence = 0.5
+)
+
+// DefaultScoreWeights favours profit and yield, then liquidity and how sure
+// we are that the legs resolve together.
+func DefaultScoreWeights() ScoreWeights {
+	return ScoreWeights{
+		Profit:     0.30,
+		Yield:      0.20,
+		Depth:      0.15,
+		Similarity: 0.10,
+		Confidence: 0.15,
+		Freshness:  0.05,
+		Close:      0.05,
+	}
+}
+
+// ParseScoreWeights reads "profit=0.4,yield=0.2,..." overrides on top of
+// the defaults. An empty string returns the defaults.
+func ParseScoreWeights(raw string) (ScoreWeights, error) {
+	w := DefaultScoreWeights()
+	if strings.TrimSpace(raw) == "" {
+		return w, nil
+	}
+	fields := map[string]*float64{
+		"profit":     &w.Profit,
+		"yield":      &w.Yield,
+		"depth":      &w.Depth,
+		"similarity": &w.Similarity,
+		"confidence": &w.Confidence,
+		"freshness":  &w.Freshness,
+		"close":      &w.Close,
+	}
+	for _, part := range strings.Split(raw, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ScoreWeights{}, fmt.Errorf("score weight %q: want name=value", part)
+		}
+		dst, known := fields[strings.ToLower(strings.TrimSpace(key))]
+		if !known {
+			return ScoreWeights{}, fmt.Errorf("unknown score weight %q", key)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil || f < 0 {
+			return ScoreWeights{}, fmt.Errorf("score weight %s: invalid value %q", key, val)
+		}
+		*dst = f
+	}
+	return w, nil
+}
+
+// ScoreInputs is the context an opportunity is scored in, beyond what the
+// opportunity itself carries.
+type ScoreInputs struct {
+	// Similarity is the matcher's embedding similarity (1 for same-market
+	// and structural relations).
+	Similarity float64
+	// Confidence is the validator's confidence in [0, 1]; zero means unknown.
+	Confidence float64
+	// DepthContracts is the smallest visible ask depth across the legs.
+	DepthContracts float64
+	// SnapshotAge is the age of the oldest leg snapshot.
+	SnapshotAge time.Duration
+	Now         time.Time
+}
+
+// Score returns the weighted mean of the normalised components; higher is
+// better and the result is in [0, 1].
+func (w ScoreWeights) Score(o *Opportunity, in ScoreInputs) float64 {
+	if o == nil {
+		return 0
+	}
+	total := w.Profit + w.Yield + w.Depth + w.Similarity + w.Confidence + w.Freshness + w.Close
+	if total <= 0 {
+		return 0
+	}
+	confidence := in.Confidence
+	if confidence <= 0 {
+		confidence = unknownConfidence
+	}
+	closeIn := time.Duration(0)
+	if !o.SettlesAt.IsZero() {
+		closeIn = o.SettlesAt.Sub(in.Now)
+	}
+	sum := w.Profit*saturate(o.ProfitUSD.Float(), scoreProfitScaleUSD) +
+		w.Yield*saturate(o.AnnualizedYield, scoreYieldScale) +
+		w.Depth*saturate(in.DepthContracts, scoreDepthScale) +
+		w.Similarity*clamp01(in.Similarity) +
+		w.Confidence*clamp01(confidence) +
+		w.Freshness*decay(in.SnapshotAge.Seconds(), scoreStaleScale.Seconds()) +
+		w.Close*decay(closeIn.Hours(), scoreCloseScale.Hours())
+	return sum / total
+}
+
+// saturate maps [0, inf) onto [0, 1), reaching 0.5 at scale.
+func saturate(v, scale float64) float64 {
+	if v <= 0 {
+		return 0
+	}
+	return v / (v + scale)
+}
+
+// decay maps [0, inf) onto (0, 1], halving at scale.
+func decay(v, scale float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	return scale / (v + scale)
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -1,15 +1,51 @@
 package matches
 
+import "strings"
+
+// OutcomeMapping says how YES on one venue relates to YES on the other.
+type OutcomeMapping string
+
+const (
+	// OutcomeSame means Polymarket YES and Kalshi YES resolve together.
+	OutcomeSame OutcomeMapping = "same"
+	// OutcomeInverted means one market is worded as the negation of the
+	// other, so Polymarket YES resolves with Kalshi NO.
+	OutcomeInverted OutcomeMapping = "inverted"
+)
+
+// ParseOutcomeMapping normalises validator output; anything unrecognised is
+// treated as the same polarity.
+func ParseOutcomeMapping(raw string) OutcomeMapping {
+	if strings.EqualFold(strings.TrimSpace(raw), string(OutcomeInverted)) {
+		return OutcomeInverted
+	}
+	return OutcomeSame
+}
+
 // ResolutionVerdict captures the validator's outcome for a pair of markets.
 type ResolutionVerdict struct {
-	ValidResolution  bool   `json:"ValidResolution"`
-	ResolutionReason string `json:"ResolutionReason"`
+	ValidResolution  bool           `json:"ValidResolution"`
+	ResolutionReason string         `json:"ResolutionReason"`
+	OutcomeMapping   OutcomeMapping `json:"OutcomeMapping,omitempty"`
+	// Confidence is the validator's confidence in the verdict, in [0, 1];
+	// zero when unknown.
+	Confidence float64 `json:"Confidence,omitempty"`
 }
 
 // NewResolutionVerdict builds a verdict struct.
-func NewResolutionVerdict(valid bool, reason string) *ResolutionVerdict {
+func NewResolutionVerdict(valid bool, reason string, mapping OutcomeMapping) *ResolutionVerdict {
+	if mapping == "" {
+		mapping = OutcomeSame
+	}
 	return &ResolutionVerdict{
 		ValidResolution:  valid,
 		ResolutionReason: reason,
+		OutcomeMapping:   mapping,
 	}
 }
+
+// Inverted reports whether the verdict pairs YES on one venue with NO on
+// the other.
+func (v *ResolutionVerdict) Inverted() bool {
+	return v != nil && v.OutcomeMapping == OutcomeInverted
+}
@@ -0,0 +1,201 @@
+// Package money carries prices, costs and fees as integer millionths of a
+// dollar so sums are exact and results reproduce bit for bit. Kalshi cents
+// and Polymarket decimal strings both convert without going through float64.
+package money
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Micros is an amount in millionths of a US dollar. It marshals to JSON as
+// a plain decimal number, so payload formats are unchanged.
+type Micros int64
+
+const (
+	// Micro is the smallest representable amount.
+	Micro Micros = 1
+	// Cent is $0.01.
+	Cent Micros = 10_000
+	// Dollar is $1.
+	Dollar Micros = 1_000_000
+
+	scale = 6
+)
+
+// FromCents converts an integer cent price (Kalshi's wire format).
+func FromCents(cents int64) Micros {
+	return Micros(cents) * Cent
+}
+
+// Cents converts back to Kalshi's integer cent format. ok is false when the
+// amount is not a whole number of cents.
+func (m Micros) Cents() (cents int64, ok bool) {
+	return int64(m / Cent), m%Cent == 0
+}
+
+// FromFloat rounds a float64 dollar amount to the nearest micro. Use it only
+// at the edges (formulas that are inherently real-valued, legacy inputs).
+func FromFloat(v float64) Micros {
+	return Micros(math.Round(v * float64(Dollar)))
+}
+
+// ParseDecimal parses a decimal dollar string such as "0.535" or "12" exactly.
+// Digits beyond the sixth decimal place are rejected rather than rounded.
+func ParseDecimal(s string) (Micros, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("money: empty amount")
+	}
+	neg := false
+	switch s[0] {
+	case '-':
+		neg = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("money: invalid amount %q", s)
+	}
+	if len(frac) > scale {
+		if strings.TrimRight(frac[scale:], "0") != "" {
+			return 0, fmt.Errorf("money: %q has more than %d decimal places", s, scale)
+		}
+		frac = frac[:scale]
+	}
+	var w, f int64
+	var err error
+	if whole != "" {
+		if w, err = strconv.ParseInt(whole, 10, 64); err != nil {
+			return 0, fmt.Errorf("money: invalid amount %q", s)
+		}
+	}
+	if frac != "" {
+		if f, err = strconv.ParseInt(frac+strings.Repeat("0", scale-len(frac)), 10, 64); err != nil {
+			return 0, fmt.Errorf("money: invalid amount %q", s)
+		}
+	}
+	if w > math.MaxInt64/int64(Dollar)-1 {
+		return 0, fmt.Errorf("money: %q out of range", s)
+	}
+	out := Micros(w)*Dollar + Micros(f)
+	if neg {
+		out = -out
+	}
+	return out, nil
+}
+
+// Float returns the amount in dollars.
+func (m Micros) Float() float64 {
+	return float64(m) / float64(Dollar)
+}
+
+// Mul prices a quantity of contracts at m each, rounded to the nearest
+// micro. With venue tick and lot sizes the product is already a whole
+// number of micros, so the rounding only removes float noise.
+func (m Micros) Mul(qty float64) Micros {
+	return Micros(math.Round(float64(m) * qty))
+}
+
+// Div returns the per-contract amount of m spread over qty.
+func (m Micros) Div(qty float64) Micros {
+	if qty == 0 {
+		return 0
+	}
+	return Micros(math.Round(float64(m) / qty))
+}
+
+// CeilTo rounds up to a multiple of unit (e.g. Cent for Kalshi fees).
+func (m Micros) CeilTo(unit Micros) Micros {
+	if unit <= 0 {
+		return m
+	}
+	q := m / unit
+	if m%unit > 0 {
+		q++
+	}
+	return q * unit
+}
+
+// FloorTo rounds down to a multiple of unit.
+func (m Micros) FloorTo(unit Micros) Micros {
+	if unit <= 0 {
+		return m
+	}
+	q := m / unit
+	if m%unit < 0 {
+		q--
+	}
+	return q * unit
+}
+
+// String formats the amount as a decimal without trailing zeros.
+func (m Micros) String() string {
+	sign := ""
+	v := int64(m)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	whole := v / int64(Dollar)
+	frac := v % int64(Dollar)
+	if frac == 0 {
+		return sign + strconv.FormatInt(whole, 10)
+	}
+	fs := strings.TrimRight(fmt.Sprintf("%06d", frac), "0")
+	return sign + strconv.FormatInt(whole, 10) + "." + fs
+}
+
+// Format lets %f, %g and %v verbs print the amount in dollars, so log lines
+// written for float64 keep working.
+func (m Micros) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'd':
+		fmt.Fprintf(s, "%d", int64(m))
+	case 's', 'v':
+		fmt.Fprint(s, m.String())
+	default:
+		format := "%"
+		for _, flag := range "+-# 0" {
+			if s.Flag(int(flag)) {
+				format += string(flag)
+			}
+		}
+		if w, ok := s.Width(); ok {
+			format += strconv.Itoa(w)
+		}
+		if p, ok := s.Precision(); ok {
+			format += "." + strconv.Itoa(p)
+		}
+		fmt.Fprintf(s, format+string(verb), m.Float())
+	}
+}
+
+// MarshalJSON writes the exact decimal value as a JSON number.
+func (m Micros) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or string. Numbers with more than six
+// decimal places (older float payloads) are rounded to the nearest micro.
+func (m *Micros) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" || s == "" {
+		*m = 0
+		return nil
+	}
+	if v, err := ParseDecimal(s); err == nil {
+		*m = v
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("money: invalid amount %s", data)
+	}
+	*m = FromFloat(f)
+	return nil
+}
@@ -0,0 +1,253 @@
+// Package paper simulates trading every published opportunity: legs are
+// filled against the next fresh books, held positions are marked to the
+// bids, and settled positions book their payout, so realized and
+// unrealized P&L can be tracked per strategy without risking capital.
+package paper
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/arb"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+const epsilon = 1e-9
+
+// Status is the lifecycle state of a paper position.
+type Status string
+
+const (
+	StatusOpen    Status = "open"
+	StatusSettled Status = "settled"
+)
+
+// Leg is one filled order of a paper position.
+type Leg struct {
+	matches.PositionLeg
+	// Ordered is the quantity the opportunity asked for; Quantity is what
+	// the book filled at or under the limit.
+	Ordered float64      `json:"ordered"`
+	CostUSD money.Micros `json:"cost_usd"`
+	FeeUSD  money.Micros `json:"fee_usd"`
+	// Category is the event's category, for risk limits.
+	Category string `json:"category,omitempty"`
+	// SlippageUSD is the fill cost minus the opportunity's planned average
+	// price for the same quantity; positive means we paid more.
+	SlippageUSD money.Micros `json:"slippage_usd"`
+	// MarkUSD is what selling the leg into the latest bids returns, net of
+	// taker fees.
+	MarkUSD money.Micros `json:"mark_usd"`
+}
+
+// Position is a simulated trade of one published opportunity.
+type Position struct {
+	ID        int64             `json:"id,omitempty"`
+	PairID    string            `json:"pair_id"`
+	Strategy  string            `json:"strategy"`
+	Direction matches.Direction `json:"direction"`
+	Status    Status            `json:"status"`
+	Legs      []Leg             `json:"legs"`
+	// PayoutPerUnit is what one complete set pays at settlement, per
+	// contract (the $1 floor for implication arbs).
+	PayoutPerUnit float64 `json:"payout_per_unit"`
+	// PlannedProfitUSD is the opportunity's profit as published.
+	PlannedProfitUSD money.Micros `json:"planned_profit_usd"`
+	// EntryCostUSD is every leg's fill cost plus fees.
+	EntryCostUSD money.Micros `json:"entry_cost_usd"`
+	FeesUSD      money.Micros `json:"fees_usd"`
+	SlippageUSD  money.Micros `json:"slippage_usd"`
+	// MarkValueUSD is the liquidation value at the last mark.
+	MarkValueUSD money.Micros `json:"mark_value_usd"`
+	// SettlementUSD is the cash booked at settlement.
+	SettlementUSD money.Micros `json:"settlement_usd,omitempty"`
+	OpenedAt      time.Time    `json:"opened_at"`
+	MarkedAt      time.Time    `json:"marked_at,omitempty"`
+	SettlesAt     time.Time    `json:"settles_at,omitempty"`
+	SettledAt     time.Time    `json:"settled_at,omitempty"`
+}
+
+// HedgedQuantity is the number of complete sets held: the smallest leg.
+func (p *Position) HedgedQuantity() float64 {
+	qty := 0.0
+	for i, leg := range p.Legs {
+		if i == 0 || leg.Quantity < qty {
+			qty = leg.Quantity
+		}
+	}
+	return qty
+}
+
+// UnrealizedUSD is the mark value minus entry cost while the position is
+// open; zero once settled.
+func (p *Position) UnrealizedUSD() money.Micros {
+	if p.Status != StatusOpen {
+		return 0
+	}
+	return p.MarkValueUSD - p.EntryCostUSD
+}
+
+// RealizedUSD is the settlement cash minus entry cost once settled.
+func (p *Position) RealizedUSD() money.Micros {
+	if p.Status != StatusSettled {
+		return 0
+	}
+	return p.SettlementUSD - p.EntryCostUSD
+}
+
+// Open fills every order of op against snaps, keyed by SnapshotKey, and
+// returns the resulting position. It fails when an order has no snapshot or
+// nothing fills at all; legs that fill short are kept as they are, so the
+// position can carry unhedged quantity exactly as a live trade would.
+func Open(pairID string, op *matches.Opportunity, snaps map[string]*models.MarketSnapshot, fees arb.FeeSchedule, now time.Time) (*Position, error) {
+	if op == nil || len(op.Orders) == 0 {
+		return nil, fmt.Errorf("opportunity has no orders")
+	}
+	pos := &Position{
+		PairID:           pairID,
+		Strategy:         op.Direction.Strategy(),
+		Direction:        op.Direction,
+		Status:           StatusOpen,
+		PayoutPerUnit:    op.PayoutPerUnit(),
+		PlannedProfitUSD: op.ProfitUSD,
+		OpenedAt:         now,
+		MarkedAt:         now,
+		SettlesAt:        op.SettlesAt,
+	}
+	filled := 0.0
+	for _, order := range op.Orders {
+		snap := snaps[SnapshotKey(order.Venue, order.MarketID)]
+		if snap == nil {
+			return nil, fmt.Errorf("no fresh snapshot for %s %s", order.Venue, order.MarketID)
+		}
+		fill := arb.SimulateBuy(order, snap, fees)
+		leg := Leg{
+			PositionLeg: matches.PositionLeg{
+				MarketRef: matches.MarketRef{Venue: snap.Venue, EventID: snap.Event.EventID, MarketID: order.MarketID},
+				Outcome:   order.Outcome,
+				Quantity:  fill.Quantity,
+				AvgPrice:  fill.AvgPrice(),
+			},
+			Ordered:  order.Quantity,
+			CostUSD:  fill.CostUSD,
+			FeeUSD:   fill.FeeUSD,
+			Category: snap.Event.Category,
+		}
+		if planned, ok := plannedPrice(op, order); ok {
+			leg.SlippageUSD = fill.CostUSD - money.FromFloat(planned*fill.Quantity)
+		}
+		pos.Legs = append(pos.Legs, leg)
+		pos.EntryCostUSD += fill.CostUSD + fill.FeeUSD
+		pos.FeesUSD += fill.FeeUSD
+		pos.SlippageUSD += leg.SlippageUSD
+		filled += fill.Quantity
+		if pos.SettlesAt.IsZero() || arb.CloseTime(snap).After(pos.SettlesAt) {
+			pos.SettlesAt = arb.CloseTime(snap)
+		}
+	}
+	if filled <= epsilon {
+		return nil, fmt.Errorf("no leg filled at its limit price")
+	}
+	pos.Mark(snaps, fees, now)
+	return pos, nil
+}
+
+// plannedPrice is the average price the opportunity expected for an order.
+func plannedPrice(op *matches.Opportunity, order matches.Order) (float64, bool) {
+	for _, leg := range op.Legs {
+		if leg.Venue == order.Venue && leg.MarketID == order.MarketID && leg.Outcome == order.Outcome {
+			return leg.AvgPrice, true
+		}
+	}
+	return 0, false
+}
+
+// Mark values every leg at what its bids would pay for it now, net of taker
+// fees. Legs without a snapshot keep their previous mark.
+func (p *Position) Mark(snaps map[string]*models.MarketSnapshot, fees arb.FeeSchedule, now time.Time) {
+	var total money.Micros
+	for i := range p.Legs {
+		leg := &p.Legs[i]
+		if snap := snaps[SnapshotKey(string(leg.Venue), leg.MarketID)]; snap != nil {
+			sell := arb.SimulateSell(matches.Order{Outcome: leg.Outcome, Quantity: leg.Quantity}, snap, fees)
+			leg.MarkUSD = sell.CostUSD - sell.FeeUSD
+		}
+		total += leg.MarkUSD
+	}
+	p.MarkValueUSD = total
+	p.MarkedAt = now
+}
+
+// Settle books the payout once the markets have closed. Complete sets pay
+// PayoutPerUnit whichever way the markets resolve; quantity one leg holds
+// beyond the hedge depends on the outcome, which the snapshots do not
+// carry, so it settles at its last mark.
+func (p *Position) Settle(now time.Time) {
+	hedged := p.HedgedQuantity()
+	value := money.FromFloat(p.PayoutPerUnit * hedged)
+	for _, leg := range p.Legs {
+		if excess := leg.Quantity - hedged; excess > epsilon && leg.Quantity > epsilon {
+			value += leg.MarkUSD.Mul(excess / leg.Quantity)
+		}
+	}
+	p.SettlementUSD = value
+	p.Status = StatusSettled
+	p.SettledAt = now
+}
+
+// Due reports whether the position's markets have closed.
+func (p *Position) Due(now time.Time) bool {
+	return p.Status == StatusOpen && !p.SettlesAt.IsZero() && !now.Before(p.SettlesAt)
+}
+
+// SnapshotKey identifies a market's snapshot across venues.
+func SnapshotKey(venue, marketID string) string {
+	return venue + ":" + marketID
+}
+
+// StrategyPnL aggregates paper positions of one strategy.
+type StrategyPnL struct {
+	Strategy      string       `json:"strategy"`
+	Open          int          `json:"open"`
+	Settled       int          `json:"settled"`
+	CostUSD       money.Micros `json:"cost_usd"`
+	PlannedUSD    money.Micros `json:"planned_usd"`
+	RealizedUSD   money.Micros `json:"realized_usd"`
+	UnrealizedUSD money.Micros `json:"unrealized_usd"`
+	FeesUSD       money.Micros `json:"fees_usd"`
+	SlippageUSD   money.Micros `json:"slippage_usd"`
+}
+
+// Summarize groups positions by strategy, sorted by strategy name.
+func Summarize(positions []Position) []StrategyPnL {
+	byStrategy := make(map[string]*StrategyPnL)
+	for i := range positions {
+		p := &positions[i]
+		s := byStrategy[p.Strategy]
+		if s == nil {
+			s = &StrategyPnL{Strategy: p.Strategy}
+			byStrategy[p.Strategy] = s
+		}
+		switch p.Status {
+		case StatusOpen:
+			s.Open++
+		case StatusSettled:
+			s.Settled++
+		}
+		s.CostUSD += p.EntryCostUSD
+		s.PlannedUSD += p.PlannedProfitUSD
+		s.RealizedUSD += p.RealizedUSD()
+		s.UnrealizedUSD += p.UnrealizedUSD()
+		s.FeesUSD += p.FeesUSD
+		s.SlippageUSD += p.SlippageUSD
+	}
+	out := make([]StrategyPnL, 0, len(byStrategy))
+	for _, s := range byStrategy {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Strategy < out[j].Strategy })
+	return out
+}
@@ -16,26 +16,30 @@ import (
 	"github.com/hetulpatel/Arbitrage/internal/collectors"
 	"github.com/hetulpatel/Arbitrage/internal/logging"
 	"github.com/hetulpatel/Arbitrage/internal/models"
+	"github.com/hetulpatel/Arbitrage/internal/money"
 )
 
 const (
-	defaultBaseURL = "https://gamma-api.polymarket.com/events"
-	defaultBookURL = "https://clob.polymarket.com/book"
+	defaultBaseURL   = "https://gamma-api.polymarket.com/events"
+	defaultBookURL   = "https://clob.polymarket.com/book"
+	defaultMarketURL = "https://gamma-api.polymarket.com/markets"
 )
 
 // Client fetches Polymarket events + CLOB data.
 type Client struct {
 	baseURL    string
 	bookURL    string
+	marketURL  string
 	httpClient *http.Client
 	nextOffset int
 }
 
 // Config controls optional overrides for the client.
 type Config struct {
-	BaseURL string
-	BookURL string
-	Timeout time.Duration
+	BaseURL   string
+	BookURL   string
+	MarketURL string
+	Timeout   time.Duration
 }
 
 // NewClient builds a Polymarket client with sane defaults.
@@ -48,13 +52,18 @@ func NewClient(cfg Config) *Client {
 	if book == "" {
 		book = defaultBookURL
 	}
+	marketURL := cfg.MarketURL
+	if marketURL == "" {
+		marketURL = defaultMarketURL
+	}
 	timeout := cfg.Timeout
 	if timeout == 0 {
 		timeout = 20 * time.Second
 	}
 	return &Client{
-		baseURL: base,
-		bookURL: book,
+		baseURL:   base,
+		bookURL:   book,
+		marketURL: marketURL,
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
@@ -150,6 +159,20 @@ func (c *Client) fetchEvent(ctx context.Context, id string) (*eventDetail, error
 	return &ev, nil
 }
 
+// Event fetches and normalizes a whole event with the orderbooks of every
+// open market, for evaluators that price the family as a basket.
+func (c *Client) Event(ctx context.Context, eventID string) (*collectors.Event, error) {
+	if eventID == "" {
+		return nil, fmt.Errorf("polymarket: eventID is required")
+	}
+	ev, err := c.fetchEvent(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("polymarket fetch event %s: %w", eventID, err)
+	}
+	norm := c.normalizeEvent(ctx, ev)
+	return &norm, nil
+}
+
 // MarketSnapshot fetches and normalizes a single market for fresh orderbook checks.
 func (c *Client) MarketSnapshot(ctx context.Context, eventID, marketID string) (*models.MarketSnapshot, error) {
 	if eventID == "" || marketID == "" {
@@ -169,6 +192,54 @@ func (c *Client) MarketSnapshot(ctx context.Context, eventID, marketID string) (
 	return nil, fmt.Errorf("polymarket: market %s not found in event %s", marketID, eventID)
 }
 
+// MarketResolution reports whether a market has resolved and how. Closed
+// markets drop out of MarketSnapshot, so this reads the market directly.
+func (c *Client) MarketResolution(ctx context.Context, marketID string) (collectors.Resolution, error) {
+	if marketID == "" {
+		return collectors.Resolution{}, fmt.Errorf("polymarket: marketID is required")
+	}
+	u := fmt.Sprintf("%s/%s", strings.TrimRight(c.marketURL, "/"), marketID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return collectors.Resolution{}, err
+	}
+	var m market
+	if err := c.do(req, &m); err != nil {
+		return collectors.Resolution{}, fmt.Errorf("polymarket fetch market %s: %w", marketID, err)
+	}
+	return marketResolution(&m), nil
+}
+
+// marketResolution reads the outcome prices of a closed market once the UMA
+// oracle has resolved it: ["1","0"] is YES, ["0","1"] NO and ["0.5","0.5"]
+// a 50-50 split. Older markets carry no UMA status; their prices are final
+// once the market is closed.
+func marketResolution(m *market) collectors.Resolution {
+	res := collectors.Resolution{Venue: collectors.VenuePolymarket, MarketID: m.ID, Status: "open"}
+	if !m.Closed {
+		return res
+	}
+	res.Status = "closed"
+	if m.UMAResolutionStatus != "" {
+		res.Status = m.UMAResolutionStatus
+	}
+	if m.UMAResolutionStatus != "" && m.UMAResolutionStatus != "resolved" {
+		return res
+	}
+	var prices []string
+	if err := json.Unmarshal([]byte(m.OutcomePrices), &prices); err != nil || len(prices) != 2 {
+		return res
+	}
+	yes, err := strconv.ParseFloat(prices[0], 64)
+	if err != nil || (yes != 0 && yes != 0.5 && yes != 1) {
+		return res
+	}
+	res.Resolved = true
+	res.YesPayout = yes
+	res.Result = collectors.ResultFor(yes)
+	return res
+}
+
 func (c *Client) fetchOrderbook(ctx context.Context, tokenID string) (collectors.Orderbook, error) {
 	u, _ := url.Parse(c.bookURL)
 	q := u.Query()
@@ -235,13 +306,24 @@ func (c *Client) normalizeEvent(ctx context.Context, ev *eventDetail) collectors
 		ResolutionDetails: ev.ResolutionDescription,
 		CloseTime:         closeTime,
 		Raw:               map[string]any{"raw_event": ev},
+		MutuallyExclusive: ev.NegRisk,
+	}
+	// Augmented neg-risk events can add outcomes after launch and keep an
+	// implicit "other" outcome.
+	if ev.NegRiskAugmented {
+		norm.Unlisted++
 	}
 
 	for _, m := range ev.Markets {
 		if isPlaceholderMarket(&m) {
+			norm.Unlisted++
 			continue
 		}
 		if m.Closed || !m.Active {
+			// A market already resolved NO cannot be the winning outcome.
+			if res := marketResolution(&m); !res.Resolved || res.YesPayout > 0 {
+				norm.Unlisted++
+			}
 			continue
 		}
 		norm.Markets = append(norm.Markets, c.normalizeMarket(ctx, ev, &m))
@@ -291,6 +373,7 @@ func (c *Client) normalizeMarket(ctx context.Context, ev *eventDetail, m *market
 		Price:        price,
 		Orderbooks:   orderbooks,
 		ClobTokenIDs: clobIDs,
+		NegRisk:      m.NegRisk,
 	}
 }
 
@@ -299,7 +382,7 @@ func bestBid(orderbooks map[string]collectors.Orderbook, clobIDs []string, idx i
 		return 0
 	}
 	if book, ok := orderbooks[clobIDs[idx]]; ok && len(book.Bids) > 0 {
-		return book.Bids[0].Price
+		return book.Bids[0].Price.Float()
 	}
 	return 0
 }
@@ -309,7 +392,7 @@ func bestAsk(orderbooks map[string]collectors.Orderbook, clobIDs []string, idx i
 		return 0
 	}
 	if book, ok := orderbooks[clobIDs[idx]]; ok && len(book.Asks) > 0 {
-		return book.Asks[0].Price
+		return book.Asks[0].Price.Float()
 	}
 	return 0
 }
@@ -328,22 +411,22 @@ func parseClobTokenIDs(raw string) []string {
 func convertClobBook(b clobBook) collectors.Orderbook {
 	out := collectors.Orderbook{}
 	for _, lvl := range b.Bids {
-		price := parseDecimal(lvl.Price)
+		price := parsePrice(lvl.Price)
 		size := parseDecimal(lvl.Size)
 		out.Bids = append(out.Bids, collectors.OrderbookLevel{
 			Price:     price,
 			Quantity:  size,
-			RawPrice:  price,
+			RawPrice:  price.Float(),
 			RawAmount: size,
 		})
 	}
 	for _, lvl := range b.Asks {
-		price := parseDecimal(lvl.Price)
+		price := parsePrice(lvl.Price)
 		size := parseDecimal(lvl.Size)
 		out.Asks = append(out.Asks, collectors.OrderbookLevel{
 			Price:     price,
 			Quantity:  size,
-			RawPrice:  price,
+			RawPrice:  price.Float(),
 			RawAmount: size,
 		})
 	}
@@ -361,6 +444,15 @@ func parseDecimal(val string) float64 {
 	return f
 }
 
+// parsePrice keeps CLOB decimal prices exact; anything ParseDecimal rejects
+// (exponent notation) falls back to the rounded float.
+func parsePrice(val string) money.Micros {
+	if m, err := money.ParseDecimal(val); err == nil {
+		return m
+	}
+	return money.FromFloat(parseDecimal(val))
+}
+
 var placeholderQuestionRe = regexp.MustCompile(`(?i)^will\s+\w+\s+[a-z]\b`)
 
 func isPlaceholderMarket(m *market) bool {
@@ -412,6 +504,8 @@ type eventDetail struct {
 	Closed                bool     `json:"closed"`
 	Category              string   `json:"category"`
 	EndDate               string   `json:"endDate"`
+	NegRisk               bool     `json:"negRisk"`
+	NegRiskAugmented      bool     `json:"negRiskAugmented"`
 	Markets               []market `json:"markets"`
 }
 
@@ -428,6 +522,12 @@ type market struct {
 	EndDate        string  `json:"endDate"`
 	Active         bool    `json:"active"`
 	Closed         bool    `json:"closed"`
+	NegRisk        bool    `json:"negRisk"`
+
+	// Resolution fields: outcomePrices is a JSON string array in outcome
+	// order (YES first).
+	OutcomePrices       string `json:"outcomePrices"`
+	UMAResolutionStatus string `json:"umaResolutionStatus"`
 }
 
 type clobBook struct {
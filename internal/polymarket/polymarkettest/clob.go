@@ -0,0 +1,631 @@
+// Package polymarkettest provides an in-process mock of the Polymarket CLOB
+// auth and order endpoints. It checks L1 wallet signatures, L2 HMAC
+// signatures and EIP-712 order signatures exactly as the exchange does and
+// fills orders against a canned book, so polymarket.TradingClient can be
+// exercised end to end offline.
+package polymarkettest
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/hetulpatel/Arbitrage/internal/money"
+	"github.com/hetulpatel/Arbitrage/internal/polymarket"
+)
+
+// unit is one token or one USDC in base units.
+const unit = int64(money.Dollar)
+
+// Level is one scripted resting order of the canned book.
+type Level struct {
+	Price money.Micros
+	Size  float64
+}
+
+// level is a resting price level in base units. orderID is set for orders
+// posted through the mock, which incoming orders never match (no
+// self-trades).
+type level struct {
+	price   int64
+	size    int64
+	orderID string
+}
+
+// book holds a token's bids (best first, descending) and asks (best first,
+// ascending).
+type book struct {
+	bids, asks []*level
+}
+
+// OrderState is an order as the mock tracks it.
+type OrderState struct {
+	ID           string
+	TokenID      string
+	Side         polymarket.Side
+	Price        money.Micros
+	OriginalSize float64
+	MatchedSize  float64
+	// Status is live, matched or canceled.
+	Status string
+}
+
+type order struct {
+	id       string
+	maker    string
+	tokenID  string
+	side     polymarket.Side
+	price    int64
+	original int64
+	matched  int64
+	status   string
+}
+
+type account struct {
+	address string
+	creds   polymarket.APICreds
+}
+
+// CLOB is a mock Polymarket CLOB backed by an httptest.Server. Every
+// address starts with StartingBalance USDC and no tokens; balances move
+// only when orders fill.
+type CLOB struct {
+	server  *httptest.Server
+	chainID int64
+
+	mu        sync.Mutex
+	books     map[string]*book
+	orders    map[string]*order
+	byKey     map[string]*account
+	byAddress map[string]*account
+	balances  map[string]int64
+	positions map[string]map[string]int64
+	seq       int
+
+	StartingBalance money.Micros
+}
+
+// NewCLOB starts a mock CLOB that verifies signatures for Polygon mainnet.
+func NewCLOB() *CLOB {
+	c := &CLOB{
+		chainID:         polymarket.PolygonChainID,
+		books:           make(map[string]*book),
+		orders:          make(map[string]*order),
+		byKey:           make(map[string]*account),
+		byAddress:       make(map[string]*account),
+		balances:        make(map[string]int64),
+		positions:       make(map[string]map[string]int64),
+		StartingBalance: 1_000 * money.Dollar,
+	}
+	mux := http.NewServeMux()
+	mux.Handle("POST /auth/api-key", c.l1(c.handleCreateKey))
+	mux.Handle("GET /auth/derive-api-key", c.l1(c.handleDeriveKey))
+	mux.Handle("POST /order", c.l2(c.handlePost))
+	mux.Handle("DELETE /order", c.l2(c.handleCancel))
+	mux.Handle("DELETE /orders", c.l2(c.handleCancelMany))
+	c.server = httptest.NewServer(mux)
+	return c
+}
+
+// URL is the CLOB base URL to give polymarket.TradingConfig.
+func (c *CLOB) URL() string {
+	return c.server.URL
+}
+
+// Close shuts the server down.
+func (c *CLOB) Close() {
+	c.server.Close()
+}
+
+// SetBook replaces a token's external liquidity. Orders resting from
+// earlier posts are kept.
+func (c *CLOB) SetBook(tokenID string, bids, asks []Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b := c.book(tokenID)
+	b.bids = scripted(b.bids, bids)
+	b.asks = scripted(b.asks, asks)
+	sortBook(b)
+}
+
+func scripted(current []*level, levels []Level) []*level {
+	var out []*level
+	for _, l := range current {
+		if l.orderID != "" {
+			out = append(out, l)
+		}
+	}
+	for _, l := range levels {
+		out = append(out, &level{price: int64(l.Price), size: baseUnits(l.Size)})
+	}
+	return out
+}
+
+// SetBalance sets an address's USDC balance.
+func (c *CLOB) SetBalance(address string, balance money.Micros) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.balance(address)
+	c.balances[normalize(address)] = int64(balance)
+}
+
+// Balance returns an address's USDC balance.
+func (c *CLOB) Balance(address string) money.Micros {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return money.Micros(c.balance(address))
+}
+
+// SetPosition sets the number of tokenID tokens an address holds.
+func (c *CLOB) SetPosition(address, tokenID string, size float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.position(address)[tokenID] = baseUnits(size)
+}
+
+// Position returns the number of tokenID tokens an address holds.
+func (c *CLOB) Position(address, tokenID string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return float64(c.position(address)[tokenID]) / float64(unit)
+}
+
+// Order reports a posted order's state.
+func (c *CLOB) Order(orderID string) (OrderState, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	o := c.orders[orderID]
+	if o == nil {
+		return OrderState{}, false
+	}
+	return OrderState{
+		ID:           o.id,
+		TokenID:      o.tokenID,
+		Side:         o.side,
+		Price:        money.Micros(o.price),
+		OriginalSize: float64(o.original) / float64(unit),
+		MatchedSize:  float64(o.matched) / float64(unit),
+		Status:       o.status,
+	}, true
+}
+
+// FillResting simulates another participant trading size tokens against
+// one of our resting orders at its limit price (a maker fill).
+func (c *CLOB) FillResting(orderID string, size float64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	o := c.orders[orderID]
+	if o == nil || o.status != "live" {
+		return fmt.Errorf("order %s is not live", orderID)
+	}
+	n := min(baseUnits(size), o.original-o.matched)
+	c.settle(o, n, o.price)
+	levels := c.restingSide(o)
+	for i, l := range *levels {
+		if l.orderID == orderID {
+			l.size -= n
+			if l.size <= 0 {
+				*levels = append((*levels)[:i], (*levels)[i+1:]...)
+			}
+			break
+		}
+	}
+	if o.matched == o.original {
+		o.status = "matched"
+	}
+	return nil
+}
+
+// l1 authenticates credential requests by recovering the wallet that
+// signed the ClobAuth message.
+func (c *CLOB) l1(next func(http.ResponseWriter, *http.Request, string)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		address, err := polymarket.ChecksumAddress(r.Header.Get(polymarket.HeaderAddress))
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, "Invalid L1 Request headers")
+			return
+		}
+		nonce, err := strconv.ParseInt(r.Header.Get(polymarket.HeaderNonce), 10, 64)
+		if err != nil {
+			nonce = 0
+		}
+		hash, err := polymarket.ClobAuthHash(address, r.Header.Get(polymarket.HeaderTimestamp), nonce, c.chainID)
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, "Invalid L1 Request headers")
+			return
+		}
+		sig, err := polymarket.DecodeSignature(r.Header.Get(polymarket.HeaderSignature))
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, "Invalid L1 Request headers")
+			return
+		}
+		if signer, err := polymarket.RecoverAddress(hash, sig); err != nil || signer != address {
+			writeError(w, http.StatusUnauthorized, "Invalid L1 Request headers")
+			return
+		}
+		next(w, r, address)
+	})
+}
+
+// l2 authenticates trading requests by their API key and HMAC signature.
+func (c *CLOB) l2(next func(http.ResponseWriter, *http.Request, *account, []byte)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "unreadable body")
+			return
+		}
+		c.mu.Lock()
+		acct := c.byKey[r.Header.Get(polymarket.HeaderAPIKey)]
+		c.mu.Unlock()
+		if acct == nil || r.Header.Get(polymarket.HeaderPassphrase) != acct.creds.Passphrase ||
+			normalize(r.Header.Get(polymarket.HeaderAddress)) != normalize(acct.address) {
+			writeError(w, http.StatusUnauthorized, "Unauthorized/Invalid api key")
+			return
+		}
+		want, err := polymarket.BuildHMAC(acct.creds.Secret, r.Header.Get(polymarket.HeaderTimestamp), r.Method, r.URL.Path, string(body))
+		if err != nil || !hmac.Equal([]byte(want), []byte(r.Header.Get(polymarket.HeaderSignature))) {
+			writeError(w, http.StatusUnauthorized, "Unauthorized/Invalid api key")
+			return
+		}
+		next(w, r, acct, body)
+	})
+}
+
+func (c *CLOB) handleCreateKey(w http.ResponseWriter, _ *http.Request, address string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.byAddress[address] != nil {
+		writeError(w, http.StatusBadRequest, "Could not create api key")
+		return
+	}
+	acct := &account{address: address, creds: polymarket.APICreds{
+		APIKey:     randomHex(16),
+		Secret:     base64.URLEncoding.EncodeToString(randomBytes(32)),
+		Passphrase: randomHex(32),
+	}}
+	c.byAddress[address] = acct
+	c.byKey[acct.creds.APIKey] = acct
+	writeJSON(w, acct.creds)
+}
+
+func (c *CLOB) handleDeriveKey(w http.ResponseWriter, _ *http.Request, address string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	acct := c.byAddress[address]
+	if acct == nil {
+		writeError(w, http.StatusBadRequest, "Could not derive api key!")
+		return
+	}
+	writeJSON(w, acct.creds)
+}
+
+func (c *CLOB) handlePost(w http.ResponseWriter, _ *http.Request, acct *account, body []byte) {
+	var req struct {
+		Order     polymarket.WireOrder `json:"order"`
+		Owner     string               `json:"owner"`
+		OrderType polymarket.OrderType `json:"orderType"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid order payload")
+		return
+	}
+	if req.Owner != acct.creds.APIKey {
+		writeError(w, http.StatusBadRequest, "the order owner has to be the owner of the API KEY")
+		return
+	}
+	o, err := req.Order.Order()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid order payload: "+err.Error())
+		return
+	}
+	id, err := c.verify(o, req.Order.Signature)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid signature")
+		return
+	}
+	if normalize(o.Signer) != normalize(acct.address) {
+		writeError(w, http.StatusBadRequest, "the order signer address has to be the address of the API KEY")
+		return
+	}
+	if o.SignatureType == polymarket.SignatureEOA && normalize(o.Maker) != normalize(o.Signer) {
+		writeError(w, http.StatusBadRequest, "invalid signature type for maker")
+		return
+	}
+	switch req.OrderType {
+	case polymarket.GoodTillCanceled, polymarket.FillOrKill, polymarket.FillAndKill:
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported order type "+string(req.OrderType))
+		return
+	}
+	if o.Expiration != 0 {
+		writeError(w, http.StatusBadRequest, "expiration is only allowed on GTD orders")
+		return
+	}
+	price, size := int64(o.Price()), o.Size()
+	if price <= 0 || price >= unit || size <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid order price or size")
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.orders[id] != nil {
+		writeError(w, http.StatusBadRequest, "order "+id+" is invalid. Duplicated.")
+		return
+	}
+	if (o.Side == polymarket.Buy && c.balance(o.Maker) < o.MakerAmount) ||
+		(o.Side == polymarket.Sell && c.position(o.Maker)[o.TokenID] < o.MakerAmount) {
+		writeError(w, http.StatusBadRequest, "not enough balance / allowance")
+		return
+	}
+	if req.OrderType == polymarket.FillOrKill && c.crossable(o.TokenID, o.Side, price) < size {
+		writeError(w, http.StatusBadRequest, "order couldn't be fully filled. FOK orders are fully filled or killed.")
+		return
+	}
+
+	c.seq++
+	ord := &order{id: id, maker: o.Maker, tokenID: o.TokenID, side: o.Side, price: price, original: size, status: "live"}
+	c.orders[id] = ord
+	making, taking := c.match(ord)
+
+	status := polymarket.OrderMatched
+	switch {
+	case ord.matched == ord.original:
+		ord.status = "matched"
+	case req.OrderType == polymarket.GoodTillCanceled:
+		status = polymarket.OrderLive
+		side := c.restingSide(ord)
+		*side = append(*side, &level{price: price, size: ord.original - ord.matched, orderID: id})
+		sortBook(c.book(ord.tokenID))
+	default:
+		ord.status = "canceled"
+		if ord.matched == 0 {
+			status = polymarket.OrderUnmatched
+		}
+	}
+	var hashes []string
+	if ord.matched > 0 {
+		hashes = []string{fmt.Sprintf("0x%064x", c.seq)}
+	}
+	writeJSON(w, map[string]any{
+		"success":            true,
+		"errorMsg":           "",
+		"orderID":            id,
+		"status":             status,
+		"makingAmount":       money.Micros(making).String(),
+		"takingAmount":       money.Micros(taking).String(),
+		"transactionsHashes": hashes,
+	})
+}
+
+func (c *CLOB) handleCancel(w http.ResponseWriter, _ *http.Request, acct *account, body []byte) {
+	var req struct {
+		OrderID string `json:"orderID"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil || req.OrderID == "" {
+		writeError(w, http.StatusBadRequest, "Invalid order payload")
+		return
+	}
+	writeJSON(w, c.cancel(acct, []string{req.OrderID}))
+}
+
+func (c *CLOB) handleCancelMany(w http.ResponseWriter, _ *http.Request, acct *account, body []byte) {
+	var ids []string
+	if err := json.Unmarshal(body, &ids); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid order payload")
+		return
+	}
+	writeJSON(w, c.cancel(acct, ids))
+}
+
+func (c *CLOB) cancel(acct *account, ids []string) polymarket.CancelResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := polymarket.CancelResult{Canceled: []string{}, NotCanceled: map[string]string{}}
+	for _, id := range ids {
+		o := c.orders[id]
+		switch {
+		case o == nil:
+			out.NotCanceled[id] = "order not found"
+		case normalize(o.maker) != normalize(acct.address):
+			out.NotCanceled[id] = "order not found"
+		case o.status == "matched":
+			out.NotCanceled[id] = "order already matched"
+		case o.status == "canceled":
+			out.NotCanceled[id] = "order already canceled"
+		default:
+			c.unrest(o)
+			o.status = "canceled"
+			out.Canceled = append(out.Canceled, id)
+		}
+	}
+	return out
+}
+
+// verify recovers the order signer under either exchange contract and
+// returns the order hash as its id.
+func (c *CLOB) verify(o polymarket.Order, signature string) (string, error) {
+	sig, err := polymarket.DecodeSignature(signature)
+	if err != nil {
+		return "", err
+	}
+	signer, err := polymarket.ChecksumAddress(o.Signer)
+	if err != nil {
+		return "", err
+	}
+	for _, exchange := range []string{polymarket.ExchangeAddress, polymarket.NegRiskExchangeAddress} {
+		hash, err := polymarket.OrderHash(o, c.chainID, exchange)
+		if err != nil {
+			return "", err
+		}
+		if got, err := polymarket.RecoverAddress(hash, sig); err == nil && got == signer {
+			return polymarket.EncodeSignature(hash), nil
+		}
+	}
+	return "", fmt.Errorf("signature does not match signer")
+}
+
+// crossable is the external size resting at or better than limit on the
+// side an order would take from.
+func (c *CLOB) crossable(tokenID string, side polymarket.Side, limit int64) int64 {
+	var n int64
+	for _, l := range *c.takingSide(tokenID, side) {
+		if l.orderID == "" && crosses(side, l.price, limit) {
+			n += l.size
+		}
+	}
+	return n
+}
+
+// match takes external liquidity at or better than the order's limit, best
+// price first, and returns the amounts the order gave and received.
+func (c *CLOB) match(o *order) (making, taking int64) {
+	levels := c.takingSide(o.tokenID, o.side)
+	kept := (*levels)[:0]
+	for _, l := range *levels {
+		remaining := o.original - o.matched
+		if remaining == 0 || l.orderID != "" || !crosses(o.side, l.price, o.price) {
+			kept = append(kept, l)
+			continue
+		}
+		n := min(remaining, l.size)
+		usdc := c.settle(o, n, l.price)
+		if o.side == polymarket.Buy {
+			making, taking = making+usdc, taking+n
+		} else {
+			making, taking = making+n, taking+usdc
+		}
+		if l.size -= n; l.size > 0 {
+			kept = append(kept, l)
+		}
+	}
+	*levels = kept
+	return making, taking
+}
+
+// settle moves cash and tokens for n tokens of o trading at price.
+func (c *CLOB) settle(o *order, n, price int64) int64 {
+	usdc := n * price / unit
+	c.balance(o.maker)
+	pos := c.position(o.maker)
+	if o.side == polymarket.Buy {
+		c.balances[normalize(o.maker)] -= usdc
+		pos[o.tokenID] += n
+	} else {
+		c.balances[normalize(o.maker)] += usdc
+		pos[o.tokenID] -= n
+	}
+	o.matched += n
+	return usdc
+}
+
+func (c *CLOB) unrest(o *order) {
+	levels := c.restingSide(o)
+	for i, l := range *levels {
+		if l.orderID == o.id {
+			*levels = append((*levels)[:i], (*levels)[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *CLOB) takingSide(tokenID string, side polymarket.Side) *[]*level {
+	b := c.book(tokenID)
+	if side == polymarket.Buy {
+		return &b.asks
+	}
+	return &b.bids
+}
+
+func (c *CLOB) restingSide(o *order) *[]*level {
+	b := c.book(o.tokenID)
+	if o.side == polymarket.Buy {
+		return &b.bids
+	}
+	return &b.asks
+}
+
+func (c *CLOB) book(tokenID string) *book {
+	b := c.books[tokenID]
+	if b == nil {
+		b = &book{}
+		c.books[tokenID] = b
+	}
+	return b
+}
+
+func (c *CLOB) balance(address string) int64 {
+	key := normalize(address)
+	bal, ok := c.balances[key]
+	if !ok {
+		bal = int64(c.StartingBalance)
+		c.balances[key] = bal
+	}
+	return bal
+}
+
+func (c *CLOB) position(address string) map[string]int64 {
+	key := normalize(address)
+	pos := c.positions[key]
+	if pos == nil {
+		pos = make(map[string]int64)
+		c.positions[key] = pos
+	}
+	return pos
+}
+
+func crosses(side polymarket.Side, levelPrice, limit int64) bool {
+	if side == polymarket.Buy {
+		return levelPrice <= limit
+	}
+	return levelPrice >= limit
+}
+
+func sortBook(b *book) {
+	sort.SliceStable(b.bids, func(i, j int) bool { return b.bids[i].price > b.bids[j].price })
+	sort.SliceStable(b.asks, func(i, j int) bool { return b.asks[i].price < b.asks[j].price })
+}
+
+// normalize makes addresses comparable regardless of checksum casing.
+func normalize(address string) string {
+	if sum, err := polymarket.ChecksumAddress(address); err == nil {
+		return sum
+	}
+	return address
+}
+
+func baseUnits(size float64) int64 {
+	return int64(math.Round(size * float64(unit)))
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	rand.Read(b)
+	return b
+}
+
+func randomHex(n int) string {
+	return hex.EncodeToString(randomBytes(n))
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,201 @@
+package polymarkettest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/hetulpatel/Arbitrage/internal/money"
+	"github.com/hetulpatel/Arbitrage/internal/polymarket"
+	"github.com/hetulpatel/Arbitrage/internal/polymarket/polymarkettest"
+)
+
+const (
+	walletKey = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	token     = "71321045679252212594626385532706912750332728571942532289631379312455583992563"
+)
+
+// setup starts a CLOB with asks of 10 at 0.40 and 5 at 0.42 and bids of 20
+// at 0.35, and a client holding API credentials for it.
+func setup(t *testing.T) (*polymarkettest.CLOB, *polymarket.TradingClient) {
+	t.Helper()
+	clob := polymarkettest.NewCLOB()
+	t.Cleanup(clob.Close)
+	clob.SetBook(token,
+		[]polymarkettest.Level{{Price: 35 * money.Cent, Size: 20}},
+		[]polymarkettest.Level{{Price: 42 * money.Cent, Size: 5}, {Price: 40 * money.Cent, Size: 10}},
+	)
+	client, err := polymarket.NewTradingClient(polymarket.TradingConfig{BaseURL: clob.URL(), PrivateKey: walletKey})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if _, err := client.CreateOrDeriveAPIKey(context.Background()); err != nil {
+		t.Fatalf("api key: %v", err)
+	}
+	return clob, client
+}
+
+func buy(price money.Micros, size float64) polymarket.OrderArgs {
+	return polymarket.OrderArgs{TokenID: token, Side: polymarket.Buy, Price: price, Size: size}
+}
+
+func TestCredentials(t *testing.T) {
+	clob, client := setup(t)
+	ctx := context.Background()
+	creds := client.Creds()
+	derived, err := client.DeriveAPIKey(ctx)
+	if err != nil {
+		t.Fatalf("derive: %v", err)
+	}
+	if *derived != *creds {
+		t.Errorf("derived %+v, want %+v", derived, creds)
+	}
+	if _, err := client.CreateAPIKey(ctx); err == nil {
+		t.Error("second key created for the same wallet")
+	}
+
+	// A wrong secret fails the HMAC check.
+	forged := *creds
+	forged.Secret = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+	impostor, err := polymarket.NewTradingClient(polymarket.TradingConfig{BaseURL: clob.URL(), PrivateKey: walletKey, Creds: &forged})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	_, err = impostor.PlaceOrder(ctx, buy(40*money.Cent, 1), polymarket.FillOrKill)
+	var apiErr *polymarket.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("forged HMAC: %v, want 401", err)
+	}
+	if pos := clob.Position(client.Address(), token); pos != 0 {
+		t.Errorf("position = %g after a rejected order, want 0", pos)
+	}
+}
+
+func TestFillOrKill(t *testing.T) {
+	clob, client := setup(t)
+	ctx := context.Background()
+	addr := client.Address()
+	before := clob.Balance(addr)
+
+	// 16 tokens at up to 0.42 is more than the 15 offered.
+	if _, err := client.PlaceOrder(ctx, buy(42*money.Cent, 16), polymarket.FillOrKill); err == nil {
+		t.Fatal("oversized FOK order filled")
+	}
+	if pos := clob.Position(addr, token); pos != 0 || clob.Balance(addr) != before {
+		t.Fatalf("killed order moved funds: position=%g balance=%s", pos, clob.Balance(addr))
+	}
+
+	res, err := client.PlaceOrder(ctx, buy(42*money.Cent, 12), polymarket.FillOrKill)
+	if err != nil {
+		t.Fatalf("place: %v", err)
+	}
+	// 10 at 0.40 then 2 at 0.42 = 4.84 USDC.
+	if res.Status != polymarket.OrderMatched || res.FilledSize() != 12 || res.FilledUSD() != money.FromFloat(4.84) {
+		t.Fatalf("result = %s size=%g usd=%s, want matched 12 for 4.84", res.Status, res.FilledSize(), res.FilledUSD())
+	}
+	if len(res.TransactionHashes) != 1 {
+		t.Errorf("transaction hashes = %v, want one", res.TransactionHashes)
+	}
+	if pos := clob.Position(addr, token); pos != 12 {
+		t.Errorf("position = %g, want 12", pos)
+	}
+	if got, want := clob.Balance(addr), before-money.FromFloat(4.84); got != want {
+		t.Errorf("balance = %s, want %s", got, want)
+	}
+	state, ok := clob.Order(res.ID)
+	if !ok || state.Status != "matched" || state.MatchedSize != 12 {
+		t.Errorf("order state = %+v, want matched 12", state)
+	}
+
+	// Selling hits the 0.35 bid.
+	sell, err := client.PlaceOrder(ctx, polymarket.OrderArgs{TokenID: token, Side: polymarket.Sell, Price: 35 * money.Cent, Size: 12}, polymarket.FillOrKill)
+	if err != nil {
+		t.Fatalf("sell: %v", err)
+	}
+	if sell.FilledSize() != 12 || sell.FilledUSD() != money.FromFloat(4.2) {
+		t.Errorf("sell size=%g usd=%s, want 12 for 4.20", sell.FilledSize(), sell.FilledUSD())
+	}
+	if pos := clob.Position(addr, token); pos != 0 {
+		t.Errorf("position = %g after selling, want 0", pos)
+	}
+}
+
+func TestFillAndKill(t *testing.T) {
+	clob, client := setup(t)
+	ctx := context.Background()
+	res, err := client.PlaceOrder(ctx, buy(41*money.Cent, 25), polymarket.FillAndKill)
+	if err != nil {
+		t.Fatalf("place: %v", err)
+	}
+	// Only the 0.40 level crosses a 0.41 limit; the rest is cancelled.
+	if res.Status != polymarket.OrderMatched || res.FilledSize() != 10 || res.FilledUSD() != 4*money.Dollar {
+		t.Fatalf("result = %s size=%g usd=%s, want matched 10 for 4.00", res.Status, res.FilledSize(), res.FilledUSD())
+	}
+	state, _ := clob.Order(res.ID)
+	if state.Status != "canceled" || state.MatchedSize != 10 || state.OriginalSize != 25 {
+		t.Errorf("order state = %+v, want canceled 10 of 25", state)
+	}
+	if err := clob.FillResting(res.ID, 1); err == nil {
+		t.Error("FAK remainder rested")
+	}
+
+	miss, err := client.PlaceOrder(ctx, buy(39*money.Cent, 5), polymarket.FillAndKill)
+	if err != nil {
+		t.Fatalf("place: %v", err)
+	}
+	if miss.Status != polymarket.OrderUnmatched || miss.FilledSize() != 0 {
+		t.Errorf("non-crossing FAK = %s size=%g, want unmatched 0", miss.Status, miss.FilledSize())
+	}
+	if pos := clob.Position(client.Address(), token); pos != 10 {
+		t.Errorf("position = %g, want 10", pos)
+	}
+}
+
+func TestRestingOrderAndCancel(t *testing.T) {
+	clob, client := setup(t)
+	ctx := context.Background()
+	order, err := client.BuildOrder(buy(38*money.Cent, 10))
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	res, err := client.PostOrder(ctx, order, polymarket.GoodTillCanceled)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	if res.Status != polymarket.OrderLive || res.FilledSize() != 0 {
+		t.Fatalf("result = %s size=%g, want live 0", res.Status, res.FilledSize())
+	}
+	id, err := order.ID(polymarket.PolygonChainID)
+	if err != nil || id != res.ID {
+		t.Fatalf("order id = %s, %v; exchange reported %s", id, err, res.ID)
+	}
+	if _, err := client.PostOrder(ctx, order, polymarket.GoodTillCanceled); err == nil {
+		t.Error("re-posted order accepted")
+	}
+
+	if err := clob.FillResting(res.ID, 4); err != nil {
+		t.Fatalf("fill resting: %v", err)
+	}
+	if pos := clob.Position(client.Address(), token); pos != 4 {
+		t.Errorf("position = %g, want 4", pos)
+	}
+	canceled, err := client.CancelOrder(ctx, res.ID)
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if len(canceled.Canceled) != 1 || canceled.Canceled[0] != res.ID {
+		t.Fatalf("cancel = %+v, want %s canceled", canceled, res.ID)
+	}
+	again, err := client.CancelOrders(ctx, []string{res.ID, "0xmissing"})
+	if err != nil {
+		t.Fatalf("cancel orders: %v", err)
+	}
+	if len(again.Canceled) != 0 || again.NotCanceled[res.ID] == "" || again.NotCanceled["0xmissing"] == "" {
+		t.Errorf("second cancel = %+v, want nothing canceled", again)
+	}
+	state, _ := clob.Order(res.ID)
+	if state.Status != "canceled" || state.MatchedSize != 4 {
+		t.Errorf("order state = %+v, want canceled with 4 matched", state)
+	}
+}
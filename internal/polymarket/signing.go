@@ -0,0 +1,239 @@
+package polymarket
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"golang.org/x/crypto/sha3"
+)
+
+// Polygon mainnet and the CTF exchange contracts orders are signed for.
+const (
+	PolygonChainID         int64 = 137
+	ExchangeAddress              = "0x4bFb41d5B3570DeFd03C39a9A4D8dE6Bd8B8982E"
+	NegRiskExchangeAddress       = "0xC5d563A36AE78145C45a50134d48A1215220f80a"
+	ZeroAddress                  = "0x0000000000000000000000000000000000000000"
+)
+
+// clobAuthMessage is the fixed statement signed to prove wallet ownership
+// when creating or deriving API credentials.
+const clobAuthMessage = "This message attests that I control the given wallet"
+
+var (
+	domainTypeHash        = keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	authDomainTypeHash    = keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId)"))
+	orderTypeHash         = keccak256([]byte("Order(uint256 salt,address maker,address signer,address taker,uint256 tokenId,uint256 makerAmount,uint256 takerAmount,uint256 expiration,uint256 nonce,uint256 feeRateBps,uint8 side,uint8 signatureType)"))
+	clobAuthTypeHash      = keccak256([]byte("ClobAuth(address address,string timestamp,uint256 nonce,string message)"))
+	exchangeDomainName    = keccak256([]byte("Polymarket CTF Exchange"))
+	authDomainName        = keccak256([]byte("ClobAuthDomain"))
+	domainVersion         = keccak256([]byte("1"))
+	clobAuthMessageHash   = keccak256([]byte(clobAuthMessage))
+	secp256k1HalfOrder, _ = new(big.Int).SetString("7fffffffffffffffffffffffffffffff5d576e7357a4501ddfe92f46681b20a0", 16)
+)
+
+// Signer holds the wallet key orders and L1 auth messages are signed with.
+type Signer struct {
+	key     *secp256k1.PrivateKey
+	address string
+}
+
+// NewSigner parses a hex-encoded secp256k1 private key, with or without the
+// 0x prefix.
+func NewSigner(hexKey string) (*Signer, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"))
+	if err != nil || len(raw) != 32 {
+		return nil, fmt.Errorf("polymarket key: want 32 hex-encoded bytes")
+	}
+	var scalar secp256k1.ModNScalar
+	if overflow := scalar.SetByteSlice(raw); overflow || scalar.IsZero() {
+		return nil, fmt.Errorf("polymarket key: out of range")
+	}
+	key := secp256k1.NewPrivateKey(&scalar)
+	return &Signer{key: key, address: pubKeyAddress(key.PubKey())}, nil
+}
+
+// Address is the checksummed wallet address of the key.
+func (s *Signer) Address() string {
+	return s.address
+}
+
+// SignHash returns the 65-byte r || s || v signature of a 32-byte digest,
+// with v in {27, 28} as Ethereum wallets produce it.
+func (s *Signer) SignHash(hash []byte) []byte {
+	compact := ecdsa.SignCompact(s.key, hash, false)
+	return append(compact[1:], compact[0])
+}
+
+// RecoverAddress returns the checksummed address that produced sig over
+// hash.
+func RecoverAddress(hash, sig []byte) (string, error) {
+	if len(sig) != 65 {
+		return "", fmt.Errorf("signature is %d bytes, want 65", len(sig))
+	}
+	v := sig[64]
+	if v < 27 {
+		v += 27
+	}
+	if v != 27 && v != 28 {
+		return "", fmt.Errorf("invalid recovery id %d", sig[64])
+	}
+	// Reject malleable high-s signatures, as the exchange contract does.
+	if new(big.Int).SetBytes(sig[32:64]).Cmp(secp256k1HalfOrder) > 0 {
+		return "", fmt.Errorf("signature s value is not canonical")
+	}
+	compact := append([]byte{v}, sig[:64]...)
+	pub, _, err := ecdsa.RecoverCompact(compact, hash)
+	if err != nil {
+		return "", err
+	}
+	return pubKeyAddress(pub), nil
+}
+
+// OrderHash is the EIP-712 digest of an order for the given chain and
+// exchange contract; it doubles as the order id.
+func OrderHash(o Order, chainID int64, exchange string) ([]byte, error) {
+	tokenID, ok := new(big.Int).SetString(o.TokenID, 10)
+	if !ok || tokenID.Sign() < 0 {
+		return nil, fmt.Errorf("invalid token id %q", o.TokenID)
+	}
+	side, err := o.Side.code()
+	if err != nil {
+		return nil, err
+	}
+	maker, err := addressWord(o.Maker)
+	if err != nil {
+		return nil, fmt.Errorf("maker: %w", err)
+	}
+	signer, err := addressWord(o.Signer)
+	if err != nil {
+		return nil, fmt.Errorf("signer: %w", err)
+	}
+	taker, err := addressWord(o.Taker)
+	if err != nil {
+		return nil, fmt.Errorf("taker: %w", err)
+	}
+	contract, err := addressWord(exchange)
+	if err != nil {
+		return nil, fmt.Errorf("exchange: %w", err)
+	}
+	domain := keccak256(domainTypeHash, exchangeDomainName, domainVersion, uintWord(chainID), contract)
+	structHash := keccak256(
+		orderTypeHash,
+		uintWord(o.Salt),
+		maker,
+		signer,
+		taker,
+		bigWord(tokenID),
+		uintWord(o.MakerAmount),
+		uintWord(o.TakerAmount),
+		uintWord(o.Expiration),
+		uintWord(o.Nonce),
+		uintWord(o.FeeRateBps),
+		uintWord(side),
+		uintWord(int64(o.SignatureType)),
+	)
+	return typedDataHash(domain, structHash), nil
+}
+
+// ClobAuthHash is the EIP-712 digest a wallet signs to create or derive
+// API credentials (L1 authentication).
+func ClobAuthHash(address, timestamp string, nonce int64, chainID int64) ([]byte, error) {
+	addr, err := addressWord(address)
+	if err != nil {
+		return nil, err
+	}
+	domain := keccak256(authDomainTypeHash, authDomainName, domainVersion, uintWord(chainID))
+	structHash := keccak256(clobAuthTypeHash, addr, keccak256([]byte(timestamp)), uintWord(nonce), clobAuthMessageHash)
+	return typedDataHash(domain, structHash), nil
+}
+
+// BuildHMAC returns the L2 signature of a request: the URL-safe base64
+// HMAC-SHA256, keyed with the decoded API secret, of timestamp + method +
+// path + body.
+func BuildHMAC(secret, timestamp, method, path, body string) (string, error) {
+	key, err := base64.URLEncoding.DecodeString(secret)
+	if err != nil {
+		return "", fmt.Errorf("polymarket api secret: %w", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(timestamp + method + path + body))
+	return base64.URLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// EncodeSignature and DecodeSignature convert signatures to and from the
+// 0x-prefixed hex the API carries.
+func EncodeSignature(sig []byte) string {
+	return "0x" + hex.EncodeToString(sig)
+}
+
+func DecodeSignature(raw string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(raw, "0x"))
+}
+
+// ChecksumAddress normalizes a hex address to its EIP-55 mixed-case form.
+func ChecksumAddress(address string) (string, error) {
+	raw, err := parseAddress(address)
+	if err != nil {
+		return "", err
+	}
+	return checksum(raw), nil
+}
+
+func typedDataHash(domain, structHash []byte) []byte {
+	return keccak256([]byte{0x19, 0x01}, domain, structHash)
+}
+
+func keccak256(parts ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
+
+func uintWord(v int64) []byte {
+	return bigWord(big.NewInt(v))
+}
+
+func bigWord(v *big.Int) []byte {
+	return v.FillBytes(make([]byte, 32))
+}
+
+func addressWord(address string) ([]byte, error) {
+	raw, err := parseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return append(make([]byte, 12), raw...), nil
+}
+
+func parseAddress(address string) ([]byte, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(address, "0x"))
+	if err != nil || len(raw) != 20 {
+		return nil, fmt.Errorf("invalid address %q", address)
+	}
+	return raw, nil
+}
+
+func pubKeyAddress(pub *secp256k1.PublicKey) string {
+	return checksum(keccak256(pub.SerializeUncompressed()[1:])[12:])
+}
+
+func checksum(raw []byte) string {
+	lower := hex.EncodeToString(raw)
+	hash := hex.EncodeToString(keccak256([]byte(lower)))
+	out := []byte(lower)
+	for i, c := range out {
+		if c >= 'a' && hash[i] >= '8' {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
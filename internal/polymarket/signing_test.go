@@ -0,0 +1,245 @@
+package polymarket
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		t.Fatalf("decode %q: %v", s, err)
+	}
+	return b
+}
+
+func mustWord(t *testing.T, address string) []byte {
+	t.Helper()
+	w, err := addressWord(address)
+	if err != nil {
+		t.Fatalf("address %q: %v", address, err)
+	}
+	return w
+}
+
+// TestEIP712KnownAnswer reproduces the Mail example of the EIP-712
+// specification: the domain separator, struct hash, digest and the
+// signature by keccak256("cow").
+func TestEIP712KnownAnswer(t *testing.T) {
+	domainType := keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	if !bytes.Equal(domainType, domainTypeHash) {
+		t.Fatalf("domain type hash differs from the package's")
+	}
+	domain := keccak256(domainType, keccak256([]byte("Ether Mail")), keccak256([]byte("1")), uintWord(1),
+		mustWord(t, "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"))
+	if want := mustHex(t, "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"); !bytes.Equal(domain, want) {
+		t.Fatalf("domain separator = %x, want %x", domain, want)
+	}
+
+	personType := keccak256([]byte("Person(string name,address wallet)"))
+	mailType := keccak256([]byte("Mail(Person from,Person to,string contents)Person(string name,address wallet)"))
+	from := keccak256(personType, keccak256([]byte("Cow")), mustWord(t, "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"))
+	to := keccak256(personType, keccak256([]byte("Bob")), mustWord(t, "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"))
+	mail := keccak256(mailType, from, to, keccak256([]byte("Hello, Bob!")))
+	if want := mustHex(t, "0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"); !bytes.Equal(mail, want) {
+		t.Fatalf("struct hash = %x, want %x", mail, want)
+	}
+	digest := typedDataHash(domain, mail)
+	if want := mustHex(t, "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"); !bytes.Equal(digest, want) {
+		t.Fatalf("digest = %x, want %x", digest, want)
+	}
+
+	signer, err := NewSigner(hex.EncodeToString(keccak256([]byte("cow"))))
+	if err != nil {
+		t.Fatalf("new signer: %v", err)
+	}
+	if got := signer.Address(); got != "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826" {
+		t.Fatalf("address = %s, want 0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826", got)
+	}
+	sig := signer.SignHash(digest)
+	want := "0x" +
+		"4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" +
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562" +
+		"1c"
+	if got := EncodeSignature(sig); got != want {
+		t.Fatalf("signature = %s, want %s", got, want)
+	}
+	if got, err := RecoverAddress(digest, sig); err != nil || got != signer.Address() {
+		t.Fatalf("recover = %s, %v; want %s", got, err, signer.Address())
+	}
+}
+
+func TestSignerAddress(t *testing.T) {
+	signer, err := NewSigner("0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatalf("new signer: %v", err)
+	}
+	if got := signer.Address(); got != "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23" {
+		t.Errorf("address = %s, want 0x2c7536E3605D9C16a7a3D7b1898e529396a65c23", got)
+	}
+	for _, bad := range []string{"", "0x1234", strings.Repeat("00", 32), strings.Repeat("ff", 32)} {
+		if _, err := NewSigner(bad); err == nil {
+			t.Errorf("NewSigner(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestChecksumAddress(t *testing.T) {
+	// EIP-55 test vectors.
+	for _, want := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		got, err := ChecksumAddress(strings.ToLower(want))
+		if err != nil || got != want {
+			t.Errorf("ChecksumAddress(%s) = %s, %v; want %s", strings.ToLower(want), got, err, want)
+		}
+	}
+}
+
+func testOrder(t *testing.T, signer *Signer) Order {
+	t.Helper()
+	return Order{
+		Salt:        479249096354,
+		Maker:       signer.Address(),
+		Signer:      signer.Address(),
+		Taker:       ZeroAddress,
+		TokenID:     "71321045679252212594626385532706912750332728571942532289631379312455583992563",
+		MakerAmount: 5_700_000,
+		TakerAmount: 10_000_000,
+		Side:        Buy,
+	}
+}
+
+// TestOrderSignature signs a CTF exchange order and checks the digest binds
+// every field, the chain and the exchange contract.
+func TestOrderSignature(t *testing.T) {
+	signer, err := NewSigner("0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatalf("new signer: %v", err)
+	}
+	order := testOrder(t, signer)
+	hash, err := OrderHash(order, PolygonChainID, ExchangeAddress)
+	if err != nil {
+		t.Fatalf("order hash: %v", err)
+	}
+	sig := signer.SignHash(hash)
+	if !bytes.Equal(sig, signer.SignHash(hash)) {
+		t.Fatal("signatures are not deterministic")
+	}
+	if got, err := RecoverAddress(hash, sig); err != nil || got != signer.Address() {
+		t.Fatalf("recover = %s, %v; want %s", got, err, signer.Address())
+	}
+
+	variants := map[string]func(o *Order){
+		"salt":         func(o *Order) { o.Salt++ },
+		"token":        func(o *Order) { o.TokenID = "1" },
+		"maker amount": func(o *Order) { o.MakerAmount++ },
+		"taker amount": func(o *Order) { o.TakerAmount++ },
+		"side":         func(o *Order) { o.Side = Sell },
+		"fee":          func(o *Order) { o.FeeRateBps = 1 },
+		"type":         func(o *Order) { o.SignatureType = SignaturePolyProxy },
+		"taker":        func(o *Order) { o.Taker = signer.Address() },
+	}
+	for name, mutate := range variants {
+		o := testOrder(t, signer)
+		mutate(&o)
+		other, err := OrderHash(o, PolygonChainID, ExchangeAddress)
+		if err != nil {
+			t.Fatalf("%s: order hash: %v", name, err)
+		}
+		if bytes.Equal(other, hash) {
+			t.Errorf("changing %s kept the order hash", name)
+		}
+	}
+	if other, _ := OrderHash(order, PolygonChainID, NegRiskExchangeAddress); bytes.Equal(other, hash) {
+		t.Error("the neg-risk exchange signs the same digest")
+	}
+	if other, _ := OrderHash(order, 80002, ExchangeAddress); bytes.Equal(other, hash) {
+		t.Error("another chain signs the same digest")
+	}
+
+	// The malleated (n − s, flipped v) twin of a valid signature is refused.
+	n := new(big.Int).Add(new(big.Int).Lsh(secp256k1HalfOrder, 1), big.NewInt(1))
+	twin := append([]byte(nil), sig...)
+	new(big.Int).Sub(n, new(big.Int).SetBytes(sig[32:64])).FillBytes(twin[32:64])
+	twin[64] ^= 1
+	if _, err := RecoverAddress(hash, twin); err == nil {
+		t.Error("high-s signature recovered")
+	}
+}
+
+func TestBuildHMAC(t *testing.T) {
+	got, err := BuildHMAC("AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=", "1700000000", "POST", "/order", `{"orderID":"0xabc"}`)
+	if err != nil {
+		t.Fatalf("hmac: %v", err)
+	}
+	if want := "fp6DKw54ZwOnBVNV2UiaBx-95Tl0cFULyGHx55WjcQ8="; got != want {
+		t.Errorf("hmac = %s, want %s", got, want)
+	}
+	if _, err := BuildHMAC("not base64!", "1700000000", "POST", "/order", ""); err == nil {
+		t.Error("bad secret accepted")
+	}
+}
+
+func TestBuildOrderAmounts(t *testing.T) {
+	client, err := NewTradingClient(TradingConfig{PrivateKey: "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	cases := []struct {
+		name         string
+		args         OrderArgs
+		maker, taker int64
+	}{
+		{"buy rounds size down", OrderArgs{Side: Buy, Price: 57 * money.Cent, Size: 10.129}, 5_768_400, 10_120_000},
+		{"buy keeps float hundredths", OrderArgs{Side: Buy, Price: 57 * money.Cent, Size: 0.29}, 165_300, 290_000},
+		{"sell swaps amounts", OrderArgs{Side: Sell, Price: 43 * money.Cent, Size: 5}, 5_000_000, 2_150_000},
+		{"tenth-cent tick", OrderArgs{Side: Buy, Price: money.FromFloat(0.573), Size: 3.333, TickSize: money.FromFloat(0.001)}, 1_908_090, 3_330_000},
+	}
+	for _, tc := range cases {
+		tc.args.TokenID = "1234"
+		signed, err := client.BuildOrder(tc.args)
+		if err != nil {
+			t.Fatalf("%s: build: %v", tc.name, err)
+		}
+		if signed.MakerAmount != tc.maker || signed.TakerAmount != tc.taker {
+			t.Errorf("%s: maker=%d taker=%d, want %d/%d", tc.name, signed.MakerAmount, signed.TakerAmount, tc.maker, tc.taker)
+		}
+		if signed.Price() != tc.args.Price {
+			t.Errorf("%s: implied price %s, want %s", tc.name, signed.Price(), tc.args.Price)
+		}
+		hash, err := OrderHash(signed.Order, PolygonChainID, ExchangeAddress)
+		if err != nil {
+			t.Fatalf("%s: order hash: %v", tc.name, err)
+		}
+		sig, err := DecodeSignature(signed.Signature)
+		if err != nil {
+			t.Fatalf("%s: decode signature: %v", tc.name, err)
+		}
+		if got, err := RecoverAddress(hash, sig); err != nil || got != client.Address() {
+			t.Errorf("%s: signer = %s, %v; want %s", tc.name, got, err, client.Address())
+		}
+	}
+
+	for name, args := range map[string]OrderArgs{
+		"size rounds to zero": {Side: Buy, Price: 50 * money.Cent, Size: 0.004},
+		"off tick":            {Side: Buy, Price: money.FromFloat(0.505), Size: 1},
+		"tick too fine":       {Side: Buy, Price: 50 * money.Cent, Size: 1, TickSize: money.FromFloat(0.00001)},
+		"price at one":        {Side: Sell, Price: money.Dollar, Size: 1},
+		"unknown side":        {Side: "HOLD", Price: 50 * money.Cent, Size: 1},
+	} {
+		args.TokenID = "1234"
+		if _, err := client.BuildOrder(args); err == nil {
+			t.Errorf("%s: build succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,645 @@
+package polymarket
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+const defaultCLOBURL = "https://clob.polymarket.com"
+
+// Authentication headers of the CLOB API. L1 requests (credential
+// management) carry an EIP-712 wallet signature plus a nonce; L2 requests
+// (trading) carry the API key, passphrase and an HMAC signature.
+const (
+	HeaderAddress    = "POLY_ADDRESS"
+	HeaderSignature  = "POLY_SIGNATURE"
+	HeaderTimestamp  = "POLY_TIMESTAMP"
+	HeaderNonce      = "POLY_NONCE"
+	HeaderAPIKey     = "POLY_API_KEY"
+	HeaderPassphrase = "POLY_PASSPHRASE"
+)
+
+// APICreds are the L2 credentials derived from the wallet.
+type APICreds struct {
+	APIKey     string `json:"apiKey"`
+	Secret     string `json:"secret"`
+	Passphrase string `json:"passphrase"`
+}
+
+// SignatureType tells the exchange how the maker relates to the signer.
+type SignatureType int
+
+const (
+	// SignatureEOA: the signing wallet is the maker and holds the funds.
+	SignatureEOA SignatureType = 0
+	// SignaturePolyProxy and SignatureGnosisSafe: the funds sit in a
+	// Polymarket proxy wallet or Safe (the funder) the signer controls.
+	SignaturePolyProxy  SignatureType = 1
+	SignatureGnosisSafe SignatureType = 2
+)
+
+// Side is whether an order buys or sells outcome tokens.
+type Side string
+
+const (
+	Buy  Side = "BUY"
+	Sell Side = "SELL"
+)
+
+func (s Side) code() (int64, error) {
+	switch s {
+	case Buy:
+		return 0, nil
+	case Sell:
+		return 1, nil
+	default:
+		return 0, fmt.Errorf("unknown side %q", s)
+	}
+}
+
+// OrderType controls what happens to the unfilled part of an order.
+type OrderType string
+
+const (
+	// GoodTillCanceled rests the remainder on the book.
+	GoodTillCanceled OrderType = "GTC"
+	// FillOrKill fills the whole order immediately or rejects it.
+	FillOrKill OrderType = "FOK"
+	// FillAndKill fills what it can immediately and cancels the rest.
+	FillAndKill OrderType = "FAK"
+)
+
+// OrderStatus is what the exchange did with a posted order.
+type OrderStatus string
+
+const (
+	OrderLive      OrderStatus = "live"
+	OrderMatched   OrderStatus = "matched"
+	OrderDelayed   OrderStatus = "delayed"
+	OrderUnmatched OrderStatus = "unmatched"
+)
+
+// Order is the EIP-712 order struct of the CTF exchange. Amounts are in
+// base units with six decimals for both USDC and outcome tokens.
+type Order struct {
+	Salt          int64
+	Maker         string
+	Signer        string
+	Taker         string
+	TokenID       string // uint256 in decimal
+	MakerAmount   int64
+	TakerAmount   int64
+	Expiration    int64
+	Nonce         int64
+	FeeRateBps    int64
+	Side          Side
+	SignatureType SignatureType
+}
+
+// Price is the limit price implied by the amounts.
+func (o Order) Price() money.Micros {
+	switch o.Side {
+	case Buy:
+		if o.TakerAmount > 0 {
+			return money.Micros(o.MakerAmount * int64(money.Dollar) / o.TakerAmount)
+		}
+	case Sell:
+		if o.MakerAmount > 0 {
+			return money.Micros(o.TakerAmount * int64(money.Dollar) / o.MakerAmount)
+		}
+	}
+	return 0
+}
+
+// Size is the number of outcome tokens, in base units, the order trades.
+func (o Order) Size() int64 {
+	if o.Side == Buy {
+		return o.TakerAmount
+	}
+	return o.MakerAmount
+}
+
+// SignedOrder is an order with the signer's EIP-712 signature.
+type SignedOrder struct {
+	Order
+	Signature string
+	// NegRisk records which exchange contract the order was signed for.
+	NegRisk bool
+}
+
+// ID is the order hash the exchange identifies the order by.
+func (o *SignedOrder) ID(chainID int64) (string, error) {
+	hash, err := OrderHash(o.Order, chainID, exchangeFor(o.NegRisk))
+	if err != nil {
+		return "", err
+	}
+	return EncodeSignature(hash), nil
+}
+
+// OrderArgs describes a limit order to build and sign.
+type OrderArgs struct {
+	TokenID string
+	Side    Side
+	// Price is the limit price per token; it must be a multiple of TickSize.
+	Price money.Micros
+	// Size is the number of tokens; it is rounded down to hundredths.
+	Size float64
+	// TickSize is the market's minimum price increment (default one cent).
+	TickSize money.Micros
+	// NegRisk markets settle through the neg-risk exchange contract.
+	NegRisk    bool
+	FeeRateBps int64
+}
+
+// OrderResult is the exchange's answer to a posted order.
+type OrderResult struct {
+	ID       string
+	Status   OrderStatus
+	Side     Side
+	ErrorMsg string
+	// MakingAmount and TakingAmount are what the order gave and received
+	// when it matched: USDC and tokens for a buy, the reverse for a sell.
+	MakingAmount      money.Micros
+	TakingAmount      money.Micros
+	TransactionHashes []string
+}
+
+// FilledSize is the number of tokens the order traded on submission.
+func (r *OrderResult) FilledSize() float64 {
+	if r.Side == Buy {
+		return r.TakingAmount.Float()
+	}
+	return r.MakingAmount.Float()
+}
+
+// FilledUSD is the USDC the order paid (buy) or received (sell).
+func (r *OrderResult) FilledUSD() money.Micros {
+	if r.Side == Buy {
+		return r.MakingAmount
+	}
+	return r.TakingAmount
+}
+
+// CancelResult lists the orders a cancel request removed and why the rest
+// were not.
+type CancelResult struct {
+	Canceled    []string          `json:"canceled"`
+	NotCanceled map[string]string `json:"not_canceled"`
+}
+
+// TradingConfig configures an authenticated TradingClient.
+type TradingConfig struct {
+	// BaseURL is the CLOB API root.
+	BaseURL string
+	// PrivateKey is the hex-encoded wallet key that signs orders.
+	PrivateKey string
+	// Funder is the address holding the funds when it differs from the
+	// signer (proxy or Safe wallets); it defaults to the signer.
+	Funder        string
+	SignatureType SignatureType
+	ChainID       int64
+	// Creds are existing API credentials; without them, call
+	// CreateOrDeriveAPIKey before trading.
+	Creds   *APICreds
+	Timeout time.Duration
+}
+
+// TradingClient builds, signs, posts and cancels orders on the Polymarket
+// CLOB.
+type TradingClient struct {
+	baseURL       string
+	signer        *Signer
+	funder        string
+	signatureType SignatureType
+	chainID       int64
+	creds         *APICreds
+	httpClient    *http.Client
+}
+
+// NewTradingClient builds a client for the wallet key in cfg.
+func NewTradingClient(cfg TradingConfig) (*TradingClient, error) {
+	signer, err := NewSigner(cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	base := cfg.BaseURL
+	if base == "" {
+		base = defaultCLOBURL
+	}
+	funder := signer.Address()
+	if cfg.Funder != "" {
+		if funder, err = ChecksumAddress(cfg.Funder); err != nil {
+			return nil, fmt.Errorf("polymarket funder: %w", err)
+		}
+	}
+	if cfg.SignatureType == SignatureEOA && funder != signer.Address() {
+		return nil, fmt.Errorf("polymarket: an EOA signature needs the funder to be the signer")
+	}
+	chainID := cfg.ChainID
+	if chainID == 0 {
+		chainID = PolygonChainID
+	}
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	return &TradingClient{
+		baseURL:       strings.TrimRight(base, "/"),
+		signer:        signer,
+		funder:        funder,
+		signatureType: cfg.SignatureType,
+		chainID:       chainID,
+		creds:         cfg.Creds,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}, nil
+}
+
+// Address is the signing wallet's address.
+func (c *TradingClient) Address() string {
+	return c.signer.Address()
+}
+
+// Creds returns the API credentials in use, if any.
+func (c *TradingClient) Creds() *APICreds {
+	return c.creds
+}
+
+// CreateAPIKey creates new API credentials for the wallet and uses them.
+func (c *TradingClient) CreateAPIKey(ctx context.Context) (*APICreds, error) {
+	return c.apiKey(ctx, http.MethodPost, "/auth/api-key")
+}
+
+// DeriveAPIKey recovers the wallet's existing API credentials and uses
+// them.
+func (c *TradingClient) DeriveAPIKey(ctx context.Context) (*APICreds, error) {
+	return c.apiKey(ctx, http.MethodGet, "/auth/derive-api-key")
+}
+
+// CreateOrDeriveAPIKey creates credentials, falling back to deriving the
+// existing ones when the wallet already has a key.
+func (c *TradingClient) CreateOrDeriveAPIKey(ctx context.Context) (*APICreds, error) {
+	creds, err := c.CreateAPIKey(ctx)
+	if err == nil {
+		return creds, nil
+	}
+	return c.DeriveAPIKey(ctx)
+}
+
+func (c *TradingClient) apiKey(ctx context.Context, method, path string) (*APICreds, error) {
+	var creds APICreds
+	if err := c.call(ctx, method, path, nil, &creds, c.l1Headers); err != nil {
+		return nil, err
+	}
+	if creds.APIKey == "" || creds.Secret == "" {
+		return nil, fmt.Errorf("polymarket: empty api credentials")
+	}
+	c.creds = &creds
+	return &creds, nil
+}
+
+// BuildOrder converts a limit price and size into exchange amounts and
+// signs the order. Buys give price × size USDC for size tokens; sells give
+// size tokens for price × size USDC.
+func (c *TradingClient) BuildOrder(args OrderArgs) (*SignedOrder, error) {
+	if args.TokenID == "" {
+		return nil, fmt.Errorf("polymarket order: token id required")
+	}
+	tick := args.TickSize
+	if tick == 0 {
+		tick = money.Cent
+	}
+	if tick < 100 || tick%100 != 0 {
+		return nil, fmt.Errorf("polymarket order: tick size %s is finer than 0.0001", tick)
+	}
+	if args.Price < tick || args.Price > money.Dollar-tick || args.Price%tick != 0 {
+		return nil, fmt.Errorf("polymarket order: price %s is not a multiple of tick %s inside (0, 1)", args.Price, tick)
+	}
+	hundredths := int64(math.Floor(args.Size*100 + 1e-9))
+	if hundredths <= 0 {
+		return nil, fmt.Errorf("polymarket order: size %.4f rounds to zero", args.Size)
+	}
+	tokens := hundredths * 10_000
+	usdc := hundredths * int64(args.Price) / 100
+	salt, err := newSalt()
+	if err != nil {
+		return nil, err
+	}
+	order := Order{
+		Salt:          salt,
+		Maker:         c.funder,
+		Signer:        c.signer.Address(),
+		Taker:         ZeroAddress,
+		TokenID:       args.TokenID,
+		FeeRateBps:    args.FeeRateBps,
+		Side:          args.Side,
+		SignatureType: c.signatureType,
+	}
+	switch args.Side {
+	case Buy:
+		order.MakerAmount, order.TakerAmount = usdc, tokens
+	case Sell:
+		order.MakerAmount, order.TakerAmount = tokens, usdc
+	default:
+		return nil, fmt.Errorf("polymarket order: unknown side %q", args.Side)
+	}
+	return c.SignOrder(order, args.NegRisk)
+}
+
+// SignOrder signs a fully specified order for the exchange contract it
+// settles through.
+func (c *TradingClient) SignOrder(order Order, negRisk bool) (*SignedOrder, error) {
+	hash, err := OrderHash(order, c.chainID, exchangeFor(negRisk))
+	if err != nil {
+		return nil, fmt.Errorf("polymarket order: %w", err)
+	}
+	return &SignedOrder{
+		Order:     order,
+		Signature: EncodeSignature(c.signer.SignHash(hash)),
+		NegRisk:   negRisk,
+	}, nil
+}
+
+// PostOrder submits a signed order. Re-posting the same signed order is
+// rejected as a duplicate, so retrying a write is safe.
+func (c *TradingClient) PostOrder(ctx context.Context, order *SignedOrder, orderType OrderType) (*OrderResult, error) {
+	if c.creds == nil {
+		return nil, fmt.Errorf("polymarket: api credentials required to trade")
+	}
+	body := wirePostOrder{
+		Order:     toWireOrder(order),
+		Owner:     c.creds.APIKey,
+		OrderType: orderType,
+	}
+	var out wireOrderResponse
+	if err := c.call(ctx, http.MethodPost, "/order", body, &out, c.l2Headers); err != nil {
+		return nil, err
+	}
+	if !out.Success && out.ErrorMsg != "" {
+		return nil, &APIError{StatusCode: http.StatusOK, Message: out.ErrorMsg}
+	}
+	return &OrderResult{
+		ID:                out.OrderID,
+		Status:            out.Status,
+		Side:              order.Side,
+		ErrorMsg:          out.ErrorMsg,
+		MakingAmount:      parseAmount(out.MakingAmount),
+		TakingAmount:      parseAmount(out.TakingAmount),
+		TransactionHashes: out.TransactionsHashes,
+	}, nil
+}
+
+// PlaceOrder builds, signs and posts an order in one step.
+func (c *TradingClient) PlaceOrder(ctx context.Context, args OrderArgs, orderType OrderType) (*OrderResult, error) {
+	order, err := c.BuildOrder(args)
+	if err != nil {
+		return nil, err
+	}
+	return c.PostOrder(ctx, order, orderType)
+}
+
+// CancelOrder cancels one resting order.
+func (c *TradingClient) CancelOrder(ctx context.Context, orderID string) (*CancelResult, error) {
+	var out CancelResult
+	body := map[string]string{"orderID": orderID}
+	if err := c.call(ctx, http.MethodDelete, "/order", body, &out, c.l2Headers); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelOrders cancels several resting orders.
+func (c *TradingClient) CancelOrders(ctx context.Context, orderIDs []string) (*CancelResult, error) {
+	var out CancelResult
+	if err := c.call(ctx, http.MethodDelete, "/orders", orderIDs, &out, c.l2Headers); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// APIError is a rejected CLOB request.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("polymarket CLOB %d: %s", e.StatusCode, e.Message)
+}
+
+type headerFunc func(h http.Header, method, path string, body []byte) error
+
+// l1Headers signs the ClobAuth message with the wallet key.
+func (c *TradingClient) l1Headers(h http.Header, _, _ string, _ []byte) error {
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	hash, err := ClobAuthHash(c.signer.Address(), ts, 0, c.chainID)
+	if err != nil {
+		return err
+	}
+	h.Set(HeaderAddress, c.signer.Address())
+	h.Set(HeaderSignature, EncodeSignature(c.signer.SignHash(hash)))
+	h.Set(HeaderTimestamp, ts)
+	h.Set(HeaderNonce, "0")
+	return nil
+}
+
+// l2Headers signs the request with the API secret.
+func (c *TradingClient) l2Headers(h http.Header, method, path string, body []byte) error {
+	if c.creds == nil {
+		return fmt.Errorf("polymarket: api credentials required")
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	sig, err := BuildHMAC(c.creds.Secret, ts, method, path, string(body))
+	if err != nil {
+		return err
+	}
+	h.Set(HeaderAddress, c.signer.Address())
+	h.Set(HeaderSignature, sig)
+	h.Set(HeaderTimestamp, ts)
+	h.Set(HeaderAPIKey, c.creds.APIKey)
+	h.Set(HeaderPassphrase, c.creds.Passphrase)
+	return nil
+}
+
+// call signs and sends one request. Only GETs are retried; a re-posted
+// order is rejected as a duplicate rather than filled twice, but the caller
+// decides whether that is worth it.
+func (c *TradingClient) call(ctx context.Context, method, path string, body, dst any, sign headerFunc) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+	}
+	var attempt int
+	for {
+		attempt++
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		// Signatures cover the timestamp, so every attempt re-signs.
+		if err := sign(req.Header, method, path, payload); err != nil {
+			return err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			if method == http.MethodGet && shouldRetry(attempt, 0) {
+				sleep(attempt)
+				continue
+			}
+			return err
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			if dst == nil {
+				return nil
+			}
+			return json.NewDecoder(resp.Body).Decode(dst)
+		}
+
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		resp.Body.Close()
+		if method == http.MethodGet && shouldRetry(attempt, resp.StatusCode) {
+			sleep(attempt)
+			continue
+		}
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(raw))}
+		var wrapped struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(raw, &wrapped) == nil && wrapped.Error != "" {
+			apiErr.Message = wrapped.Error
+		}
+		return apiErr
+	}
+}
+
+func exchangeFor(negRisk bool) string {
+	if negRisk {
+		return NegRiskExchangeAddress
+	}
+	return ExchangeAddress
+}
+
+// newSalt draws a random salt below 2^53 so it survives JSON number
+// decoding on the server.
+func newSalt() (int64, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1<<53))
+	if err != nil {
+		return 0, fmt.Errorf("polymarket salt: %w", err)
+	}
+	return n.Int64(), nil
+}
+
+func parseAmount(raw string) money.Micros {
+	if raw == "" {
+		return 0
+	}
+	m, err := money.ParseDecimal(raw)
+	if err != nil {
+		return 0
+	}
+	return m
+}
+
+// WireOrder is the JSON form of a signed order in POST /order.
+type WireOrder struct {
+	Salt          int64         `json:"salt"`
+	Maker         string        `json:"maker"`
+	Signer        string        `json:"signer"`
+	Taker         string        `json:"taker"`
+	TokenID       string        `json:"tokenId"`
+	MakerAmount   string        `json:"makerAmount"`
+	TakerAmount   string        `json:"takerAmount"`
+	Expiration    string        `json:"expiration"`
+	Nonce         string        `json:"nonce"`
+	FeeRateBps    string        `json:"feeRateBps"`
+	Side          Side          `json:"side"`
+	SignatureType SignatureType `json:"signatureType"`
+	Signature     string        `json:"signature"`
+}
+
+func toWireOrder(o *SignedOrder) WireOrder {
+	return WireOrder{
+		Salt:          o.Salt,
+		Maker:         o.Maker,
+		Signer:        o.Signer,
+		Taker:         o.Taker,
+		TokenID:       o.TokenID,
+		MakerAmount:   strconv.FormatInt(o.MakerAmount, 10),
+		TakerAmount:   strconv.FormatInt(o.TakerAmount, 10),
+		Expiration:    strconv.FormatInt(o.Expiration, 10),
+		Nonce:         strconv.FormatInt(o.Nonce, 10),
+		FeeRateBps:    strconv.FormatInt(o.FeeRateBps, 10),
+		Side:          o.Side,
+		SignatureType: o.SignatureType,
+		Signature:     o.Signature,
+	}
+}
+
+// Order converts the wire form back into the struct that was signed.
+func (w WireOrder) Order() (Order, error) {
+	o := Order{
+		Salt:          w.Salt,
+		Maker:         w.Maker,
+		Signer:        w.Signer,
+		Taker:         w.Taker,
+		TokenID:       w.TokenID,
+		Side:          w.Side,
+		SignatureType: w.SignatureType,
+	}
+	fields := []struct {
+		name string
+		raw  string
+		dst  *int64
+	}{
+		{"makerAmount", w.MakerAmount, &o.MakerAmount},
+		{"takerAmount", w.TakerAmount, &o.TakerAmount},
+		{"expiration", w.Expiration, &o.Expiration},
+		{"nonce", w.Nonce, &o.Nonce},
+		{"feeRateBps", w.FeeRateBps, &o.FeeRateBps},
+	}
+	for _, f := range fields {
+		v, err := strconv.ParseInt(f.raw, 10, 64)
+		if err != nil || v < 0 {
+			return Order{}, fmt.Errorf("invalid %s %q", f.name, f.raw)
+		}
+		*f.dst = v
+	}
+	return o, nil
+}
+
+type wirePostOrder struct {
+	Order     WireOrder `json:"order"`
+	Owner     string    `json:"owner"`
+	OrderType OrderType `json:"orderType"`
+}
+
+type wireOrderResponse struct {
+	Success            bool        `json:"success"`
+	ErrorMsg           string      `json:"errorMsg"`
+	OrderID            string      `json:"orderID"`
+	Status             OrderStatus `json:"status"`
+	MakingAmount       string      `json:"makingAmount"`
+	TakingAmount       string      `json:"takingAmount"`
+	TransactionsHashes []string    `json:"transactionsHashes"`
+}
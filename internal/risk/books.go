@@ -0,0 +1,86 @@
+package risk
+
+import (
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/execution"
+	"github.com/hetulpatel/Arbitrage/internal/ledger"
+	"github.com/hetulpatel/Arbitrage/internal/paper"
+)
+
+// PaperBook builds the book of the paper trader: open positions at their
+// entry cost, and the realized P&L of positions settled since dayStart.
+func PaperBook(positions []paper.Position, dayStart time.Time) Book {
+	var book Book
+	for i := range positions {
+		pos := &positions[i]
+		switch pos.Status {
+		case paper.StatusOpen:
+			open := Position{PairID: pos.PairID}
+			for _, leg := range pos.Legs {
+				open.Legs = append(open.Legs, Exposure{
+					Venue:       leg.Venue,
+					EventID:     leg.EventID,
+					MarketID:    leg.MarketID,
+					Category:    leg.Category,
+					NotionalUSD: leg.CostUSD + leg.FeeUSD,
+				})
+			}
+			book.Open = append(book.Open, open)
+		case paper.StatusSettled:
+			if !pos.SettledAt.Before(dayStart) {
+				book.RealizedTodayUSD += pos.RealizedUSD()
+			}
+		}
+	}
+	return book
+}
+
+// ExecutionBook builds the book of the executor from executions of one mode
+// (live or dry run) and that mode's ledger positions. Executions holding
+// contracts, including ones that stopped mid-run, are open until their
+// markets close, at the cost of what is held. Realized today is the P&L of
+// ledger positions settled since dayStart, divergent resolutions included,
+// plus repair losses booked since dayStart on executions not yet settled
+// (a settled position's P&L already contains them).
+func ExecutionBook(execs []execution.Execution, settled []ledger.Position, now, dayStart time.Time) Book {
+	var book Book
+	counted := make(map[int64]bool)
+	for i := range settled {
+		pos := &settled[i]
+		if pos.Status != ledger.StatusSettled || pos.SettledAt.Before(dayStart) {
+			continue
+		}
+		book.RealizedTodayUSD += pos.RealizedUSD()
+		counted[pos.RefID] = true
+	}
+	for i := range execs {
+		e := &execs[i]
+		if !e.UpdatedAt.Before(dayStart) && !counted[e.ID] {
+			book.RealizedTodayUSD -= e.LossUSD
+		}
+		if !e.SettlesAt.IsZero() && !now.Before(e.SettlesAt) {
+			continue
+		}
+		open := Position{PairID: e.PairID}
+		for j := range e.Legs {
+			leg := &e.Legs[j]
+			held := leg.Held()
+			if held <= 0 || leg.Filled <= 0 {
+				continue
+			}
+			open.Legs = append(open.Legs, Exposure{
+				Venue:       collectors.Venue(leg.Venue),
+				EventID:     leg.EventID,
+				MarketID:    leg.MarketID,
+				Category:    leg.Category,
+				NotionalUSD: (leg.CostUSD + leg.FeeUSD).Mul(held / leg.Filled),
+			})
+		}
+		if len(open.Legs) > 0 {
+			book.Open = append(book.Open, open)
+		}
+	}
+	return book
+}
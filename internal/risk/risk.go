@@ -0,0 +1,251 @@
+// Package risk decides whether a trade may be sent. A Guard compares the
+// trade against what is already held — notional per pair, event, category
+// and venue, the number of open pairs and the day's realized loss — and
+// against a global kill switch, and returns the first limit it would break.
+package risk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/models"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+	"github.com/hetulpatel/Arbitrage/internal/paper"
+)
+
+// Reason names the limit a blocked trade would have broken.
+type Reason string
+
+const (
+	ReasonKillSwitch       Reason = "kill_switch"
+	ReasonDailyLoss        Reason = "daily_loss"
+	ReasonOpenPairs        Reason = "open_pairs"
+	ReasonPairNotional     Reason = "pair_notional"
+	ReasonEventNotional    Reason = "event_notional"
+	ReasonCategoryNotional Reason = "category_notional"
+	ReasonVenueNotional    Reason = "venue_notional"
+)
+
+// Limits are the pre-trade limits. Zero disables a limit.
+type Limits struct {
+	MaxPairNotionalUSD     money.Micros
+	MaxEventNotionalUSD    money.Micros
+	MaxCategoryNotionalUSD money.Micros
+	MaxVenueNotionalUSD    money.Micros
+	// DailyLossLimitUSD stops trading once the realized loss since UTC
+	// midnight reaches it.
+	DailyLossLimitUSD money.Micros
+	MaxOpenPairs      int
+}
+
+// LimitsFromEnv reads the RISK_* variables shared by every trading command.
+func LimitsFromEnv() Limits {
+	return Limits{
+		MaxPairNotionalUSD:     envMicros("RISK_MAX_PAIR_NOTIONAL_USD"),
+		MaxEventNotionalUSD:    envMicros("RISK_MAX_EVENT_NOTIONAL_USD"),
+		MaxCategoryNotionalUSD: envMicros("RISK_MAX_CATEGORY_NOTIONAL_USD"),
+		MaxVenueNotionalUSD:    envMicros("RISK_MAX_VENUE_NOTIONAL_USD"),
+		DailyLossLimitUSD:      envMicros("RISK_DAILY_LOSS_LIMIT_USD"),
+		MaxOpenPairs:           envInt("RISK_MAX_OPEN_PAIRS"),
+	}
+}
+
+// Exposure is the capital one leg puts at risk in a market.
+type Exposure struct {
+	Venue       collectors.Venue `json:"venue"`
+	EventID     string           `json:"event_id,omitempty"`
+	MarketID    string           `json:"market_id"`
+	Category    string           `json:"category,omitempty"`
+	NotionalUSD money.Micros     `json:"notional_usd"`
+}
+
+// Position is an open pair and its legs.
+type Position struct {
+	PairID string
+	Legs   []Exposure
+}
+
+// Book is what is already held and lost, from one trading mode's records.
+type Book struct {
+	Open []Position
+	// RealizedTodayUSD is the net realized P&L since UTC midnight.
+	RealizedTodayUSD money.Micros
+}
+
+// Trade is a proposed trade of one opportunity.
+type Trade struct {
+	PairID    string
+	Direction matches.Direction
+	Legs      []Exposure
+}
+
+// NotionalUSD is the capital the whole trade puts at risk.
+func (t Trade) NotionalUSD() money.Micros {
+	var total money.Micros
+	for _, leg := range t.Legs {
+		total += leg.NotionalUSD
+	}
+	return total
+}
+
+// NewTrade prices every order of op at its limit plus fees. snaps, keyed by
+// paper.SnapshotKey, supply each order's event and category.
+func NewTrade(pairID string, op *matches.Opportunity, snaps map[string]*models.MarketSnapshot) (Trade, error) {
+	if op == nil || len(op.Orders) == 0 {
+		return Trade{}, fmt.Errorf("opportunity has no orders")
+	}
+	trade := Trade{PairID: pairID, Direction: op.Direction}
+	for _, order := range op.Orders {
+		snap := snaps[paper.SnapshotKey(order.Venue, order.MarketID)]
+		if snap == nil {
+			return Trade{}, fmt.Errorf("no snapshot for %s %s", order.Venue, order.MarketID)
+		}
+		trade.Legs = append(trade.Legs, Exposure{
+			Venue:       snap.Venue,
+			EventID:     snap.Event.EventID,
+			MarketID:    order.MarketID,
+			Category:    snap.Event.Category,
+			NotionalUSD: order.LimitPrice.Mul(order.Quantity) + order.FeeUSD,
+		})
+	}
+	return trade, nil
+}
+
+// Block records a trade the guard refused.
+type Block struct {
+	ID int64 `json:"id,omitempty"`
+	// Source is the trading mode that was blocked: paper, dry_run or live.
+	Source      string            `json:"source"`
+	PairID      string            `json:"pair_id"`
+	Direction   matches.Direction `json:"direction"`
+	Reason      Reason            `json:"reason"`
+	Detail      string            `json:"detail"`
+	NotionalUSD money.Micros      `json:"notional_usd"`
+	Legs        []Exposure        `json:"legs"`
+	At          time.Time         `json:"at"`
+}
+
+// Switch is the global kill switch.
+type Switch interface {
+	// Engaged reports whether trading is halted and why.
+	Engaged(ctx context.Context) (bool, string, error)
+}
+
+// Guard checks trades against the limits and the kill switch.
+type Guard struct {
+	Limits Limits
+	// Switch may be nil to run without a kill switch.
+	Switch Switch
+}
+
+// Check returns the first limit the trade would break, or nil when it may
+// go ahead. A kill switch that cannot be read blocks: a halt must not be
+// missed because Redis is down.
+func (g *Guard) Check(ctx context.Context, source string, book Book, trade Trade) *Block {
+	block := func(reason Reason, format string, args ...any) *Block {
+		return &Block{
+			Source:      source,
+			PairID:      trade.PairID,
+			Direction:   trade.Direction,
+			Reason:      reason,
+			Detail:      fmt.Sprintf(format, args...),
+			NotionalUSD: trade.NotionalUSD(),
+			Legs:        trade.Legs,
+			At:          time.Now().UTC(),
+		}
+	}
+	if g.Switch != nil {
+		engaged, why, err := g.Switch.Engaged(ctx)
+		if err != nil {
+			return block(ReasonKillSwitch, "kill switch unreadable: %v", err)
+		}
+		if engaged {
+			return block(ReasonKillSwitch, "kill switch engaged: %s", why)
+		}
+	}
+	l := g.Limits
+	if l.DailyLossLimitUSD > 0 && -book.RealizedTodayUSD >= l.DailyLossLimitUSD {
+		return block(ReasonDailyLoss, "realized today %.2f, limit -%.2f", book.RealizedTodayUSD, l.DailyLossLimitUSD)
+	}
+
+	pairs := make(map[string]bool)
+	pair := make(map[string]money.Micros)
+	event := make(map[string]money.Micros)
+	category := make(map[string]money.Micros)
+	venue := make(map[collectors.Venue]money.Micros)
+	add := func(pairID string, legs []Exposure) {
+		for _, leg := range legs {
+			pair[pairID] += leg.NotionalUSD
+			if leg.EventID != "" {
+				event[eventKey(leg)] += leg.NotionalUSD
+			}
+			if c := categoryKey(leg.Category); c != "" {
+				category[c] += leg.NotionalUSD
+			}
+			venue[leg.Venue] += leg.NotionalUSD
+		}
+	}
+	for _, pos := range book.Open {
+		pairs[pos.PairID] = true
+		add(pos.PairID, pos.Legs)
+	}
+	if l.MaxOpenPairs > 0 && !pairs[trade.PairID] && len(pairs) >= l.MaxOpenPairs {
+		return block(ReasonOpenPairs, "%d pairs open, limit %d", len(pairs), l.MaxOpenPairs)
+	}
+	add(trade.PairID, trade.Legs)
+
+	if l.MaxPairNotionalUSD > 0 && pair[trade.PairID] > l.MaxPairNotionalUSD {
+		return block(ReasonPairNotional, "pair notional %.2f, limit %.2f", pair[trade.PairID], l.MaxPairNotionalUSD)
+	}
+	for _, leg := range trade.Legs {
+		if key := eventKey(leg); l.MaxEventNotionalUSD > 0 && leg.EventID != "" && event[key] > l.MaxEventNotionalUSD {
+			return block(ReasonEventNotional, "event %s notional %.2f, limit %.2f", key, event[key], l.MaxEventNotionalUSD)
+		}
+		if c := categoryKey(leg.Category); l.MaxCategoryNotionalUSD > 0 && c != "" && category[c] > l.MaxCategoryNotionalUSD {
+			return block(ReasonCategoryNotional, "category %s notional %.2f, limit %.2f", c, category[c], l.MaxCategoryNotionalUSD)
+		}
+		if l.MaxVenueNotionalUSD > 0 && venue[leg.Venue] > l.MaxVenueNotionalUSD {
+			return block(ReasonVenueNotional, "venue %s notional %.2f, limit %.2f", leg.Venue, venue[leg.Venue], l.MaxVenueNotionalUSD)
+		}
+	}
+	return nil
+}
+
+// eventKey scopes event ids to their venue.
+func eventKey(leg Exposure) string {
+	return string(leg.Venue) + ":" + leg.EventID
+}
+
+// categoryKey folds the venues' category spellings together.
+func categoryKey(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
+
+// DayStart is UTC midnight of the day containing now.
+func DayStart(now time.Time) time.Time {
+	return now.UTC().Truncate(24 * time.Hour)
+}
+
+func envMicros(key string) money.Micros {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := money.ParseDecimal(val); err == nil {
+			return parsed
+		}
+	}
+	return 0
+}
+
+func envInt(key string) int {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.Atoi(val); err == nil {
+			return parsed
+		}
+	}
+	return 0
+}
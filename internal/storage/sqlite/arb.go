@@ -2,15 +2,18 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/hetulpatel/Arbitrage/internal/arb"
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
 	"github.com/hetulpatel/Arbitrage/internal/matches"
 )
 
-// InsertArbOpportunity stores the outcome of an arb evaluation (profitable or not).
+// InsertArbOpportunity stores the outcome of an arb evaluation (profitable or
+// not) along with any per-budget sweep results.
 func (s *Store) InsertArbOpportunity(ctx context.Context, payload *matches.Payload, result arb.Result) error {
 	if s == nil || s.db == nil || payload == nil {
 		return fmt.Errorf("sqlite store not initialized or payload nil")
@@ -19,6 +22,10 @@ func (s *Store) InsertArbOpportunity(ctx context.Context, payload *matches.Paylo
 	if best == nil {
 		best = &matches.Opportunity{}
 	}
+	legRisk := best.LegRisk
+	if legRisk == nil {
+		legRisk = &matches.LegRisk{}
+	}
 
 	legsJSON, err := json.Marshal(best.Legs)
 	if err != nil {
@@ -38,12 +45,25 @@ INSERT INTO arb_opportunities (
 	similarity, distance, matched_at, processed_at,
 	direction, qty_contracts, total_cost_usd, profit_usd,
 	budget_usd, kalshi_fees_usd, polymarket_fees_usd,
-	legs_json, raw_payload_json
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	legs_json, raw_payload_json,
+	return_on_capital, annualized_yield, settles_at,
+	reject_code, reject_venue, leg_risk_loss_prob,
+	total_cost_micros, profit_micros, kalshi_fees_micros, polymarket_fees_micros,
+	score,
+	source_yes_price_micros, source_no_price_micros, target_yes_price_micros, target_no_price_micros,
+	budget_micros, payout_floor_micros, payout_max_micros,
+	leg_risk_expected_profit_micros, leg_risk_worst_loss_micros
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
 	processedAt := time.Now().UTC().Format(time.RFC3339Nano)
-	_, err = s.db.ExecContext(
+	res, err := tx.ExecContext(
 		ctx,
 		query,
 		payload.PairID,
@@ -63,13 +83,98 @@ INSERT INTO arb_opportunities (
 		processedAt,
 		best.Direction,
 		best.Quantity,
-		best.TotalCostUSD,
-		best.ProfitUSD,
+		best.TotalCostUSD.Float(),
+		best.ProfitUSD.Float(),
 		best.BudgetUSD,
-		best.KalshiFeesUSD,
-		best.PolymarketFeesUSD,
+		best.KalshiFeesUSD.Float(),
+		best.PolymarketFeesUSD.Float(),
 		string(legsJSON),
 		string(rawJSON),
+		best.ReturnOnCapital,
+		best.AnnualizedYield,
+		formatTime(best.SettlesAt),
+		string(result.Reason.Code),
+		string(result.Reason.Venue),
+		legRisk.LossProbability,
+		int64(best.TotalCostUSD),
+		int64(best.ProfitUSD),
+		int64(best.KalshiFeesUSD),
+		int64(best.PolymarketFeesUSD),
+		best.Score,
+		askMicros(payload.Source.Market, true),
+		askMicros(payload.Source.Market, false),
+		askMicros(payload.Target.Market, true),
+		askMicros(payload.Target.Market, false),
+		micros(best.BudgetUSD),
+		micros(best.PayoutFloorUSD),
+		micros(best.PayoutMaxUSD),
+		micros(legRisk.ExpectedProfitUSD),
+		micros(legRisk.WorstCaseLossUSD),
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if len(result.Sweep) > 0 {
+		id, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("opportunity id: %w", err)
+		}
+		if err := insertBudgetSweep(ctx, tx, id, payload.PairID, processedAt, result.Sweep); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func insertBudgetSweep(ctx context.Context, tx *sql.Tx, opportunityID int64, pairID, processedAt string, sweep []*matches.Opportunity) error {
+	stmt, err := tx.PrepareContext(ctx, `
+INSERT INTO arb_budget_sweeps (
+	opportunity_id, pair_id, processed_at, budget_micros, direction,
+	qty_contracts, total_cost_micros, profit_micros, return_on_capital, annualized_yield
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`)
+	if err != nil {
+		return fmt.Errorf("prepare sweep insert: %w", err)
+	}
+	defer stmt.Close()
+	for _, op := range sweep {
+		if op == nil {
+			continue
+		}
+		if _, err := stmt.ExecContext(ctx,
+			opportunityID,
+			pairID,
+			processedAt,
+			micros(op.BudgetUSD),
+			op.Direction,
+			op.Quantity,
+			int64(op.TotalCostUSD),
+			int64(op.ProfitUSD),
+			op.ReturnOnCapital,
+			op.AnnualizedYield,
+		); err != nil {
+			return fmt.Errorf("insert sweep budget=%.2f: %w", op.BudgetUSD, err)
+		}
+	}
+	return nil
+}
+
+// askMicros is a market's best YES or NO ask, read exactly from the captured
+// ladder. Markets without one fall back to the top-of-book quote, which the
+// venues send as cents or short decimals, so rounding it to micros loses
+// nothing.
+func askMicros(m collectors.Market, yes bool) int64 {
+	yesBook, noBook := splitOrderbooks(m)
+	book, quote := noBook, m.Price.NoAsk
+	if yes {
+		book, quote = yesBook, m.Price.YesAsk
+	}
+	if book == nil || len(book.Asks) == 0 {
+		return micros(quote)
+	}
+	best := book.Asks[0].Price
+	for _, l := range book.Asks[1:] {
+		best = min(best, l.Price)
+	}
+	return int64(best)
 }
@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/execution"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+const executionSchemaSQL = `
+CREATE TABLE IF NOT EXISTS executions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	pair_id TEXT NOT NULL,
+	direction TEXT NOT NULL,
+	dry_run INTEGER NOT NULL,
+	status TEXT NOT NULL,
+	legs_json TEXT NOT NULL,
+	payout_per_unit_micros INTEGER NOT NULL,
+	planned_profit_micros INTEGER NOT NULL,
+	max_loss_micros INTEGER NOT NULL,
+	loss_micros INTEGER NOT NULL,
+	hedged_quantity REAL NOT NULL,
+	unhedged_quantity REAL NOT NULL,
+	error TEXT,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL,
+	settles_at TEXT
+);
+CREATE INDEX IF NOT EXISTS executions_pair_idx ON executions(pair_id, status);
+CREATE TABLE IF NOT EXISTS execution_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	execution_id INTEGER NOT NULL REFERENCES executions(id),
+	status TEXT NOT NULL,
+	detail TEXT,
+	legs_json TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS execution_events_execution_idx ON execution_events(execution_id);
+`
+
+// RecordExecution saves an execution (inserting it on its first transition,
+// which sets its ID) and appends the transition with a copy of the legs at
+// that moment, in one transaction.
+func (s *Store) RecordExecution(ctx context.Context, e *execution.Execution, ev execution.Event) error {
+	if s == nil || s.db == nil || e == nil {
+		return fmt.Errorf("sqlite store not initialized or execution nil")
+	}
+	legsJSON, err := json.Marshal(e.Legs)
+	if err != nil {
+		return fmt.Errorf("marshal legs: %w", err)
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if e.ID == 0 {
+		res, err := tx.ExecContext(ctx, `
+INSERT INTO executions (
+	pair_id, direction, dry_run, status, legs_json, payout_per_unit_micros,
+	planned_profit_micros, max_loss_micros, loss_micros, hedged_quantity, unhedged_quantity,
+	error, created_at, updated_at, settles_at
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`,
+			e.PairID, e.Direction, e.DryRun, e.Status, string(legsJSON), micros(e.PayoutPerUnit),
+			int64(e.PlannedProfit), int64(e.MaxLossUSD), int64(e.LossUSD), e.HedgedQuantity(), e.UnhedgedQuantity(),
+			e.Error, formatTime(e.CreatedAt), formatTime(e.UpdatedAt), formatTime(e.SettlesAt),
+		)
+		if err != nil {
+			return fmt.Errorf("insert execution: %w", err)
+		}
+		if e.ID, err = res.LastInsertId(); err != nil {
+			return fmt.Errorf("execution id: %w", err)
+		}
+	} else {
+		_, err := tx.ExecContext(ctx, `
+UPDATE executions SET
+	status = ?, legs_json = ?, loss_micros = ?, hedged_quantity = ?, unhedged_quantity = ?,
+	error = ?, updated_at = ?
+WHERE id = ?
+`,
+			e.Status, string(legsJSON), int64(e.LossUSD), e.HedgedQuantity(), e.UnhedgedQuantity(),
+			e.Error, formatTime(e.UpdatedAt), e.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("update execution %d: %w", e.ID, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO execution_events (execution_id, status, detail, legs_json, created_at) VALUES (?, ?, ?, ?, ?)
+`, e.ID, ev.Status, ev.Detail, string(legsJSON), formatTime(ev.At)); err != nil {
+		return fmt.Errorf("insert execution event: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Executions lists executions with the given status, or all of them when
+// status is empty, oldest first.
+func (s *Store) Executions(ctx context.Context, status execution.Status) ([]execution.Execution, error) {
+	if status == "" {
+		return s.queryExecutions(ctx, "")
+	}
+	return s.queryExecutions(ctx, `WHERE status = ?`, status)
+}
+
+// RiskExecutions lists the executions of one mode the risk guard counts:
+// those whose markets have not closed by now, and those updated since
+// since, oldest first.
+func (s *Store) RiskExecutions(ctx context.Context, dryRun bool, now, since time.Time) ([]execution.Execution, error) {
+	return s.queryExecutions(ctx, `
+WHERE dry_run = ? AND (
+	COALESCE(settles_at, '') = '' OR julianday(settles_at) > julianday(?) OR julianday(updated_at) >= julianday(?)
+)`, dryRun, formatTime(now), formatTime(since))
+}
+
+func (s *Store) queryExecutions(ctx context.Context, where string, args ...any) ([]execution.Execution, error) {
+	query := `
+SELECT id, pair_id, direction, dry_run, status, legs_json, payout_per_unit_micros,
+	planned_profit_micros, max_loss_micros, loss_micros, error, created_at, updated_at, settles_at
+FROM executions ` + where
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []execution.Execution
+	for rows.Next() {
+		var (
+			e                              execution.Execution
+			direction, st, legsJSON        string
+			payout, planned, maxLoss, loss int64
+			errMsg, settlesAt              sql.NullString
+			createdAt, updatedAt           string
+		)
+		if err := rows.Scan(&e.ID, &e.PairID, &direction, &e.DryRun, &st, &legsJSON, &payout,
+			&planned, &maxLoss, &loss, &errMsg, &createdAt, &updatedAt, &settlesAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(legsJSON), &e.Legs); err != nil {
+			return nil, fmt.Errorf("execution %d legs: %w", e.ID, err)
+		}
+		e.PayoutPerUnit = money.Micros(payout).Float()
+		e.Direction = matches.Direction(direction)
+		e.Status = execution.Status(st)
+		e.PlannedProfit = money.Micros(planned)
+		e.MaxLossUSD = money.Micros(maxLoss)
+		e.LossUSD = money.Micros(loss)
+		e.Error = errMsg.String
+		e.CreatedAt = parseTime(createdAt)
+		e.UpdatedAt = parseTime(updatedAt)
+		e.SettlesAt = parseTime(settlesAt.String)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
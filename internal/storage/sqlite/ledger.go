@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/ledger"
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+)
+
+const ledgerSchemaSQL = `
+CREATE TABLE IF NOT EXISTS ledger_positions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	source TEXT NOT NULL,
+	ref_id INTEGER NOT NULL,
+	pair_id TEXT NOT NULL,
+	strategy TEXT NOT NULL,
+	direction TEXT NOT NULL,
+	status TEXT NOT NULL,
+	legs_json TEXT NOT NULL,
+	payout_per_unit_micros INTEGER NOT NULL,
+	cost_micros INTEGER NOT NULL,
+	proceeds_micros INTEGER NOT NULL,
+	payout_micros INTEGER NOT NULL,
+	realized_pnl_micros INTEGER,
+	set_payout_micros INTEGER,
+	divergent INTEGER NOT NULL DEFAULT 0,
+	opened_at TEXT NOT NULL,
+	settles_at TEXT,
+	settled_at TEXT,
+	UNIQUE (source, ref_id)
+);
+CREATE INDEX IF NOT EXISTS ledger_positions_pair_idx ON ledger_positions(pair_id, status);
+`
+
+// SaveLedgerPosition adds a position the first time its execution or paper
+// trade is seen (ID zero; the ID is set only when a row was inserted) or
+// updates it after settlement.
+func (s *Store) SaveLedgerPosition(ctx context.Context, pos *ledger.Position) error {
+	if s == nil || s.db == nil || pos == nil {
+		return fmt.Errorf("sqlite store not initialized or position nil")
+	}
+	legsJSON, err := json.Marshal(pos.Legs)
+	if err != nil {
+		return fmt.Errorf("marshal legs: %w", err)
+	}
+	if pos.ID == 0 {
+		res, err := s.db.ExecContext(ctx, `
+INSERT OR IGNORE INTO ledger_positions (
+	source, ref_id, pair_id, strategy, direction, status, legs_json, payout_per_unit_micros,
+	cost_micros, proceeds_micros, payout_micros, realized_pnl_micros, set_payout_micros, divergent,
+	opened_at, settles_at, settled_at
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`,
+			pos.Source, pos.RefID, pos.PairID, pos.Strategy, pos.Direction, pos.Status, string(legsJSON), micros(pos.PayoutPerUnit),
+			int64(pos.CostUSD), int64(pos.ProceedsUSD), int64(pos.PayoutUSD), int64(pos.RealizedUSD()), micros(pos.SetPayout), pos.Divergent,
+			formatTime(pos.OpenedAt), formatTime(pos.SettlesAt), formatTime(pos.SettledAt),
+		)
+		if err != nil {
+			return fmt.Errorf("insert ledger position: %w", err)
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return err
+		}
+		pos.ID, err = res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("ledger position id: %w", err)
+		}
+		return nil
+	}
+	_, err = s.db.ExecContext(ctx, `
+UPDATE ledger_positions SET
+	status = ?, legs_json = ?, payout_micros = ?, realized_pnl_micros = ?, set_payout_micros = ?,
+	divergent = ?, settled_at = ?
+WHERE id = ?
+`,
+		pos.Status, string(legsJSON), int64(pos.PayoutUSD), int64(pos.RealizedUSD()), micros(pos.SetPayout),
+		pos.Divergent, formatTime(pos.SettledAt), pos.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update ledger position %d: %w", pos.ID, err)
+	}
+	return nil
+}
+
+// LedgerPositions lists ledger positions with the given status, or all of
+// them when status is empty, oldest first.
+func (s *Store) LedgerPositions(ctx context.Context, status ledger.Status) ([]ledger.Position, error) {
+	if status == "" {
+		return s.queryLedgerPositions(ctx, "")
+	}
+	return s.queryLedgerPositions(ctx, `WHERE status = ?`, status)
+}
+
+// SettledLedgerPositions lists the positions from source settled since
+// since, oldest first.
+func (s *Store) SettledLedgerPositions(ctx context.Context, source ledger.Source, since time.Time) ([]ledger.Position, error) {
+	return s.queryLedgerPositions(ctx, `
+WHERE source = ? AND status = ? AND julianday(settled_at) >= julianday(?)`, source, ledger.StatusSettled, formatTime(since))
+}
+
+func (s *Store) queryLedgerPositions(ctx context.Context, where string, args ...any) ([]ledger.Position, error) {
+	query := `
+SELECT id, source, ref_id, pair_id, strategy, direction, status, legs_json, payout_per_unit_micros,
+	cost_micros, proceeds_micros, payout_micros, set_payout_micros, divergent, opened_at, settles_at, settled_at
+FROM ledger_positions ` + where
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ledger.Position
+	for rows.Next() {
+		var (
+			pos                                   ledger.Position
+			source, direction, st, legsJSON       string
+			payoutPerUnit, cost, proceeds, payout int64
+			setPayout                             sql.NullInt64
+			openedAt                              string
+			settlesAt, settledAt                  sql.NullString
+		)
+		if err := rows.Scan(&pos.ID, &source, &pos.RefID, &pos.PairID, &pos.Strategy, &direction, &st, &legsJSON, &payoutPerUnit,
+			&cost, &proceeds, &payout, &setPayout, &pos.Divergent, &openedAt, &settlesAt, &settledAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(legsJSON), &pos.Legs); err != nil {
+			return nil, fmt.Errorf("ledger position %d legs: %w", pos.ID, err)
+		}
+		pos.Source = ledger.Source(source)
+		pos.Direction = matches.Direction(direction)
+		pos.Status = ledger.Status(st)
+		pos.CostUSD = money.Micros(cost)
+		pos.ProceedsUSD = money.Micros(proceeds)
+		pos.PayoutUSD = money.Micros(payout)
+		pos.PayoutPerUnit = money.Micros(payoutPerUnit).Float()
+		pos.SetPayout = money.Micros(setPayout.Int64).Float()
+		pos.OpenedAt = parseTime(openedAt)
+		pos.SettlesAt = parseTime(settlesAt.String)
+		pos.SettledAt = parseTime(settledAt.String)
+		out = append(out, pos)
+	}
+	return out, rows.Err()
+}
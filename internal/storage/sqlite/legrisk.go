@@ -0,0 +1,64 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/arb"
+	"github.com/hetulpatel/Arbitrage/internal/collectors"
+)
+
+const bookChangeSchemaSQL = `
+CREATE TABLE IF NOT EXISTS book_change_rates (
+	venue TEXT PRIMARY KEY,
+	changes REAL NOT NULL,
+	seconds REAL NOT NULL,
+	updated_at TEXT NOT NULL
+);
+`
+
+// SaveBookChangeHistory replaces the persisted best-ask change totals of
+// every venue in history.
+func (s *Store) SaveBookChangeHistory(ctx context.Context, history map[collectors.Venue]arb.BookChangeHistory) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("sqlite store not initialized")
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	now := formatTime(time.Now())
+	for venue, h := range history {
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO book_change_rates (venue, changes, seconds, updated_at) VALUES (?, ?, ?, ?)
+ON CONFLICT(venue) DO UPDATE SET changes = excluded.changes, seconds = excluded.seconds, updated_at = excluded.updated_at
+`, venue, h.Changes, h.Seconds, now); err != nil {
+			return fmt.Errorf("save book changes %s: %w", venue, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// BookChangeHistory loads the persisted best-ask change totals per venue.
+func (s *Store) BookChangeHistory(ctx context.Context) (map[collectors.Venue]arb.BookChangeHistory, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT venue, changes, seconds FROM book_change_rates`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[collectors.Venue]arb.BookChangeHistory)
+	for rows.Next() {
+		var (
+			venue string
+			h     arb.BookChangeHistory
+		)
+		if err := rows.Scan(&venue, &h.Changes, &h.Seconds); err != nil {
+			return nil, err
+		}
+		out[collectors.Venue(venue)] = h
+	}
+	return out, rows.Err()
+}
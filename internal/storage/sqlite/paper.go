@@ -0,0 +1,164 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+	"github.com/hetulpatel/Arbitrage/internal/paper"
+)
+
+const paperSchemaSQL = `
+CREATE TABLE IF NOT EXISTS paper_positions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	pair_id TEXT NOT NULL,
+	strategy TEXT NOT NULL,
+	direction TEXT NOT NULL,
+	status TEXT NOT NULL,
+	legs_json TEXT NOT NULL,
+	payout_per_unit_micros INTEGER NOT NULL,
+	planned_profit_micros INTEGER NOT NULL,
+	entry_cost_micros INTEGER NOT NULL,
+	fees_micros INTEGER NOT NULL,
+	slippage_micros INTEGER NOT NULL,
+	mark_value_micros INTEGER NOT NULL,
+	settlement_micros INTEGER,
+	realized_pnl_micros INTEGER,
+	unrealized_pnl_micros INTEGER,
+	opened_at TEXT NOT NULL,
+	marked_at TEXT,
+	settles_at TEXT,
+	settled_at TEXT
+);
+CREATE INDEX IF NOT EXISTS paper_positions_pair_idx ON paper_positions(pair_id, status);
+`
+
+// SavePaperPosition inserts a new paper position (ID zero, which is then
+// set) or updates an existing one after a mark or settlement.
+func (s *Store) SavePaperPosition(ctx context.Context, pos *paper.Position) error {
+	if s == nil || s.db == nil || pos == nil {
+		return fmt.Errorf("sqlite store not initialized or position nil")
+	}
+	legsJSON, err := json.Marshal(pos.Legs)
+	if err != nil {
+		return fmt.Errorf("marshal legs: %w", err)
+	}
+	if pos.ID == 0 {
+		res, err := s.db.ExecContext(ctx, `
+INSERT INTO paper_positions (
+	pair_id, strategy, direction, status, legs_json, payout_per_unit_micros,
+	planned_profit_micros, entry_cost_micros, fees_micros, slippage_micros,
+	mark_value_micros, settlement_micros, realized_pnl_micros, unrealized_pnl_micros,
+	opened_at, marked_at, settles_at, settled_at
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`,
+			pos.PairID, pos.Strategy, pos.Direction, pos.Status, string(legsJSON), micros(pos.PayoutPerUnit),
+			int64(pos.PlannedProfitUSD), int64(pos.EntryCostUSD), int64(pos.FeesUSD), int64(pos.SlippageUSD),
+			int64(pos.MarkValueUSD), int64(pos.SettlementUSD), int64(pos.RealizedUSD()), int64(pos.UnrealizedUSD()),
+			formatTime(pos.OpenedAt), formatTime(pos.MarkedAt), formatTime(pos.SettlesAt), formatTime(pos.SettledAt),
+		)
+		if err != nil {
+			return fmt.Errorf("insert paper position: %w", err)
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("paper position id: %w", err)
+		}
+		pos.ID = id
+		return nil
+	}
+	_, err = s.db.ExecContext(ctx, `
+UPDATE paper_positions SET
+	status = ?, legs_json = ?, mark_value_micros = ?, settlement_micros = ?,
+	realized_pnl_micros = ?, unrealized_pnl_micros = ?, marked_at = ?, settles_at = ?, settled_at = ?
+WHERE id = ?
+`,
+		pos.Status, string(legsJSON), int64(pos.MarkValueUSD), int64(pos.SettlementUSD),
+		int64(pos.RealizedUSD()), int64(pos.UnrealizedUSD()), formatTime(pos.MarkedAt), formatTime(pos.SettlesAt), formatTime(pos.SettledAt),
+		pos.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update paper position %d: %w", pos.ID, err)
+	}
+	return nil
+}
+
+// HasOpenPaperPosition reports whether the pair already has an open paper
+// position.
+func (s *Store) HasOpenPaperPosition(ctx context.Context, pairID string) (bool, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM paper_positions WHERE pair_id = ? AND status = ?`, pairID, paper.StatusOpen).Scan(&n)
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// PaperPositions lists paper positions with the given status, or all of
+// them when status is empty, oldest first.
+func (s *Store) PaperPositions(ctx context.Context, status paper.Status) ([]paper.Position, error) {
+	query := `
+SELECT id, pair_id, strategy, direction, status, legs_json, payout_per_unit_micros,
+	planned_profit_micros, entry_cost_micros, fees_micros, slippage_micros,
+	mark_value_micros, settlement_micros, opened_at, marked_at, settles_at, settled_at
+FROM paper_positions`
+	var args []any
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, status)
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []paper.Position
+	for rows.Next() {
+		var (
+			pos                                         paper.Position
+			direction, posStatus, legsJSON              string
+			payout, planned, cost, fees, slippage, mark int64
+			settlement                                  sql.NullInt64
+			openedAt                                    string
+			markedAt, settlesAt, settledAt              sql.NullString
+		)
+		if err := rows.Scan(&pos.ID, &pos.PairID, &pos.Strategy, &direction, &posStatus, &legsJSON, &payout,
+			&planned, &cost, &fees, &slippage, &mark, &settlement, &openedAt, &markedAt, &settlesAt, &settledAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(legsJSON), &pos.Legs); err != nil {
+			return nil, fmt.Errorf("paper position %d legs: %w", pos.ID, err)
+		}
+		pos.PayoutPerUnit = money.Micros(payout).Float()
+		pos.Direction = matches.Direction(direction)
+		pos.Status = paper.Status(posStatus)
+		pos.PlannedProfitUSD = money.Micros(planned)
+		pos.EntryCostUSD = money.Micros(cost)
+		pos.FeesUSD = money.Micros(fees)
+		pos.SlippageUSD = money.Micros(slippage)
+		pos.MarkValueUSD = money.Micros(mark)
+		pos.SettlementUSD = money.Micros(settlement.Int64)
+		pos.OpenedAt = parseTime(openedAt)
+		pos.MarkedAt = parseTime(markedAt.String)
+		pos.SettlesAt = parseTime(settlesAt.String)
+		pos.SettledAt = parseTime(settledAt.String)
+		out = append(out, pos)
+	}
+	return out, rows.Err()
+}
+
+func parseTime(raw string) time.Time {
+	if raw == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hetulpatel/Arbitrage/internal/matches"
+	"github.com/hetulpatel/Arbitrage/internal/money"
+	"github.com/hetulpatel/Arbitrage/internal/risk"
+)
+
+const riskSchemaSQL = `
+CREATE TABLE IF NOT EXISTS risk_blocks (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	source TEXT NOT NULL,
+	pair_id TEXT NOT NULL,
+	direction TEXT NOT NULL,
+	reason TEXT NOT NULL,
+	detail TEXT,
+	notional_micros INTEGER NOT NULL,
+	legs_json TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS risk_blocks_reason_idx ON risk_blocks(reason, created_at);
+`
+
+// SaveRiskBlock records a trade the risk guard refused and sets its ID.
+func (s *Store) SaveRiskBlock(ctx context.Context, b *risk.Block) error {
+	if s == nil || s.db == nil || b == nil {
+		return fmt.Errorf("sqlite store not initialized or block nil")
+	}
+	legsJSON, err := json.Marshal(b.Legs)
+	if err != nil {
+		return fmt.Errorf("marshal legs: %w", err)
+	}
+	res, err := s.db.ExecContext(ctx, `
+INSERT INTO risk_blocks (source, pair_id, direction, reason, detail, notional_micros, legs_json, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`, b.Source, b.PairID, b.Direction, b.Reason, b.Detail, int64(b.NotionalUSD), string(legsJSON), formatTime(b.At))
+	if err != nil {
+		return fmt.Errorf("insert risk block: %w", err)
+	}
+	if b.ID, err = res.LastInsertId(); err != nil {
+		return fmt.Errorf("risk block id: %w", err)
+	}
+	return nil
+}
+
+// RiskBlocks lists blocked trades with the given reason, or all of them when
+// reason is empty, oldest first.
+func (s *Store) RiskBlocks(ctx context.Context, reason risk.Reason) ([]risk.Block, error) {
+	query := `
+SELECT id, source, pair_id, direction, reason, detail, notional_micros, legs_json, created_at
+FROM risk_blocks`
+	var args []any
+	if reason != "" {
+		query += ` WHERE reason = ?`
+		args = append(args, reason)
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []risk.Block
+	for rows.Next() {
+		var (
+			b                                   risk.Block
+			direction, why, legsJSON, createdAt string
+			notional                            int64
+		)
+		if err := rows.Scan(&b.ID, &b.Source, &b.PairID, &direction, &why, &b.Detail, &notional, &legsJSON, &createdAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(legsJSON), &b.Legs); err != nil {
+			return nil, fmt.Errorf("risk block %d legs: %w", b.ID, err)
+		}
+		b.Direction = matches.Direction(direction)
+		b.Reason = risk.Reason(why)
+		b.NotionalUSD = money.Micros(notional)
+		b.At = parseTime(createdAt)
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
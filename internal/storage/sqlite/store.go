@@ -14,6 +14,7 @@ import (
 
 	"github.com/hetulpatel/Arbitrage/internal/collectors"
 	"github.com/hetulpatel/Arbitrage/internal/hashutil"
+	"github.com/hetulpatel/Arbitrage/internal/money"
 )
 
 const (
@@ -75,21 +76,47 @@ func (s *Store) Close() error {
 	return s.db.Close()
 }
 
-// CreateTables ensures the unified markets table exists.
+// CreateTables ensures the unified markets, arbitrage, paper-trading,
+// execution, risk, ledger and book-change tables exist.
 func (s *Store) CreateTables(ctx context.Context) error {
-	_, err := s.db.ExecContext(ctx, unifiedSchemaSQL+arbSchemaSQL)
-	return err
+	if _, err := s.db.ExecContext(ctx, unifiedSchemaSQL+arbSchemaSQL+paperSchemaSQL+executionSchemaSQL+riskSchemaSQL+ledgerSchemaSQL+bookChangeSchemaSQL); err != nil {
+		return err
+	}
+	for _, t := range []struct {
+		table   string
+		columns []string
+	}{
+		{"arb_opportunities", arbAddedColumns},
+	} {
+		if err := s.addColumns(ctx, t.table, t.columns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumns brings tables created by older builds up to date. SQLite has no
+// ADD COLUMN IF NOT EXISTS, so duplicate-column errors are ignored.
+func (s *Store) addColumns(ctx context.Context, table string, columns []string) error {
+	for _, col := range columns {
+		_, err := s.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", table, col))
+		if err != nil && !strings.Contains(err.Error(), "duplicate column") {
+			return fmt.Errorf("add column %s.%s: %w", table, col, err)
+		}
+	}
+	return nil
 }
 
 // DropTables removes the unified table.
 func (s *Store) DropTables(ctx context.Context) error {
-	_, err := s.db.ExecContext(ctx, `DROP TABLE IF EXISTS markets; DROP TABLE IF EXISTS arb_opportunities;`)
+	_, err := s.db.ExecContext(ctx, `DROP TABLE IF EXISTS markets; DROP TABLE IF EXISTS arb_budget_sweeps; DROP TABLE IF EXISTS arb_opportunities; DROP TABLE IF EXISTS paper_positions; DROP TABLE IF EXISTS execution_events; DROP TABLE IF EXISTS executions; DROP TABLE IF EXISTS risk_blocks; DROP TABLE IF EXISTS ledger_positions; DROP TABLE IF EXISTS book_change_rates;`)
 	return err
 }
 
-// ClearTables truncates the unified table.
+// ClearTables truncates the unified table and the book-change rates observed
+// from it.
 func (s *Store) ClearTables(ctx context.Context) error {
-	_, err := s.db.ExecContext(ctx, `DELETE FROM markets;`)
+	_, err := s.db.ExecContext(ctx, `DELETE FROM markets; DELETE FROM book_change_rates;`)
 	return err
 }
 
@@ -99,8 +126,9 @@ func (s *Store) MigrateToUnifiedSchema(ctx context.Context) error {
 		`DROP TABLE IF EXISTS markets;`,
 		`DROP TABLE IF EXISTS polymarket_markets;`,
 		`DROP TABLE IF EXISTS kalshi_markets;`,
+		`DROP TABLE IF EXISTS arb_budget_sweeps;`,
 		`DROP TABLE IF EXISTS arb_opportunities;`,
-		unifiedSchemaSQL + arbSchemaSQL,
+		unifiedSchemaSQL + arbSchemaSQL + paperSchemaSQL + executionSchemaSQL + riskSchemaSQL + ledgerSchemaSQL + bookChangeSchemaSQL,
 	}
 	for _, stmt := range stmts {
 		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
@@ -318,6 +346,11 @@ func (s *Store) execUpsert(ctx context.Context, stmt *sql.Stmt, venue collectors
 	return err
 }
 
+// micros stores a dollar amount held as float64 exactly, in integer micros.
+func micros(usd float64) int64 {
+	return int64(money.FromFloat(usd))
+}
+
 func formatTime(t time.Time) string {
 	if t.IsZero() {
 		return ""
@@ -405,7 +438,67 @@ CREATE TABLE IF NOT EXISTS arb_opportunities (
 	kalshi_fees_usd REAL NOT NULL,
 	polymarket_fees_usd REAL NOT NULL,
 	legs_json TEXT NOT NULL,
-	raw_payload_json TEXT NOT NULL
+	raw_payload_json TEXT NOT NULL,
+	return_on_capital REAL,
+	annualized_yield REAL,
+	settles_at TEXT,
+	reject_code TEXT,
+	reject_venue TEXT,
+	leg_risk_loss_prob REAL,
+	total_cost_micros INTEGER,
+	profit_micros INTEGER,
+	kalshi_fees_micros INTEGER,
+	polymarket_fees_micros INTEGER,
+	score REAL,
+	source_yes_price_micros INTEGER,
+	source_no_price_micros INTEGER,
+	target_yes_price_micros INTEGER,
+	target_no_price_micros INTEGER,
+	budget_micros INTEGER,
+	payout_floor_micros INTEGER,
+	payout_max_micros INTEGER,
+	leg_risk_expected_profit_micros INTEGER,
+	leg_risk_worst_loss_micros INTEGER
 );
 CREATE INDEX IF NOT EXISTS arb_opportunities_pair_idx ON arb_opportunities(pair_id);
+
+CREATE TABLE IF NOT EXISTS arb_budget_sweeps (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	opportunity_id INTEGER NOT NULL REFERENCES arb_opportunities(id),
+	pair_id TEXT NOT NULL,
+	processed_at TEXT NOT NULL,
+	budget_micros INTEGER NOT NULL,
+	direction TEXT NOT NULL,
+	qty_contracts REAL NOT NULL,
+	total_cost_micros INTEGER NOT NULL,
+	profit_micros INTEGER NOT NULL,
+	return_on_capital REAL,
+	annualized_yield REAL
+);
+CREATE INDEX IF NOT EXISTS arb_budget_sweeps_pair_idx ON arb_budget_sweeps(pair_id, budget_micros);
 `
+
+// arbAddedColumns lists arb_opportunities columns added after the original
+// schema; CreateTables adds them to existing databases.
+var arbAddedColumns = []string{
+	"return_on_capital REAL",
+	"annualized_yield REAL",
+	"settles_at TEXT",
+	"reject_code TEXT",
+	"reject_venue TEXT",
+	"leg_risk_loss_prob REAL",
+	"total_cost_micros INTEGER",
+	"profit_micros INTEGER",
+	"kalshi_fees_micros INTEGER",
+	"polymarket_fees_micros INTEGER",
+	"score REAL",
+	"source_yes_price_micros INTEGER",
+	"source_no_price_micros INTEGER",
+	"target_yes_price_micros INTEGER",
+	"target_no_price_micros INTEGER",
+	"budget_micros INTEGER",
+	"payout_floor_micros INTEGER",
+	"payout_max_micros INTEGER",
+	"leg_risk_expected_profit_micros INTEGER",
+	"leg_risk_worst_loss_micros INTEGER",
+}
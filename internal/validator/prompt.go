@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/url"
 	"regexp"
 	"sort"
@@ -221,5 +222,6 @@ func parseResult(raw string) (*Result, error) {
 	if err := json.Unmarshal([]byte(raw), &res); err != nil {
 		return nil, err
 	}
+	res.Confidence = math.Max(0, math.Min(1, res.Confidence))
 	return &res, nil
 }
@@ -55,13 +55,15 @@ func (s *Service) Validate(ctx context.Context, payload *matches.Payload) (*Resu
 
 	userPrompt := strings.Join([]string{
 		"Compare the following Polymarket and Kalshi markets. Polymarket and Kalshi are prediction markets, you are helping with an arbitrage detection system.",
-		"Right now, a possibile risk-free arbitrage is possible if the two markets resolve identically.",
+		"Right now, a possibile risk-free arbitrage is possible if the two markets resolve identically, or exactly opposite to each other.",
 		"They must represent the exact same binary outcome, their resolution criteria must be the same, and have matching cutoff/resolution criteria to be valid.",
+		"One market may be worded as the negation of the other (\"Will X NOT happen\", \"Will X stay below\" vs \"Will X reach\"). That is still valid if Polymarket YES happens exactly when Kalshi NO happens; set OutcomeMapping to \"inverted\". Otherwise set it to \"same\".",
 		"For example, they can have different resolution sources, but as long as the criteria and the resolution sources agree on the exact definition, that is valid.",
 		"If either market allows outcomes not strictly YES/NO for the exact same event, answer false. If a potential resolution where yes or no are not the only possibilities, answer false.",
 		"Pay special attention to timing, settlement sources, definitions, tiebreakers, cancellations, or alternate clauses.",
 		"If unsure, treat it as invalid. Answer concisely with only necessary information, nothing too much more.",
-		"Return EXACTLY this JSON format:\n{\n  \"ValidResolution\": true|false,\n  \"ResolutionReason\": \"short explanation\"\n}\n\nInput JSON:\n" + string(inputJSON),
+		"Set Confidence to how sure you are of your answer, from 0.0 (guessing) to 1.0 (certain).",
+		"Return EXACTLY this JSON format:\n{\n  \"ValidResolution\": true|false,\n  \"OutcomeMapping\": \"same\"|\"inverted\",\n  \"Confidence\": 0.0-1.0,\n  \"ResolutionReason\": \"short explanation\"\n}\n\nInput JSON:\n" + string(inputJSON),
 	}, "\n")
 
 	raw, err := s.llm.Complete(ctx, s.systemPrompt, userPrompt)
@@ -10,6 +10,11 @@ import (
 type Result struct {
 	ValidResolution  bool   `json:"ValidResolution"`
 	ResolutionReason string `json:"ResolutionReason"`
+	// OutcomeMapping is "same" when YES means the same thing on both venues
+	// and "inverted" when Polymarket YES resolves with Kalshi NO.
+	OutcomeMapping string `json:"OutcomeMapping"`
+	// Confidence is how sure the model is of the verdict, in [0, 1].
+	Confidence float64 `json:"Confidence"`
 }
 
 // Config controls the validator behavior.